@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/boltstore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/gitstore"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	bolt "go.etcd.io/bbolt"
 	git "gopkg.in/src-d/go-git.v4"
 )
 
@@ -25,6 +30,7 @@ func main() {
 	branch := flag.String("git_branch", "master", "The branch of the git repo.")
 	aws_profile := flag.String("aws_profile", "default", "The AWS profile to use.")
 	aws_region := flag.String("aws_region", "us-east-2", "The AWS region to use.")
+	bolt_path := flag.String("bolt_path", "", "Push to the bolt database at this path instead of DynamoDB.")
 	flag.Parse()
 
 	// open git repo
@@ -36,31 +42,51 @@ func main() {
 	head, err := repo.Head()
 	log.Printf("Repo Head: %v, Err: %v", head, err)
 
-	gitStore := inventory.NewGitStore(repo, &git.FetchOptions{}, *branch)
+	gitStore := gitstore.NewGitStore(repo, &git.FetchOptions{}, *branch)
 	err = gitStore.Refresh()
 	if err != nil {
 		log.Fatalf("Unable to refresh state of git repo: %v", err)
 	}
-	nodes, err := gitStore.GetNodes()
-	if err != nil {
-		log.Fatalf("Unable to get nodes from git: %v", err)
+
+	var store inventory.Store
+	if *bolt_path != "" {
+		db, err := bolt.Open(*bolt_path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+		if err != nil {
+			log.Fatalf("Unable to open bolt database: %v", err)
+		}
+		defer db.Close()
+
+		boltStore := boltstore.NewBoltStore(db)
+		err = boltStore.InitializeBuckets()
+		if err != nil {
+			log.Fatalf("Unable to initialize bolt database: %v", err)
+		}
+		store = boltStore
+	} else {
+		// load aws credentials and connect to dynamodb
+		sess, err := session.NewSessionWithOptions(session.Options{
+			Profile: *aws_profile,
+			Config:  aws.Config{Region: aws.String(*aws_region)},
+		})
+		if err != nil {
+			log.Fatalf("Unable to load aws credentials: %v", err)
+		}
+
+		store = dynamodbclient.NewDynamoDBStore(dynamodb.New(sess), nil)
 	}
-	log.Printf("Found %d nodes: %v", len(nodes), nodes)
 
-	// load aws credentials and connect to dynamodb
-	sess, err := session.NewSessionWithOptions(session.Options{
-		Profile: *aws_profile,
-		Config:  aws.Config{Region: aws.String(*aws_region)},
-	})
+	changes, err := inventory.Diff(inventory.NewStoreSource(store), gitStore, true)
 	if err != nil {
-		log.Fatalf("Unable to load aws credentials: %v", err)
+		log.Fatalf("Unable to compare git store with inventory: %v", err)
 	}
 
-	db := dynamodb.New(sess)
-	ddbStore := inventory.NewDynamoDBStore(db, nil)
+	for _, change := range changes {
+		log.Print(change)
+	}
 
-	err = ddbStore.UpdateFromInventoryStore(gitStore)
+	err = changes.Apply(store)
 	if err != nil {
-		log.Fatalf("Error updating dynamodb from git store: %v", err)
+		log.Fatalf("Error updating inventory from git store: %v", err)
 	}
+	log.Printf("Applied %d changes", len(changes))
 }
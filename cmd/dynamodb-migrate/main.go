@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "This program creates missing inventory tables in DynamoDB and adds indexes missing from existing tables.\n")
+		fmt.Fprintf(os.Stderr, "Tables managed by the CloudFormation stack in template.yml are migrated by updating the stack instead.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		flag.PrintDefaults()
+	}
+
+	aws_profile := flag.String("aws_profile", "default", "The AWS profile to use.")
+	aws_region := flag.String("aws_region", "us-east-2", "The AWS region to use.")
+	endpoint := flag.String("dynamodb_endpoint", "", "Use this DynamoDB endpoint instead of the default for the region, ie: for DynamoDB local.")
+	flag.Parse()
+
+	awsConfig := &aws.Config{
+		Region:      aws.String(*aws_region),
+		Credentials: credentials.NewSharedCredentials("", *aws_profile),
+	}
+	if *endpoint != "" {
+		awsConfig.Endpoint = aws.String(*endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		log.Fatalf("Unable to create AWS session: %v", err)
+	}
+
+	store := dynamodbclient.NewDynamoDBStore(dynamodb.New(sess), nil)
+
+	err = store.InitializeTables()
+	if err != nil {
+		log.Fatalf("Unable to create tables: %v", err)
+	}
+
+	err = store.MigrateTables()
+	if err != nil {
+		log.Fatalf("Unable to migrate tables: %v", err)
+	}
+	log.Printf("Tables are up to date")
+}
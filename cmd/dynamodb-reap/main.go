@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func printReservations(reservations types.IPReservationList) {
+	for _, r := range reservations {
+		fmt.Printf("%s\t%s\t%s\n", r.IP, r.MAC, r.End.Format(time.RFC3339))
+	}
+}
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "This program permanently removes ip reservations that have expired from DynamoDB, as the scheduled reaper does.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		flag.PrintDefaults()
+	}
+
+	aws_profile := flag.String("aws_profile", "default", "The AWS profile to use.")
+	aws_region := flag.String("aws_region", "us-east-2", "The AWS region to use.")
+	endpoint := flag.String("dynamodb_endpoint", "", "Use this DynamoDB endpoint instead of the default for the region, ie: for DynamoDB local.")
+	dryRun := flag.Bool("dry-run", false, "List the expired reservations without removing them.")
+	flag.Parse()
+
+	awsConfig := &aws.Config{
+		Region:      aws.String(*aws_region),
+		Credentials: credentials.NewSharedCredentials("", *aws_profile),
+	}
+	if *endpoint != "" {
+		awsConfig.Endpoint = aws.String(*endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		log.Fatalf("Unable to create AWS session: %v", err)
+	}
+
+	store := dynamodbclient.NewDynamoDBStore(dynamodb.New(sess), nil)
+
+	if *dryRun {
+		expired, err := store.IPReservation().GetExpiredIPReservations(time.Now())
+		if err != nil {
+			log.Fatalf("Unable to get expired reservations: %v", err)
+		}
+		printReservations(expired)
+		log.Printf("Found %d expired reservations", len(expired))
+		return
+	}
+
+	reaped, err := inventory.ReapExpiredIPReservations(store, time.Now(), "dynamodb-reap")
+	printReservations(reaped)
+	if err != nil {
+		log.Fatalf("Unable to reap expired reservations: %v", err)
+	}
+	log.Printf("Removed %d expired reservations", len(reaped))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/boltstore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	bolt "go.etcd.io/bbolt"
+)
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "This program serves the inventory API over http without API Gateway.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		flag.PrintDefaults()
+	}
+
+	listen := flag.String("listen", ":8080", "The address to listen on.")
+	aws_profile := flag.String("aws_profile", "default", "The AWS profile to use.")
+	aws_region := flag.String("aws_region", "us-east-2", "The AWS region to use.")
+	bolt_path := flag.String("bolt_path", "", "Serve the bolt database at this path instead of DynamoDB.")
+	flag.Parse()
+
+	awsConfig := &aws.Config{
+		Region:      aws.String(*aws_region),
+		Credentials: credentials.NewSharedCredentials("", *aws_profile),
+	}
+	newContext := func(ctx context.Context) context.Context {
+		return lambdautils.NewAwsConfigContext(ctx, awsConfig)
+	}
+
+	if *bolt_path != "" {
+		db, err := bolt.Open(*bolt_path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+		if err != nil {
+			log.Fatalf("Unable to open bolt database: %v", err)
+		}
+		defer db.Close()
+
+		store := boltstore.NewBoltStore(db)
+		err = store.InitializeBuckets()
+		if err != nil {
+			log.Fatalf("Unable to initialize bolt database: %v", err)
+		}
+
+		newContext = func(ctx context.Context) context.Context {
+			return server.NewInventoryStoreContext(lambdautils.NewAwsConfigContext(ctx, awsConfig), store)
+		}
+	}
+
+	log.Printf("Listening on %s", *listen)
+	err := http.ListenAndServe(*listen, handlers.NewRouter(newContext))
+	if err != nil {
+		log.Fatalf("Error serving api: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/client"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/manifest"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// apiSource reads the live inventory through the api
+type apiSource struct {
+	client *client.Client
+}
+
+func (s *apiSource) GetNodes() (map[string]*types.Node, error) {
+	nodes, err := s.client.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.Node, len(nodes))
+	for _, n := range nodes {
+		result[n.ID()] = n
+	}
+	return result, nil
+}
+
+func (s *apiSource) GetNetworks() (map[string]*types.Network, error) {
+	networks, err := s.client.ListNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.Network, len(networks))
+	for _, n := range networks {
+		result[n.ID()] = n
+	}
+	return result, nil
+}
+
+func (s *apiSource) GetSystems() (map[string]*types.System, error) {
+	systems, err := s.client.ListSystems()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.System, len(systems))
+	for _, s := range systems {
+		result[s.ID()] = s
+	}
+	return result, nil
+}
+
+// apply makes the live inventory match the manifests in args
+func (c *cli) apply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Show the changes that would be made without making them.")
+	prune := flags.Bool("prune", false, "Delete objects that aren't in the manifests.")
+	err := flags.Parse(args)
+	if err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+
+	m, err := manifest.Load(flags.Args()...)
+	if err != nil {
+		return err
+	}
+
+	changes, err := inventory.Diff(&apiSource{client: c.client}, m, *prune)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(c.out, "No changes")
+		return nil
+	}
+
+	resources := c.resources()
+	for _, change := range changes {
+		fmt.Fprintln(c.out, change)
+		if *dryRun {
+			diff, err := change.Diff()
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(c.out, indent(diff, "    "))
+			continue
+		}
+
+		r := resources[change.Kind]
+		switch change.Action {
+		case inventory.ActionCreate:
+			_, err = r.create(change.Object)
+		case inventory.ActionUpdate:
+			_, err = r.update(change.Object)
+		case inventory.ActionDelete:
+			err = r.delete(change.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %s: %v", change, err)
+		}
+	}
+	return nil
+}
+
+func indent(s string, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+var errUsage = errors.New("invalid usage")
+
+// resource describes how to manipulate one kind of inventory object through
+// the api
+type resource struct {
+	newObj func() interface{}
+	id     func(interface{}) string
+	list   func() (interface{}, error)
+	get    func(string) (interface{}, error)
+	create func(interface{}) (interface{}, error)
+	update func(interface{}) (interface{}, error)
+	delete func(string) error
+	table  tableFunc
+}
+
+func (c *cli) resources() map[string]*resource {
+	return map[string]*resource{
+		"node": &resource{
+			newObj: func() interface{} { return types.NewNode() },
+			id:     func(obj interface{}) string { return obj.(*types.Node).ID() },
+			list:   func() (interface{}, error) { return c.client.ListNodes() },
+			get:    func(id string) (interface{}, error) { return c.client.GetNode(id) },
+			create: func(obj interface{}) (interface{}, error) { return c.client.CreateNode(obj.(*types.Node)) },
+			update: func(obj interface{}) (interface{}, error) { return c.client.UpdateNode(obj.(*types.Node)) },
+			delete: c.client.DeleteNode,
+			table:  nodeTable,
+		},
+		"network": &resource{
+			newObj: func() interface{} { return types.NewNetwork() },
+			id:     func(obj interface{}) string { return obj.(*types.Network).ID() },
+			list:   func() (interface{}, error) { return c.client.ListNetworks() },
+			get:    func(id string) (interface{}, error) { return c.client.GetNetwork(id) },
+			create: func(obj interface{}) (interface{}, error) { return c.client.CreateNetwork(obj.(*types.Network)) },
+			update: func(obj interface{}) (interface{}, error) { return c.client.UpdateNetwork(obj.(*types.Network)) },
+			delete: c.client.DeleteNetwork,
+			table:  networkTable,
+		},
+		"system": &resource{
+			newObj: func() interface{} { return types.NewSystem() },
+			id:     func(obj interface{}) string { return obj.(*types.System).ID() },
+			list:   func() (interface{}, error) { return c.client.ListSystems() },
+			get:    func(id string) (interface{}, error) { return c.client.GetSystem(id) },
+			create: func(obj interface{}) (interface{}, error) { return c.client.CreateSystem(obj.(*types.System)) },
+			update: func(obj interface{}) (interface{}, error) { return c.client.UpdateSystem(obj.(*types.System)) },
+			delete: c.client.DeleteSystem,
+			table:  systemTable,
+		},
+	}
+}
+
+// run dispatches the command in args
+func (c *cli) run(args []string) error {
+	if len(args) > 0 && args[0] == "apply" {
+		return c.apply(args[1:])
+	}
+
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	name, command, args := args[0], args[1], args[2:]
+	if name == "ip" {
+		return c.runIP(command, args)
+	}
+
+	r, ok := c.resources()[name]
+	if !ok {
+		return errUsage
+	}
+
+	if name == "node" && command == "lookup" {
+		return c.nodeLookup(args)
+	}
+	return c.runResource(r, command, args)
+}
+
+func (c *cli) runResource(r *resource, command string, args []string) error {
+	switch command {
+	case "list":
+		objs, err := r.list()
+		if err != nil {
+			return err
+		}
+		return c.print(objs, r.table)
+
+	case "get":
+		if len(args) != 1 {
+			return errUsage
+		}
+		obj, err := r.get(args[0])
+		if err != nil {
+			return err
+		}
+		return c.print(obj, r.table)
+
+	case "create":
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		filename := flags.String("f", "-", "The yaml or json file describing the object, - for stdin.")
+		err := flags.Parse(args)
+		if err != nil {
+			return errUsage
+		}
+
+		obj := r.newObj()
+		err = readObject(*filename, obj)
+		if err != nil {
+			return err
+		}
+
+		created, err := r.create(obj)
+		if err != nil {
+			return err
+		}
+		return c.print(created, r.table)
+
+	case "edit":
+		if len(args) != 1 {
+			return errUsage
+		}
+		obj, err := r.get(args[0])
+		if err != nil {
+			return err
+		}
+
+		edited := r.newObj()
+		err = c.editObject(obj, edited)
+		if err != nil {
+			return err
+		}
+
+		if r.id(edited) != args[0] {
+			return fmt.Errorf("the id of an object can't be changed while editing")
+		}
+
+		updated, err := r.update(edited)
+		if err != nil {
+			return err
+		}
+		return c.print(updated, r.table)
+
+	case "delete":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return r.delete(args[0])
+	}
+	return errUsage
+}
+
+func (c *cli) nodeLookup(args []string) error {
+	flags := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	macString := flags.String("mac", "", "The mac address to look up.")
+	err := flags.Parse(args)
+	if err != nil || *macString == "" {
+		return errUsage
+	}
+
+	mac, err := net.ParseMAC(*macString)
+	if err != nil {
+		return fmt.Errorf("invalid mac address: %v", err)
+	}
+
+	node, err := c.client.GetNodeByMAC(mac)
+	if err != nil {
+		return err
+	}
+	return c.print(node, nodeTable)
+}
+
+func (c *cli) runIP(command string, args []string) error {
+	switch command {
+	case "show":
+		flags := flag.NewFlagSet("show", flag.ContinueOnError)
+		macString := flags.String("mac", "", "Show all reservations for this mac address.")
+		err := flags.Parse(args)
+		if err != nil {
+			return errUsage
+		}
+
+		if *macString != "" {
+			mac, err := net.ParseMAC(*macString)
+			if err != nil {
+				return fmt.Errorf("invalid mac address: %v", err)
+			}
+
+			reservations, err := c.client.GetIPReservationsByMAC(mac)
+			if err != nil {
+				return err
+			}
+			return c.print(reservations, ipReservationTable)
+		}
+
+		if flags.NArg() != 1 {
+			return errUsage
+		}
+		ip, err := parseIP(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		reservation, err := c.client.GetIPReservation(ip)
+		if err != nil {
+			return err
+		}
+		return c.print(reservation, ipReservationTable)
+
+	case "reserve":
+		flags := flag.NewFlagSet("reserve", flag.ContinueOnError)
+		request := &types.IpamIpRequest{}
+		flags.StringVar(&request.HwAddress, "mac", "", "The mac address to reserve an address for.")
+		flags.StringVar(&request.Subnet, "subnet", "", "Allocate an address from this subnet.")
+		flags.StringVar(&request.TTL, "ttl", "", "Make the reservation dynamic, expiring after this duration.")
+		ipString := flags.String("ip", "", "Reserve this specific address.")
+		err := flags.Parse(args)
+		if err != nil || (request.Subnet == "") == (*ipString == "") {
+			return errUsage
+		}
+
+		var ip net.IP
+		if *ipString != "" {
+			ip, err = parseIP(*ipString)
+			if err != nil {
+				return err
+			}
+		}
+
+		reservation, err := c.client.CreateIPReservation(request, ip)
+		if err != nil {
+			return err
+		}
+		return c.print(reservation, ipReservationTable)
+
+	case "release":
+		if len(args) != 1 {
+			return errUsage
+		}
+		ip, err := parseIP(args[0])
+		if err != nil {
+			return err
+		}
+		return c.client.DeleteIPReservation(ip)
+	}
+	return errUsage
+}
+
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address: %s", s)
+	}
+	return ip, nil
+}
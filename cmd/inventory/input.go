@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// readObject unmarshals the file into obj.  Files ending in .json are parsed as
+// json, anything else, including stdin, as yaml.
+func readObject(filename string, obj interface{}) error {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %v", filename, err)
+	}
+
+	if filepath.Ext(filename) == ".json" {
+		err = json.Unmarshal(data, obj)
+	} else {
+		err = yaml.Unmarshal(data, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %v", filename, err)
+	}
+	return nil
+}
+
+// editObject writes obj to a temporary yaml file, opens it in the editor and
+// unmarshals the result into edited
+func (c *cli) editObject(obj interface{}, edited interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile("", "inventory-edit-*.yml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	// run the editor through the shell so that EDITOR may include arguments
+	cmd := exec.Command("sh", "-c", c.editor+` "$1"`, "--", f.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("editor failed: %v", err)
+	}
+
+	return readObject(f.Name(), edited)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/client"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+const usage = `Usage: inventory [flags] <resource> <command> [command flags] [args]
+       inventory [flags] apply [-dry-run] [-prune] <file or directory>...
+
+Resources and commands:
+  node     get <id> | list | create -f <file> | edit <id> | delete <id> | lookup -mac <mac>
+  network  get <id> | list | create -f <file> | edit <id> | delete <id>
+  system   get <id> | list | create -f <file> | edit <id> | delete <id>
+  ip       show <ip> | show -mac <mac> | reserve -mac <mac> (-subnet <cidr> | -ip <ip>) [-ttl <duration>] | release <ip>
+
+Flags:
+`
+
+// cli holds the state shared by all commands
+type cli struct {
+	client *client.Client
+	out    io.Writer
+	format string
+	editor string
+}
+
+func main() {
+	flags := flag.NewFlagSet("inventory", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	apiURL := flags.String("api_url", os.Getenv("INVENTORY_API_URL"), "The base url of the inventory API. Defaults to $INVENTORY_API_URL.")
+	aws_profile := flags.String("aws_profile", "default", "The AWS profile to use when signing requests.")
+	aws_region := flags.String("aws_region", "us-east-2", "The AWS region to use when signing requests.")
+	unsigned := flags.Bool("unsigned", false, "Send requests without SigV4 signatures, ie: to inventory-server.")
+	format := flags.String("o", "table", "Output format: table, json or yaml.")
+	flags.Parse(os.Args[1:])
+
+	if *apiURL == "" {
+		log.Fatalf("The API url must be provided with -api_url or $INVENTORY_API_URL")
+	}
+
+	configs := []*aws.Config{}
+	if !*unsigned {
+		configs = append(configs, &aws.Config{
+			Region:      aws.String(*aws_region),
+			Credentials: credentials.NewSharedCredentials("", *aws_profile),
+		})
+	}
+
+	c, err := client.NewClient(*apiURL, configs...)
+	if err != nil {
+		log.Fatalf("Unable to create client: %v", err)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	app := &cli{client: c, out: os.Stdout, format: *format, editor: editor}
+	err = app.run(flags.Args())
+	if err == errUsage {
+		flags.Usage()
+		os.Exit(2)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+}
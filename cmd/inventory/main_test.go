@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/client"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func newTestCLI(t *testing.T) (*cli, *bytes.Buffer, func()) {
+	inv := memorystore.NewMemoryStore()
+	srv := httptest.NewServer(handlers.NewRouter(func(ctx context.Context) context.Context {
+		return server.NewInventoryStoreContext(ctx, inv)
+	}))
+
+	c, err := client.NewClient(srv.URL)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	return &cli{client: c, out: out, format: "table", editor: "true"}, out, srv.Close
+}
+
+func writeTestFile(t *testing.T, dir string, name string, contents string) string {
+	filename := filepath.Join(dir, name)
+	err := ioutil.WriteFile(filename, []byte(contents), 0600)
+	if err != nil {
+		t.Fatalf("unable to write test file: %v", err)
+	}
+	return filename
+}
+
+func TestNodeCommands(t *testing.T) {
+	app, out, cleanup := newTestCLI(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "inventory-cli")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	networkFile := writeTestFile(t, dir, "network.yml", "name: provision\ndomain: provision.local\nmtu: 9000\n")
+	nodeFile := writeTestFile(t, dir, "node.yml", `inventoryid: node0001
+system: foosys
+environment: dev
+role: worker
+chassislocation:
+  rack: gh21
+  bottomu: 3
+networks:
+  provision:
+    mac: 00:25:90:7e:a2:f3
+`)
+
+	commands := [][]string{
+		{"network", "create", "-f", networkFile},
+		{"node", "create", "-f", nodeFile},
+	}
+	for _, args := range commands {
+		err = app.run(args)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out.Reset()
+	err = app.run([]string{"node", "list"})
+	if err != nil {
+		t.Fatalf("unable to list nodes: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "foosys-gh21-03") {
+		t.Errorf("unexpected node table:\n%s", out.String())
+	}
+
+	out.Reset()
+	app.format = "json"
+	err = app.run([]string{"node", "lookup", "-mac", "00:25:90:7e:a2:f3"})
+	if err != nil {
+		t.Fatalf("unable to lookup node: %v", err)
+	}
+	node := &types.Node{}
+	err = json.Unmarshal(out.Bytes(), node)
+	if err != nil || node.ID() != "node0001" {
+		t.Errorf("unexpected lookup result: %s", out.String())
+	}
+
+	out.Reset()
+	app.format = "yaml"
+	app.editor = `sed -i -e 's/^role: .*/role: master/'`
+	err = app.run([]string{"node", "edit", "node0001"})
+	if err != nil {
+		t.Fatalf("unable to edit node: %v", err)
+	}
+	if !strings.Contains(out.String(), "role: master") {
+		t.Errorf("node was not edited:\n%s", out.String())
+	}
+
+	err = app.run([]string{"node", "delete", "node0001"})
+	if err != nil {
+		t.Fatalf("unable to delete node: %v", err)
+	}
+
+	err = app.run([]string{"node", "get", "node0001"})
+	if !client.IsNotFound(err) {
+		t.Errorf("expected not found after delete, got: %v", err)
+	}
+
+	if err = app.run([]string{"node"}); err != errUsage {
+		t.Errorf("expected usage error, got: %v", err)
+	}
+}
+
+func TestIPCommands(t *testing.T) {
+	app, out, cleanup := newTestCLI(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "inventory-cli")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	networkFile := writeTestFile(t, dir, "network.json", `{"Name":"provision","Subnets":[{"Name":"dynamic","Cidr":"10.0.0.0/24","Gateway":"10.0.0.1","DynamicAllocationMethod":"random"}]}`)
+	err = app.run([]string{"network", "create", "-f", networkFile})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	err = app.run([]string{"ip", "reserve", "-mac", "00:01:02:03:04:05", "-ip", "10.0.0.10"})
+	if err != nil {
+		t.Fatalf("unable to reserve ip: %v", err)
+	}
+
+	out.Reset()
+	err = app.run([]string{"ip", "show", "-mac", "00:01:02:03:04:05"})
+	if err != nil {
+		t.Fatalf("unable to show reservations: %v", err)
+	}
+	if !strings.Contains(out.String(), "10.0.0.10/24") {
+		t.Errorf("reservation not shown:\n%s", out.String())
+	}
+
+	err = app.run([]string{"ip", "release", "10.0.0.10"})
+	if err != nil {
+		t.Fatalf("unable to release ip: %v", err)
+	}
+
+	err = app.run([]string{"ip", "show", "10.0.0.10"})
+	if !client.IsNotFound(err) {
+		t.Errorf("expected not found after release, got: %v", err)
+	}
+
+	if err = app.run([]string{"ip", "reserve", "-mac", "00:01:02:03:04:05"}); err != errUsage {
+		t.Errorf("expected usage error without subnet or ip, got: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	app, out, cleanup := newTestCLI(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "inventory-cli")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifestFile := writeTestFile(t, dir, "inventory.yml", `---
+kind: system
+name: foosys
+---
+kind: network
+name: provision
+---
+kind: node
+inventoryid: node0001
+system: foosys
+role: worker
+networks:
+  provision:
+    mac: 00:25:90:7e:a2:f3
+`)
+
+	err = app.run([]string{"apply", "--dry-run", manifestFile})
+	if err != nil {
+		t.Fatalf("unable to dry-run apply: %v", err)
+	}
+	if !strings.Contains(out.String(), "create node node0001\n") || !strings.Contains(out.String(), "    +role: worker\n") {
+		t.Errorf("unexpected dry-run output:\n%s", out.String())
+	}
+
+	nodes, err := app.client.ListNodes()
+	if err != nil || len(nodes) != 0 {
+		t.Fatalf("dry-run should not create nodes: %v %v", nodes, err)
+	}
+
+	out.Reset()
+	err = app.run([]string{"apply", manifestFile})
+	if err != nil {
+		t.Fatalf("unable to apply: %v", err)
+	}
+	expected := "create system foosys\ncreate network provision\ncreate node node0001\n"
+	if out.String() != expected {
+		t.Errorf("unexpected apply output:\n%s", out.String())
+	}
+
+	out.Reset()
+	err = app.run([]string{"apply", manifestFile})
+	if err != nil || out.String() != "No changes\n" {
+		t.Errorf("expected no changes on second apply: %v\n%s", err, out.String())
+	}
+
+	// removing the node from the manifest only deletes it with -prune
+	writeTestFile(t, dir, "inventory.yml", "kind: system\nname: foosys\n---\nkind: network\nname: provision\n")
+	out.Reset()
+	err = app.run([]string{"apply", manifestFile})
+	if err != nil || out.String() != "No changes\n" {
+		t.Errorf("expected no changes without prune: %v\n%s", err, out.String())
+	}
+
+	out.Reset()
+	err = app.run([]string{"apply", "-prune", manifestFile})
+	if err != nil || out.String() != "delete node node0001\n" {
+		t.Errorf("unexpected prune output: %v\n%s", err, out.String())
+	}
+}
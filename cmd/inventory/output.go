@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// tableFunc returns the header and rows used to display obj, which may be a
+// single object or a list of them
+type tableFunc func(obj interface{}) ([]string, [][]string)
+
+// print writes obj to the output in the selected format
+func (c *cli) print(obj interface{}, table tableFunc) error {
+	switch c.format {
+	case "json":
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.out, string(data))
+		return err
+
+	case "yaml":
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = c.out.Write(data)
+		return err
+
+	case "table":
+		header, rows := table(obj)
+		return writeTable(c.out, header, rows)
+	}
+	return fmt.Errorf("unknown output format: %s", c.format)
+}
+
+func writeTable(out io.Writer, header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func nodeTable(obj interface{}) ([]string, [][]string) {
+	nodes, ok := obj.([]*types.Node)
+	if !ok {
+		nodes = []*types.Node{obj.(*types.Node)}
+	}
+
+	rows := make([][]string, 0, len(nodes))
+	for _, n := range nodes {
+		networks := make([]string, 0, len(n.Networks))
+		for name := range n.Networks {
+			networks = append(networks, name)
+		}
+		sort.Strings(networks)
+
+		rows = append(rows, []string{n.ID(), n.Hostname(), n.System, n.Environment, n.Role, n.Location(), strings.Join(networks, ","), formatTime(&n.LastUpdated)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return []string{"ID", "HOSTNAME", "SYSTEM", "ENVIRONMENT", "ROLE", "LOCATION", "NETWORKS", "LAST UPDATED"}, rows
+}
+
+func networkTable(obj interface{}) ([]string, [][]string) {
+	networks, ok := obj.([]*types.Network)
+	if !ok {
+		networks = []*types.Network{obj.(*types.Network)}
+	}
+
+	rows := make([][]string, 0, len(networks))
+	for _, n := range networks {
+		subnets := make([]string, 0, len(n.Subnets))
+		for _, s := range n.Subnets {
+			if s.Cidr != nil {
+				subnets = append(subnets, s.Cidr.String())
+			}
+		}
+
+		rows = append(rows, []string{n.ID(), n.Domain, fmt.Sprintf("%d", n.MTU), strings.Join(subnets, ","), formatTime(&n.LastUpdated)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return []string{"NAME", "DOMAIN", "MTU", "SUBNETS", "LAST UPDATED"}, rows
+}
+
+func systemTable(obj interface{}) ([]string, [][]string) {
+	systems, ok := obj.([]*types.System)
+	if !ok {
+		systems = []*types.System{obj.(*types.System)}
+	}
+
+	rows := make([][]string, 0, len(systems))
+	for _, s := range systems {
+		environments := make([]string, 0, len(s.Environments))
+		for name := range s.Environments {
+			environments = append(environments, name)
+		}
+		sort.Strings(environments)
+
+		rows = append(rows, []string{s.ID(), s.Name, strings.Join(environments, ","), strings.Join(s.Roles, ","), formatTime(&s.LastUpdated)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return []string{"ID", "NAME", "ENVIRONMENTS", "ROLES", "LAST UPDATED"}, rows
+}
+
+func ipReservationTable(obj interface{}) ([]string, [][]string) {
+	reservations, ok := obj.(types.IPReservationList)
+	if !ok {
+		reservations = types.IPReservationList{obj.(*types.IPReservation)}
+	}
+
+	rows := make([][]string, 0, len(reservations))
+	for _, r := range reservations {
+		ip := ""
+		if r.IP != nil {
+			ip = r.IP.String()
+		}
+		hostname, _ := r.Metadata.GetString("hostname")
+		rows = append(rows, []string{ip, r.MAC.String(), hostname, formatTime(r.Start), formatTime(r.End)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return []string{"IP", "MAC", "HOSTNAME", "START", "END"}, rows
+}
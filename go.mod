@@ -14,6 +14,7 @@ require (
 	github.com/go-test/deep v1.0.1
 	github.com/opencontainers/go-digest v1.0.0-rc1 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
-	golang.org/x/net v0.0.0-20190522155817-f3200d17e092 // indirect
+	go.etcd.io/bbolt v1.3.5
+	gopkg.in/src-d/go-git.v4 v4.13.1
 	gopkg.in/yaml.v2 v2.2.2
 )
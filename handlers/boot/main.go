@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/boot"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(boot.Handler)
+}
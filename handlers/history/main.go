@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/history"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(history.Handler)
+}
@@ -0,0 +1,184 @@
+// Package client provides a go client for the inventory api.  Requests are
+// signed with SigV4 when aws configuration is provided, as required by the api
+// gateway deployment described in template.yml.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// signingServiceName is the service name api gateway expects in signatures
+const signingServiceName = "execute-api"
+
+// nextCursorHeader holds the cursor for the next page of a list, it matches
+// server.NextCursorHeader
+const nextCursorHeader = "X-Next-Cursor"
+
+// Client makes requests against the inventory api
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	signer     *v4.Signer
+	region     string
+}
+
+// NewClient creates a client for the api at baseURL, ie:
+// https://abcdef1234.execute-api.us-east-2.amazonaws.com/Prod.  If any aws
+// configs are provided, requests are signed using the credentials and region
+// they resolve to.  Without aws configs requests are sent unsigned, which is
+// suitable for inventory-server.
+func NewClient(baseURL string, configs ...*aws.Config) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %v", err)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+
+	c := &Client{baseURL: u, httpClient: http.DefaultClient}
+	if len(configs) == 0 {
+		return c, nil
+	}
+
+	sess, err := session.NewSession(configs...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load aws configuration: %v", err)
+	}
+
+	c.signer = v4.NewSigner(sess.Config.Credentials)
+	c.region = aws.StringValue(sess.Config.Region)
+	if sess.Config.HTTPClient != nil {
+		c.httpClient = sess.Config.HTTPClient
+	}
+	return c, nil
+}
+
+// url builds the url for the path, escaping each element of the path
+func (c *Client) url(query url.Values, elements ...string) string {
+	u := *c.baseURL
+	escaped := make([]string, 0, len(elements))
+	for _, e := range elements {
+		escaped = append(escaped, url.PathEscape(e))
+	}
+	u.RawPath = u.EscapedPath() + "/" + strings.Join(escaped, "/")
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// do sends a request with body marshaled as json, and unmarshals the response
+// into result if it's not nil.  Error responses are returned as *Error.
+func (c *Client) do(method string, u string, body interface{}, result interface{}) error {
+	_, err := c.doWithHeaders(method, u, body, result)
+	return err
+}
+
+// doWithHeaders behaves like do, but also returns the response headers
+func (c *Client) doWithHeaders(method string, u string, body interface{}, result interface{}) (http.Header, error) {
+	var bodyReader io.ReadSeeker
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal request body: %v", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.signer != nil {
+		_, err = c.signer.Sign(req, bodyReader, signingServiceName, c.region, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("unable to sign request: %v", err)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %v", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, newError(resp.StatusCode, data)
+	}
+
+	if result == nil {
+		return resp.Header, nil
+	}
+
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse response: %v", err)
+	}
+	return resp.Header, nil
+}
+
+// getAllPages requests each page of the list at path in turn, calling add
+// after each page is unmarshaled into page
+func (c *Client) getAllPages(path string, page interface{}, add func()) error {
+	opts := inventory.ListOptions{}
+	for {
+		headers, err := c.doWithHeaders(http.MethodGet, c.url(listQuery(opts), path), nil, page)
+		if err != nil {
+			return err
+		}
+		add()
+
+		opts.Next = headers.Get(nextCursorHeader)
+		if opts.Next == "" {
+			return nil
+		}
+	}
+}
+
+// listQuery builds the query parameters for a page of a list
+func listQuery(opts inventory.ListOptions) url.Values {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Next != "" {
+		query.Set("next", opts.Next)
+	}
+
+	filters := map[string]string{
+		"system":      opts.Filter.System,
+		"role":        opts.Filter.Role,
+		"environment": opts.Filter.Environment,
+		"tag":         opts.Filter.Tag,
+		"rack":        opts.Filter.Rack,
+	}
+	for key, value := range filters {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
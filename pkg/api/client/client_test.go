@@ -0,0 +1,263 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *Client) {
+	inv := memorystore.NewMemoryStore()
+	srv := httptest.NewServer(handlers.NewRouter(func(ctx context.Context) context.Context {
+		return server.NewInventoryStoreContext(ctx, inv)
+	}))
+
+	c, err := NewClient(srv.URL)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("unable to create client: %v", err)
+	}
+	return srv, c
+}
+
+func TestSignedRequest(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL+"/Prod/", &aws.Config{
+		Region:      aws.String("us-east-2"),
+		Credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""),
+	})
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	_, err = c.ListNodes()
+	if err != nil {
+		t.Fatalf("unable to list nodes: %v", err)
+	}
+
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(authorization, "/us-east-2/execute-api/aws4_request") {
+		t.Errorf("request was not signed correctly: '%s'", authorization)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/node/foo%2Fbar" {
+			t.Errorf("path not escaped: %s", r.URL.EscapedPath())
+		}
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"status":"Conflict","error":"An object with that id already exists."}`))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(srv.URL)
+	_, err := c.GetNode("foo/bar")
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Fatalf("expected conflict error, got: %v", err)
+	}
+
+	if e := err.(*Error); e.Message != "An object with that id already exists." {
+		t.Errorf("wrong error message: %s", e.Message)
+	}
+}
+
+func TestNodeCRUD(t *testing.T) {
+	srv, c := newTestServer(t)
+	defer srv.Close()
+
+	_, err := c.CreateSystem(&types.System{Name: "testsys"})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	_, err = c.CreateNetwork(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := types.NewNode()
+	node.InventoryID = "node0001"
+	node.System = "testsys"
+	node.Environment = "production"
+	node.Networks = types.NICInfoMap{"testnet": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}}
+
+	created, err := c.CreateNode(node)
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+	if created.ID() != "node0001" || created.LastUpdated.IsZero() {
+		t.Errorf("unexpected created node: %v", created)
+	}
+
+	_, err = c.CreateNode(node)
+	if !IsConflict(err) {
+		t.Errorf("expected conflict creating duplicate node, got: %v", err)
+	}
+
+	found, err := c.GetNodeByMAC(mac)
+	if err != nil {
+		t.Fatalf("unable to lookup node by mac: %v", err)
+	}
+	if found.ID() != "node0001" {
+		t.Errorf("wrong node returned for mac: %v", found)
+	}
+
+	node.Role = "worker"
+	updated, err := c.UpdateNode(node)
+	if err != nil {
+		t.Fatalf("unable to update node: %v", err)
+	}
+	if updated.Role != "worker" {
+		t.Errorf("node not updated: %v", updated)
+	}
+
+	nodes, err := c.ListNodes()
+	if err != nil || len(nodes) != 1 {
+		t.Errorf("unexpected node list: %v, %v", nodes, err)
+	}
+
+	err = c.DeleteNode("node0001")
+	if err != nil {
+		t.Fatalf("unable to delete node: %v", err)
+	}
+
+	_, err = c.GetNode("node0001")
+	if !IsNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestListNodesPage(t *testing.T) {
+	srv, c := newTestServer(t)
+	defer srv.Close()
+
+	_, err := c.CreateSystem(&types.System{Name: "testsys", Roles: []string{"worker", "master"}, Environments: map[string]*types.Environment{"production": &types.Environment{}}})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	for i, role := range []string{"worker", "master", "worker", "worker"} {
+		node := types.NewNode()
+		node.InventoryID = fmt.Sprintf("node%04d", i)
+		node.System = "testsys"
+		node.Environment = "production"
+		node.Role = role
+		_, err = c.CreateNode(node)
+		if err != nil {
+			t.Fatalf("unable to create node: %v", err)
+		}
+	}
+
+	opts := inventory.ListOptions{Limit: 2, Filter: inventory.NodeFilter{Role: "worker"}}
+	nodes, next, err := c.ListNodesPage(opts)
+	if err != nil || len(nodes) != 2 || next == "" {
+		t.Fatalf("unexpected first page: %v, next: '%s', err: %v", nodes, next, err)
+	}
+
+	opts.Next = next
+	nodes, next, err = c.ListNodesPage(opts)
+	if err != nil || len(nodes) != 1 || nodes[0].ID() != "node0003" || next != "" {
+		t.Fatalf("unexpected last page: %v, next: '%s', err: %v", nodes, next, err)
+	}
+
+	iNodes, _, err := c.ListInventoryNodesPage(inventory.ListOptions{Filter: inventory.NodeFilter{Role: "master"}})
+	if err != nil || len(iNodes) != 1 || iNodes[0].InventoryID != "node0001" {
+		t.Errorf("unexpected inventory nodes: %v, err: %v", iNodes, err)
+	}
+}
+
+func TestListAllPages(t *testing.T) {
+	srv, c := newTestServer(t)
+	defer srv.Close()
+
+	count := server.DefaultListLimit + 1
+	for i := 0; i < count; i++ {
+		_, err := c.CreateSystem(&types.System{Name: fmt.Sprintf("sys%04d", i)})
+		if err != nil {
+			t.Fatalf("unable to create system: %v", err)
+		}
+	}
+
+	systems, err := c.ListSystems()
+	if err != nil || len(systems) != count {
+		t.Errorf("expected all %d systems to be listed, got %d: %v", count, len(systems), err)
+	}
+}
+
+func TestIPReservations(t *testing.T) {
+	srv, c := newTestServer(t)
+	defer srv.Close()
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	network := &types.Network{Name: "testnet", Subnets: types.SubnetList{
+		&types.Subnet{Name: "testsubnet", Cidr: cidr, Gateway: net.ParseIP("10.0.0.1"), DynamicAllocationMethod: "random"},
+	}}
+	_, err := c.CreateNetwork(network)
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	reservation, err := c.CreateIPReservation(&types.IpamIpRequest{Subnet: "10.0.0.0/24", HwAddress: "00:01:02:03:04:05"}, nil)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+	if !cidr.Contains(reservation.IP.IP) || !reservation.Gateway.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("unexpected reservation: %v", reservation)
+	}
+
+	usage, err := c.GetSubnetUsage(cidr, 1)
+	if err != nil {
+		t.Fatalf("unable to get subnet usage: %v", err)
+	}
+	if usage.Allocated != 1 || usage.Static != 1 || len(usage.Free) != 1 {
+		t.Errorf("unexpected subnet usage: %+v", usage)
+	}
+
+	networkUsage, err := c.GetNetworkUsage("testnet", 0)
+	if err != nil || networkUsage.Allocated != 1 || len(networkUsage.Subnets) != 1 {
+		t.Errorf("unexpected network usage: %+v, %v", networkUsage, err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	reservations, err := c.GetIPReservationsByMAC(mac)
+	if err != nil || len(reservations) != 1 {
+		t.Fatalf("unexpected reservations for mac: %v, %v", reservations, err)
+	}
+
+	got, err := c.GetIPReservation(reservation.IP.IP)
+	if err != nil {
+		t.Fatalf("unable to get reservation: %v", err)
+	}
+	if got.MAC.String() != mac.String() {
+		t.Errorf("wrong reservation returned: %v", got)
+	}
+
+	err = c.DeleteIPReservation(reservation.IP.IP)
+	if err != nil {
+		t.Fatalf("unable to delete reservation: %v", err)
+	}
+
+	_, err = c.GetIPReservation(reservation.IP.IP)
+	if !IsNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+)
+
+// Error is returned when the api responds with an error status
+type Error struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode, Status: http.StatusText(statusCode)}
+
+	errResponse := lambdautils.ErrorResponse{}
+	err := json.Unmarshal(body, &errResponse)
+	if err != nil || errResponse.ErrorMessage == "" {
+		e.Message = string(body)
+		return e
+	}
+
+	if errResponse.Status != "" {
+		e.Status = errResponse.Status
+	}
+	e.Message = errResponse.ErrorMessage
+	return e
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == statusCode
+}
+
+// IsNotFound returns true if the api returned 404 Not Found
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if the api returned 409 Conflict
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsBadRequest returns true if the api returned 400 Bad Request
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
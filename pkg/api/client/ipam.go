@@ -0,0 +1,87 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// GetIPReservation returns the reservation for the ip address
+func (c *Client) GetIPReservation(ip net.IP) (*types.IPReservation, error) {
+	reservation := &types.IPReservation{}
+	err := c.do(http.MethodGet, c.url(nil, "ipam", "ip", ip.String()), nil, reservation)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// GetIPReservationsByMAC returns all reservations for the mac address
+func (c *Client) GetIPReservationsByMAC(mac net.HardwareAddr) (types.IPReservationList, error) {
+	reservations := types.IPReservationList{}
+	err := c.do(http.MethodGet, c.url(url.Values{"mac": {mac.String()}}, "ipam", "ip"), nil, &reservations)
+	return reservations, err
+}
+
+// CreateIPReservation creates a reservation as described by request.  If ip is
+// nil, an address is allocated from request.Subnet.
+func (c *Client) CreateIPReservation(request *types.IpamIpRequest, ip net.IP) (*types.IPReservation, error) {
+	u := c.url(nil, "ipam", "ip")
+	if ip != nil {
+		u = c.url(nil, "ipam", "ip", ip.String())
+	}
+
+	reservation := &types.IPReservation{}
+	err := c.do(http.MethodPost, u, request, reservation)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// UpdateIPReservation updates an existing reservation.  The mac address must
+// match the existing reservation.
+func (c *Client) UpdateIPReservation(reservation *types.IPReservation) (*types.IPReservation, error) {
+	updated := &types.IPReservation{}
+	err := c.do(http.MethodPut, c.url(nil, "ipam", "ip", reservation.IP.IP.String()), reservation, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteIPReservation releases the reservation for the ip address
+func (c *Client) DeleteIPReservation(ip net.IP) error {
+	return c.do(http.MethodDelete, c.url(nil, "ipam", "ip", ip.String()), nil, nil)
+}
+
+// usageQuery builds the query requesting free addresses
+func usageQuery(free int) url.Values {
+	return url.Values{"free": {strconv.Itoa(free)}}
+}
+
+// GetSubnetUsage returns the usage of the subnet, listing up to free of its
+// lowest free addresses
+func (c *Client) GetSubnetUsage(cidr *net.IPNet, free int) (*types.SubnetUsage, error) {
+	usage := &types.SubnetUsage{}
+	query := usageQuery(free)
+	query.Set("cidr", cidr.String())
+	err := c.do(http.MethodGet, c.url(query, "ipam", "subnet"), nil, usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetNetworkUsage returns the usage of every subnet in the network
+func (c *Client) GetNetworkUsage(id string, free int) (*types.NetworkUsage, error) {
+	usage := &types.NetworkUsage{}
+	err := c.do(http.MethodGet, c.url(usageQuery(free), "network", id, "usage"), nil, usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ListNetworks returns all networks
+func (c *Client) ListNetworks() ([]*types.Network, error) {
+	networks := []*types.Network{}
+	page := []*types.Network{}
+	err := c.getAllPages("network", &page, func() { networks = append(networks, page...) })
+	return networks, err
+}
+
+// GetNetwork returns the network with the id
+func (c *Client) GetNetwork(id string) (*types.Network, error) {
+	network := types.NewNetwork()
+	err := c.do(http.MethodGet, c.url(nil, "network", id), nil, network)
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// CreateNetwork creates a network and returns the network as stored
+func (c *Client) CreateNetwork(network *types.Network) (*types.Network, error) {
+	created := types.NewNetwork()
+	err := c.do(http.MethodPost, c.url(nil, "network"), network, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateNetwork updates a network and returns the network as stored
+func (c *Client) UpdateNetwork(network *types.Network) (*types.Network, error) {
+	updated := types.NewNetwork()
+	err := c.do(http.MethodPut, c.url(nil, "network", network.ID()), network, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteNetwork deletes the network with the id
+func (c *Client) DeleteNetwork(id string) error {
+	return c.do(http.MethodDelete, c.url(nil, "network", id), nil, nil)
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ListNodes returns all nodes
+func (c *Client) ListNodes() ([]*types.Node, error) {
+	nodes := []*types.Node{}
+	page := []*types.Node{}
+	err := c.getAllPages("node", &page, func() { nodes = append(nodes, page...) })
+	return nodes, err
+}
+
+// ListNodesPage returns a page of the nodes matching the filter in opts, and
+// the cursor for the next page
+func (c *Client) ListNodesPage(opts inventory.ListOptions) ([]*types.Node, string, error) {
+	nodes := []*types.Node{}
+	headers, err := c.doWithHeaders(http.MethodGet, c.url(listQuery(opts), "node"), nil, &nodes)
+	if err != nil {
+		return nil, "", err
+	}
+	return nodes, headers.Get(nextCursorHeader), nil
+}
+
+// GetNode returns the node with the id
+func (c *Client) GetNode(id string) (*types.Node, error) {
+	node := types.NewNode()
+	err := c.do(http.MethodGet, c.url(nil, "node", id), nil, node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// GetNodeByMAC returns the node with a nic with the mac address
+func (c *Client) GetNodeByMAC(mac net.HardwareAddr) (*types.Node, error) {
+	nodes := []*types.Node{}
+	err := c.do(http.MethodGet, c.url(url.Values{"mac": {mac.String()}}, "node"), nil, &nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("expected one node for mac %s, got %d", mac, len(nodes))
+	}
+	return nodes[0], nil
+}
+
+// CreateNode creates a node and returns the node as stored
+func (c *Client) CreateNode(node *types.Node) (*types.Node, error) {
+	created := types.NewNode()
+	err := c.do(http.MethodPost, c.url(nil, "node"), node, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateNode updates a node and returns the node as stored
+func (c *Client) UpdateNode(node *types.Node) (*types.Node, error) {
+	updated := types.NewNode()
+	err := c.do(http.MethodPut, c.url(nil, "node", node.ID()), node, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteNode deletes the node with the id
+func (c *Client) DeleteNode(id string) error {
+	return c.do(http.MethodDelete, c.url(nil, "node", id), nil, nil)
+}
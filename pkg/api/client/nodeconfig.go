@@ -0,0 +1,55 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ListInventoryNodes returns the compiled configuration for all nodes
+func (c *Client) ListInventoryNodes() ([]*types.InventoryNode, error) {
+	nodes := []*types.InventoryNode{}
+	page := []*types.InventoryNode{}
+	err := c.getAllPages("nodeconfig", &page, func() { nodes = append(nodes, page...) })
+	return nodes, err
+}
+
+// ListInventoryNodesPage returns the compiled configuration for a page of the
+// nodes matching the filter in opts, and the cursor for the next page
+func (c *Client) ListInventoryNodesPage(opts inventory.ListOptions) ([]*types.InventoryNode, string, error) {
+	nodes := []*types.InventoryNode{}
+	headers, err := c.doWithHeaders(http.MethodGet, c.url(listQuery(opts), "nodeconfig"), nil, &nodes)
+	if err != nil {
+		return nil, "", err
+	}
+	return nodes, headers.Get(nextCursorHeader), nil
+}
+
+// GetInventoryNode returns the compiled configuration for the node with the id
+func (c *Client) GetInventoryNode(id string) (*types.InventoryNode, error) {
+	node := &types.InventoryNode{}
+	err := c.do(http.MethodGet, c.url(nil, "nodeconfig", id), nil, node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// GetInventoryNodeByMAC returns the compiled configuration for the node with a
+// nic with the mac address
+func (c *Client) GetInventoryNodeByMAC(mac net.HardwareAddr) (*types.InventoryNode, error) {
+	nodes := []*types.InventoryNode{}
+	err := c.do(http.MethodGet, c.url(url.Values{"mac": {mac.String()}}, "nodeconfig"), nil, &nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("expected one node for mac %s, got %d", mac, len(nodes))
+	}
+	return nodes[0], nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ListSystems returns all systems
+func (c *Client) ListSystems() ([]*types.System, error) {
+	systems := []*types.System{}
+	page := []*types.System{}
+	err := c.getAllPages("system", &page, func() { systems = append(systems, page...) })
+	return systems, err
+}
+
+// GetSystem returns the system with the id
+func (c *Client) GetSystem(id string) (*types.System, error) {
+	system := types.NewSystem()
+	err := c.do(http.MethodGet, c.url(nil, "system", id), nil, system)
+	if err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+// CreateSystem creates a system and returns the system as stored
+func (c *Client) CreateSystem(system *types.System) (*types.System, error) {
+	created := types.NewSystem()
+	err := c.do(http.MethodPost, c.url(nil, "system"), system, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateSystem updates a system and returns the system as stored
+func (c *Client) UpdateSystem(system *types.System) (*types.System, error) {
+	updated := types.NewSystem()
+	err := c.do(http.MethodPut, c.url(nil, "system", system.ID()), system, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteSystem deletes the system with the id
+func (c *Client) DeleteSystem(id string) error {
+	return c.do(http.MethodDelete, c.url(nil, "system", id), nil, nil)
+}
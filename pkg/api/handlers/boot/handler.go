@@ -0,0 +1,142 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/PolarGeospatialCenter/inventory/pkg/render"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DiscoveryURLEnv names the environment variable holding the url unknown
+// nodes are chained to, so that they can be registered
+const DiscoveryURLEnv = "INVENTORY_DISCOVERY_IPXE_URL"
+
+// IPXEMetadataKeysEnv names the environment variable holding the comma
+// separated metadata keys that are set in ipxe scripts.  Other metadata isn't
+// included, since the scripts are served without authentication.
+const IPXEMetadataKeysEnv = "INVENTORY_IPXE_METADATA_KEYS"
+
+// ipxeMetadataKeys returns the metadata keys listed in IPXEMetadataKeysEnv
+func ipxeMetadataKeys() []string {
+	keys := []string{}
+	for _, key := range strings.Split(os.Getenv(IPXEMetadataKeysEnv), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func textResponse(body string) (*events.APIGatewayProxyResponse, error) {
+	return &events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "text/plain"},
+		Body:       body,
+	}, nil
+}
+
+// IPXEHandler serves the ipxe script for the node with the mac in the mac
+// query parameter.  Macs that aren't in the inventory are served a discovery
+// script rather than an error, since ipxe won't run the body of an error, and
+// nodes whose environment has no ipxe url are served a script that exits.
+func IPXEHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	mac, err := net.ParseMAC(request.QueryStringParameters["mac"])
+	if err != nil {
+		return lambdautils.ErrBadRequest("mac must be set to a valid mac address")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+	node, err := inv.InventoryNode().GetInventoryNodeByMAC(mac)
+	if err == inventory.ErrObjectNotFound {
+		script, err := render.DiscoveryIPXEScript(mac, os.Getenv(DiscoveryURLEnv))
+		if err != nil {
+			log.Printf("unable to render discovery script for %s: %v", mac, err)
+			return lambdautils.ErrInternalServerError()
+		}
+		return textResponse(script)
+	}
+
+	if err != nil {
+		log.Printf("unable to lookup node for %s: %v", mac, err)
+		return lambdautils.ErrInternalServerError()
+	}
+
+	script, err := render.IPXEScript(node, mac, ipxeMetadataKeys())
+	if err == render.ErrNoIPXEUrl {
+		return textResponse(render.ExitIPXEScript(fmt.Sprintf("No ipxe url is configured for %s", node.ID())))
+	}
+
+	if err != nil {
+		log.Printf("unable to render ipxe script for %s: %v", node.ID(), err)
+		return lambdautils.ErrInternalServerError()
+	}
+	return textResponse(script)
+}
+
+// cloudInitDocuments maps the NoCloud documents served to the function that
+// renders them
+var cloudInitDocuments = map[string]func(*inventorytypes.InventoryNode) ([]byte, error){
+	"meta-data":      render.MetaData,
+	"user-data":      render.UserData,
+	"network-config": render.NetworkConfig,
+}
+
+// CloudInitHandler serves the cloud-init NoCloud documents for a node, so
+// that nodes can be booted with seedfrom set to /boot/cloud-init/{nodeId}/.
+// Unlike the ipxe script the documents include the node's metadata, so the
+// api requires requests for them to be signed.
+func CloudInitHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	renderDocument, ok := cloudInitDocuments[request.PathParameters["document"]]
+	if !ok {
+		return lambdautils.ErrNotFound()
+	}
+
+	nodeID := request.PathParameters["nodeId"]
+	if nodeID == "" {
+		return lambdautils.ErrBadRequest()
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+	node, err := inv.InventoryNode().GetInventoryNodeByID(nodeID)
+	switch err {
+	case nil:
+	case inventory.ErrObjectNotFound:
+		return lambdautils.ErrNotFound(err.Error())
+	default:
+		log.Printf("unable to lookup node %s: %v", nodeID, err)
+		return lambdautils.ErrInternalServerError()
+	}
+
+	doc, err := renderDocument(node)
+	if err != nil {
+		log.Printf("unable to render %s for %s: %v", request.PathParameters["document"], nodeID, err)
+		return lambdautils.ErrInternalServerError()
+	}
+	return textResponse(string(doc))
+}
+
+// Handler handles requests for boot configuration
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodGet {
+		return lambdautils.ErrNotImplemented()
+	}
+
+	switch request.Resource {
+	case "/boot/ipxe":
+		return IPXEHandler(ctx, request)
+	case "/boot/cloud-init/{nodeId}/{document}":
+		return CloudInitHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotFound()
+	}
+}
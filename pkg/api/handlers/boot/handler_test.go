@@ -0,0 +1,144 @@
+package boot
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func testStore(t *testing.T) *memorystore.MemoryStore {
+	inv := memorystore.NewMemoryStore()
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	devMac, _ := net.ParseMAC("00:01:02:03:04:07")
+	records := []error{
+		inv.Network().Create(&types.Network{Name: "provisioning", Domain: "tst.example.com", Subnets: []*types.Subnet{{Cidr: subnet, Gateway: net.ParseIP("10.0.0.1")}}}),
+		inv.System().Create(&types.System{
+			Name:      "test",
+			ShortName: "tst",
+			Roles:     []string{"worker"},
+			Environments: map[string]*types.Environment{
+				"prod": {IPXEUrl: "http://boot.example.com/{{.Role}}.ipxe", Networks: map[string]string{"provisioning": "provisioning"}, Metadata: types.Metadata{"console": "ttyS0", "root_password": "s3cret"}},
+				"dev":  {Networks: map[string]string{"provisioning": "provisioning"}},
+			},
+		}),
+		inv.Node().Create(&types.Node{
+			InventoryID: "node0001",
+			System:      "tst",
+			Role:        "worker",
+			Environment: "prod",
+			Networks:    types.NICInfoMap{"provisioning": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}},
+		}),
+		inv.IPReservation().CreateIPReservation(&types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, MAC: mac}),
+		inv.Node().Create(&types.Node{
+			InventoryID: "node0003",
+			System:      "tst",
+			Role:        "worker",
+			Environment: "dev",
+			Networks:    types.NICInfoMap{"provisioning": &types.NetworkInterface{NICs: []net.HardwareAddr{devMac}}},
+		}),
+	}
+	for _, err := range records {
+		if err != nil {
+			t.Fatalf("unable to create test records: %v", err)
+		}
+	}
+	return inv
+}
+
+func TestIPXEHandler(t *testing.T) {
+	inv := testStore(t)
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+	get := func(mac string) *events.APIGatewayProxyResponse {
+		response, err := Handler(ctx, events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodGet,
+			Resource:              "/boot/ipxe",
+			QueryStringParameters: map[string]string{"mac": mac},
+		})
+		if err != nil {
+			t.Fatalf("unable to handle request: %v", err)
+		}
+		return response
+	}
+
+	os.Setenv(IPXEMetadataKeysEnv, "console, missing")
+	defer os.Unsetenv(IPXEMetadataKeysEnv)
+
+	response := get("00:01:02:03:04:05")
+	if response.StatusCode != http.StatusOK || response.Headers["Content-Type"] != "text/plain" {
+		t.Fatalf("unexpected response: %d %v %s", response.StatusCode, response.Headers, response.Body)
+	}
+
+	for _, line := range []string{"set role worker", "set net_provisioning_ip 10.0.0.10/24", "set net_provisioning_gateway 10.0.0.1", "set meta_console ttyS0", "chain http://boot.example.com/worker.ipxe"} {
+		if !strings.Contains(response.Body, line+"\n") {
+			t.Errorf("expected script to include '%s':\n%s", line, response.Body)
+		}
+	}
+
+	if strings.Contains(response.Body, "s3cret") {
+		t.Errorf("expected metadata that isn't allowed to be left out:\n%s", response.Body)
+	}
+
+	response = get("00:01:02:03:04:07")
+	if response.StatusCode != http.StatusOK || !strings.HasSuffix(response.Body, "\nexit\n") {
+		t.Errorf("expected nodes without an ipxe url to be served a script that exits: %d %s", response.StatusCode, response.Body)
+	}
+
+	os.Setenv(DiscoveryURLEnv, "http://discovery.example.com/register?mac={{.MAC}}")
+	defer os.Unsetenv(DiscoveryURLEnv)
+
+	response = get("00:01:02:03:04:06")
+	if response.StatusCode != http.StatusOK || !strings.Contains(response.Body, "chain http://discovery.example.com/register?mac=00:01:02:03:04:06\n") {
+		t.Errorf("expected the discovery script for unknown macs: %d %s", response.StatusCode, response.Body)
+	}
+
+	response = get("not a mac")
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected invalid macs to be rejected, got %d", response.StatusCode)
+	}
+}
+
+func TestCloudInitHandler(t *testing.T) {
+	ctx := server.NewInventoryStoreContext(context.Background(), testStore(t))
+	get := func(nodeID string, document string) *events.APIGatewayProxyResponse {
+		response, err := Handler(ctx, events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodGet,
+			Resource:       "/boot/cloud-init/{nodeId}/{document}",
+			PathParameters: map[string]string{"nodeId": nodeID, "document": document},
+		})
+		if err != nil {
+			t.Fatalf("unable to handle request: %v", err)
+		}
+		return response
+	}
+
+	expected := map[string]string{
+		"meta-data":      "local-hostname: tst-node0001\n",
+		"user-data":      "fqdn: tst-node0001.tst.example.com\n",
+		"network-config": "    - 10.0.0.10/24\n",
+	}
+
+	for document, line := range expected {
+		response := get("node0001", document)
+		if response.StatusCode != http.StatusOK || !strings.Contains(response.Body, line) {
+			t.Errorf("expected %s to include '%s': %d\n%s", document, line, response.StatusCode, response.Body)
+		}
+	}
+
+	if response := get("node0002", "meta-data"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected unknown nodes not to be found, got %d", response.StatusCode)
+	}
+
+	if response := get("node0001", "vendor-data"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected unknown documents not to be found, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// historyResource describes the objects whose history is served at a resource
+type historyResource struct {
+	objectType string
+	idParam    string
+}
+
+// resources maps the history endpoints to the type of object they serve and
+// the path parameter holding the object's id
+var resources = map[string]historyResource{
+	"/node/{nodeId}/history":       {types.HistoryObjectNode, "nodeId"},
+	"/network/{networkId}/history": {types.HistoryObjectNetwork, "networkId"},
+	"/system/{systemId}/history":   {types.HistoryObjectSystem, "systemId"},
+	"/ipam/ip/{ipAddress}/history": {types.HistoryObjectIPReservation, "ipAddress"},
+}
+
+// GetHandler returns a page of the changes made to an object, oldest first
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	resource, ok := resources[request.Resource]
+	if !ok {
+		return lambdautils.ErrNotFound()
+	}
+
+	id := request.PathParameters[resource.idParam]
+	if id == "" {
+		return lambdautils.ErrBadRequest()
+	}
+
+	if resource.objectType == types.HistoryObjectIPReservation {
+		ip := net.ParseIP(id)
+		if ip == nil {
+			return lambdautils.ErrBadRequest("invalid IP address")
+		}
+		id = ip.String()
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, false)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+	records, next, err := inv.History().GetHistory(resource.objectType, id, opts)
+	return server.ListResponse(records, next, err)
+}
+
+// Handler handles requests for the history of inventory objects
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+
+	changes := []struct {
+		objectType string
+		id         string
+	}{
+		{types.HistoryObjectNode, "node1"},
+		{types.HistoryObjectNode, "node1"},
+		{types.HistoryObjectNode, "node2"},
+		{types.HistoryObjectIPReservation, "10.0.0.5"},
+	}
+	for _, c := range changes {
+		r, err := types.NewHistoryRecord(c.objectType, c.id, types.HistoryActionUpdate, "tester", nil, nil)
+		if err != nil {
+			t.Fatalf("unable to create history record: %v", err)
+		}
+		err = inv.History().AppendHistory(r)
+		if err != nil {
+			t.Fatalf("unable to append history record: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name           string
+		request        events.APIGatewayProxyRequest
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"node history", events.APIGatewayProxyRequest{Resource: "/node/{nodeId}/history", PathParameters: map[string]string{"nodeId": "node1"}}, http.StatusOK, 2},
+		{"paged node history", events.APIGatewayProxyRequest{Resource: "/node/{nodeId}/history", PathParameters: map[string]string{"nodeId": "node1"}, QueryStringParameters: map[string]string{"limit": "1"}}, http.StatusOK, 1},
+		{"unchanged system", events.APIGatewayProxyRequest{Resource: "/system/{systemId}/history", PathParameters: map[string]string{"systemId": "node1"}}, http.StatusOK, 0},
+		{"reservation history", events.APIGatewayProxyRequest{Resource: "/ipam/ip/{ipAddress}/history", PathParameters: map[string]string{"ipAddress": "10.0.0.5"}}, http.StatusOK, 1},
+		{"invalid ip", events.APIGatewayProxyRequest{Resource: "/ipam/ip/{ipAddress}/history", PathParameters: map[string]string{"ipAddress": "10.0.0"}}, http.StatusBadRequest, 0},
+		{"unsupported parameter", events.APIGatewayProxyRequest{Resource: "/node/{nodeId}/history", PathParameters: map[string]string{"nodeId": "node1"}, QueryStringParameters: map[string]string{"role": "worker"}}, http.StatusBadRequest, 0},
+		{"unknown resource", events.APIGatewayProxyRequest{Resource: "/nodeconfig/{nodeId}/history", PathParameters: map[string]string{"nodeId": "node1"}}, http.StatusNotFound, 0},
+	}
+
+	for _, c := range cases {
+		c.request.HTTPMethod = http.MethodGet
+		response, err := Handler(ctx, c.request)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+
+		if response.StatusCode != c.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d: %s", c.name, c.expectedStatus, response.StatusCode, response.Body)
+			continue
+		}
+
+		if response.StatusCode != http.StatusOK {
+			continue
+		}
+
+		records := []*types.HistoryRecord{}
+		err = json.Unmarshal([]byte(response.Body), &records)
+		if err != nil {
+			t.Errorf("%s: unable to unmarshal response: %v", c.name, err)
+		}
+
+		if len(records) != c.expectedCount {
+			t.Errorf("%s: expected %d records, got %d", c.name, c.expectedCount, len(records))
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package ipamip
 
 import (
 	"context"
@@ -8,14 +8,11 @@ import (
 	"testing"
 	"time"
 
-	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
-	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
 	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
-	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/go-test/deep"
 )
 
@@ -24,19 +21,7 @@ type testHandler func(ctx context.Context, t *testing.T)
 func runTest(t *testing.T, h testHandler) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	node := inventorytypes.NewNode()
 	node.InventoryID = "testnode"
@@ -54,7 +39,7 @@ func runTest(t *testing.T, h testHandler) {
 	_, testsubnet, _ := net.ParseCIDR("10.0.0.0/24")
 	network.Subnets = []*inventorytypes.Subnet{&inventorytypes.Subnet{Name: "testsubnet", Cidr: testsubnet, Gateway: net.ParseIP("10.0.0.1"), DynamicAllocationMethod: "random"}}
 
-	err = inv.Network().Create(network)
+	err := inv.Network().Create(network)
 	if err != nil {
 		t.Errorf("unable to create test network record: %v", err)
 	}
@@ -90,7 +75,7 @@ func runTest(t *testing.T, h testHandler) {
 		t.Errorf("unable to get reservation for gateway: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	h(handlerCtx, t)
 
@@ -365,6 +350,27 @@ func TestCreateReservationConflict(t *testing.T) {
 	})
 }
 
+func TestCreateReservationExcluded(t *testing.T) {
+	runTest(t, func(handlerCtx context.Context, t *testing.T) {
+		// Post to ip endpoint with the broadcast address, which can never be allocated.  Should return Bad Request.
+		response, err := Handler(handlerCtx, events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPost,
+			PathParameters: map[string]string{"ipAddress": "10.0.0.255"},
+			Body: `
+			{
+				"mac": "01:02:03:04:05:06",
+				"name": "test-entry"
+			}`,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating reservation for excluded address: %v", err)
+		}
+		if response.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected bad request status, got: %d", response.StatusCode)
+		}
+	})
+}
+
 // func TestCreateReservationExpiredConflict(t *testing.T) {
 // 	runTest(t, func(handlerCtx context.Context, t *testing.T) {
 // 		// Post to ip endpoint with MAC, and IP already reserved for another host - but has expired.  Should return success.
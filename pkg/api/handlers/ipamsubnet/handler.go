@@ -0,0 +1,131 @@
+package ipamsubnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// DefaultFree is the number of free addresses listed if the request
+	// doesn't specify how many
+	DefaultFree = 10
+
+	// MaxFree is the largest number of free addresses that may be requested
+	MaxFree = 1000
+)
+
+// parseFree parses the number of free addresses requested in the query
+func parseFree(query map[string]string) (int, error) {
+	value, ok := query["free"]
+	if !ok {
+		return DefaultFree, nil
+	}
+
+	free, err := strconv.Atoi(value)
+	if err != nil || free < 0 || free > MaxFree {
+		return 0, fmt.Errorf("free must be an integer between 0 and %d", MaxFree)
+	}
+	return free, nil
+}
+
+// lookupSubnet finds the subnet with the cidr specified, or the subnet
+// containing the address if only an address is specified
+func lookupSubnet(inv inventory.Store, cidr string) (*types.Subnet, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		ip = net.ParseIP(cidr)
+	}
+
+	networks, err := inv.Network().GetNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks {
+		for _, subnet := range network.Subnets {
+			if ipNet != nil && subnet.Cidr.String() == ipNet.String() || ipNet == nil && subnet.Cidr.Contains(ip) {
+				return subnet, nil
+			}
+		}
+	}
+	return nil, inventory.ErrObjectNotFound
+}
+
+// validSubnet returns true if cidr is a cidr or an address
+func validSubnet(cidr string) bool {
+	_, _, err := net.ParseCIDR(cidr)
+	return err == nil || net.ParseIP(cidr) != nil
+}
+
+// SubnetHandler reports the usage of the subnet in the cidr query parameter.
+// The subnet is taken from the query rather than the path, because api
+// gateway decodes the escaped slash in a cidr before matching the path.
+func SubnetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	free, err := parseFree(request.QueryStringParameters)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	cidr := request.QueryStringParameters["cidr"]
+	if !validSubnet(cidr) {
+		return lambdautils.ErrBadRequest("invalid subnet")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	subnet, err := lookupSubnet(inv, cidr)
+	if err != nil {
+		return server.GetObjectResponse(nil, err)
+	}
+
+	usage, err := inventory.GetSubnetUsage(inv.IPReservation(), subnet, free)
+	return server.GetObjectResponse(usage, err)
+}
+
+// NetworkHandler reports the usage of every subnet in a network
+func NetworkHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	free, err := parseFree(request.QueryStringParameters)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	networkID, ok := request.PathParameters["networkId"]
+	if !ok {
+		return lambdautils.ErrBadRequest()
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	network, err := inv.Network().GetNetworkByID(networkID)
+	if err != nil {
+		return server.GetObjectResponse(nil, err)
+	}
+
+	usage, err := inventory.GetNetworkUsage(inv.IPReservation(), network, free)
+	return server.GetObjectResponse(usage, err)
+}
+
+// Handler handles requests for subnet usage
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodGet {
+		return lambdautils.ErrNotImplemented()
+	}
+
+	switch request.Resource {
+	case "/ipam/subnet":
+		return SubnetHandler(ctx, request)
+	case "/network/{networkId}/usage":
+		return NetworkHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotFound()
+	}
+}
@@ -0,0 +1,117 @@
+package ipamsubnet
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+
+	_, v4, _ := net.ParseCIDR("10.0.0.0/24")
+	_, v6, _ := net.ParseCIDR("2001:db8::/120")
+	network := &types.Network{Name: "provisioning", Subnets: types.SubnetList{
+		{Name: "v4", Cidr: v4, Gateway: net.ParseIP("10.0.0.1"), Exclusions: []ipam.IPRange{{Start: net.ParseIP("10.0.0.240"), End: net.ParseIP("10.0.0.254")}}},
+		{Name: "v6", Cidr: v6},
+	}}
+	err := inv.Network().Create(network)
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	now := time.Now()
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	reservations := []*types.IPReservation{
+		{IP: &net.IPNet{IP: net.ParseIP("10.0.0.2"), Mask: v4.Mask}, MAC: mac, Start: &now},
+		types.NewDynamicIPReservation(time.Hour),
+		types.NewDynamicIPReservation(-time.Minute),
+	}
+	reservations[1].IP = &net.IPNet{IP: net.ParseIP("10.0.0.4"), Mask: v4.Mask}
+	// expired reservations are counted until they're reaped, but don't prevent
+	// their addresses being allocated
+	reservations[2].IP = &net.IPNet{IP: net.ParseIP("10.0.0.3"), Mask: v4.Mask}
+	for _, r := range reservations {
+		err = inv.IPReservation().CreateIPReservation(r)
+		if err != nil {
+			t.Fatalf("unable to create reservation: %v", err)
+		}
+	}
+
+	response, err := Handler(ctx, events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		Resource:              "/ipam/subnet",
+		QueryStringParameters: map[string]string{"cidr": "10.0.0.0/24", "free": "3"},
+	})
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("unable to get subnet usage: %v %v", err, response)
+	}
+
+	usage := &types.SubnetUsage{}
+	err = json.Unmarshal([]byte(response.Body), usage)
+	if err != nil {
+		t.Fatalf("unable to unmarshal subnet usage: %v", err)
+	}
+
+	if usage.Total.Int64() != 256 || usage.Usable.Int64() != 238 || usage.Allocated != 2 || usage.Static != 1 || usage.Dynamic != 1 || usage.Expired != 1 || len(usage.Reservations) != 2 {
+		t.Errorf("got wrong usage: %+v", usage)
+	}
+
+	expectedFree := []string{"10.0.0.3", "10.0.0.5", "10.0.0.6"}
+	if len(usage.Free) != len(expectedFree) {
+		t.Fatalf("expected free addresses %v, got %v", expectedFree, usage.Free)
+	}
+	for i, ip := range expectedFree {
+		if !usage.Free[i].Equal(net.ParseIP(ip)) {
+			t.Errorf("expected free addresses %v, got %v", expectedFree, usage.Free)
+		}
+	}
+
+	response, err = Handler(ctx, events.APIGatewayProxyRequest{
+		HTTPMethod:     http.MethodGet,
+		Resource:       "/network/{networkId}/usage",
+		PathParameters: map[string]string{"networkId": "provisioning"},
+	})
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("unable to get network usage: %v %v", err, response)
+	}
+
+	networkUsage := &types.NetworkUsage{}
+	err = json.Unmarshal([]byte(response.Body), networkUsage)
+	if err != nil {
+		t.Fatalf("unable to unmarshal network usage: %v", err)
+	}
+
+	if networkUsage.Total.Int64() != 512 || networkUsage.Usable.Int64() != 238+255 || networkUsage.Allocated != 2 || networkUsage.Expired != 1 || len(networkUsage.Subnets) != 2 {
+		t.Errorf("got wrong network usage: %+v", networkUsage)
+	}
+
+	errorCases := map[string]struct {
+		request        events.APIGatewayProxyRequest
+		expectedStatus int
+	}{
+		"unknown subnet":  {events.APIGatewayProxyRequest{Resource: "/ipam/subnet", QueryStringParameters: map[string]string{"cidr": "10.1.0.0/24"}}, http.StatusNotFound},
+		"missing subnet":  {events.APIGatewayProxyRequest{Resource: "/ipam/subnet"}, http.StatusBadRequest},
+		"invalid subnet":  {events.APIGatewayProxyRequest{Resource: "/ipam/subnet", QueryStringParameters: map[string]string{"cidr": "10.0.0"}}, http.StatusBadRequest},
+		"invalid free":    {events.APIGatewayProxyRequest{Resource: "/ipam/subnet", QueryStringParameters: map[string]string{"cidr": "10.0.0.5", "free": "-1"}}, http.StatusBadRequest},
+		"unknown network": {events.APIGatewayProxyRequest{Resource: "/network/{networkId}/usage", PathParameters: map[string]string{"networkId": "missing"}}, http.StatusNotFound},
+	}
+
+	for name, c := range errorCases {
+		c.request.HTTPMethod = http.MethodGet
+		response, err := Handler(ctx, c.request)
+		if err != nil || response.StatusCode != c.expectedStatus {
+			t.Errorf("%s: expected status %d, got %v %v", name, c.expectedStatus, err, response)
+		}
+	}
+}
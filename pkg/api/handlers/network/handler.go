@@ -0,0 +1,135 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// GetHandler handles GET method requests from the API gateway
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if networkID, ok := request.PathParameters["networkId"]; ok {
+		network, err := inv.Network().GetNetworkByID(networkID)
+		return server.GetObjectResponse(network, err)
+	}
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrBadRequest()
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, false)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	networks, next, err := inv.Network().ListNetworks(opts)
+	return server.ListResponse(networks, next, err)
+}
+
+// PutHandler updates the specified network record
+func PutHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	networkId, ok := request.PathParameters["networkId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Updating all networks not allowed.")
+	}
+
+	// parse request body.  Should be a network
+	updatedNetwork := &inventorytypes.Network{}
+	err := json.Unmarshal([]byte(request.Body), updatedNetwork)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid network.")
+	}
+
+	err = inventory.ValidateNetwork(updatedNetwork)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.UpdateObject(inv.WithActor(server.Actor(request)).Network(), updatedNetwork, networkId, server.IfMatch(request))
+}
+
+// PostHandler updates the specified network record
+func PostHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Posting not allowed here.")
+	}
+
+	// parse request body.  Should be a network
+	newNetwork := &inventorytypes.Network{}
+	err := json.Unmarshal([]byte(request.Body), newNetwork)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid network.")
+	}
+
+	err = inventory.ValidateNetwork(newNetwork)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.CreateObject(inv.WithActor(server.Actor(request)).Network(), newNetwork)
+}
+
+// PatchHandler applies a merge patch or json patch to the specified network record
+func PatchHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	networkId, ok := request.PathParameters["networkId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Patching all networks not allowed.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	get := func() (server.InventoryObject, error) {
+		return inv.Network().GetNetworkByID(networkId)
+	}
+	newObj := func() server.InventoryObject {
+		return &inventorytypes.Network{}
+	}
+	validate := func(obj server.InventoryObject) error {
+		return inventory.ValidateNetwork(obj.(*inventorytypes.Network))
+	}
+	return server.PatchObject(inv.WithActor(server.Actor(request)).Network(), request, get, newObj, validate)
+}
+
+// DeleteHandler updates the specified network record
+func DeleteHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	networkId, ok := request.PathParameters["networkId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Deleting all networks not allowed.")
+	}
+	network := &inventorytypes.Network{Name: networkId}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.DeleteObject(inv.WithActor(server.Actor(request)).Network(), network, server.IfMatch(request))
+}
+
+// Handler handles requests for nodes
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	case http.MethodPut:
+		return PutHandler(ctx, request)
+	case http.MethodPost:
+		return PostHandler(ctx, request)
+	case http.MethodDelete:
+		return DeleteHandler(ctx, request)
+	case http.MethodPatch:
+		return PatchHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
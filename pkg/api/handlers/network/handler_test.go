@@ -1,4 +1,4 @@
-package main
+package network
 
 import (
 	"context"
@@ -8,32 +8,17 @@ import (
 	"testing"
 	"time"
 
-	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
 	"github.com/PolarGeospatialCenter/inventory/pkg/api/testutils"
-	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
 	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
-	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func TestHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	network := inventorytypes.NewNetwork()
 	network.Name = "testnetwork"
@@ -58,7 +43,7 @@ func TestHandler(t *testing.T) {
 		t.Errorf("unable to marshal updated network: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	cases := testutils.TestCases{
 		testutils.TestCase{Ctx: handlerCtx,
@@ -108,6 +93,7 @@ func TestHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: updatedNetwork,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -119,6 +105,7 @@ func TestHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: updatedNetwork,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -129,6 +116,7 @@ func TestHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: []*inventorytypes.Network{&updatedNetwork},
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -172,3 +160,105 @@ func TestHandler(t *testing.T) {
 	}
 	cases.RunTests(t, Handler)
 }
+
+func TestPatchHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inv := memorystore.NewMemoryStore()
+
+	network := inventorytypes.NewNetwork()
+	network.Name = "testnetwork"
+	network.MTU = 1500
+	network.Domain = "foo"
+	network.Metadata = inventorytypes.Metadata{"teststring": "test"}
+	network.LastUpdated = time.Now()
+	err := inv.Network().Create(network)
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	patchedNetwork := *network
+	patchedNetwork.MTU = 9000
+	patchedNetwork.Metadata = inventorytypes.Metadata{}
+	patchedNetwork.Subnets = []*inventorytypes.Subnet{}
+
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
+
+	cases := testutils.TestCases{
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Merge patch network",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPatch,
+				PathParameters: map[string]string{"networkId": "testnetwork"},
+				Headers:        map[string]string{"content-type": server.MergePatchContentType},
+				Body:           `{"MTU":9000,"Metadata":{"teststring":null}}`,
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedBodyObject: &patchedNetwork,
+				IgnoredBodyFields:  []string{"LastUpdated"},
+				ExpectedStatus:     http.StatusOK,
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Patch missing network",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPatch,
+				PathParameters: map[string]string{"networkId": "missing"},
+				Body:           `{"MTU":9000}`,
+			},
+			TestResult: testutils.ExpectError(http.StatusNotFound, "Object not found"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Patch all networks",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPatch,
+				Body:       `{"MTU":9000}`,
+			},
+			TestResult: testutils.ExpectError(http.StatusMethodNotAllowed, "Patching all networks not allowed."),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Create network with unknown allocation method",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Body:       `{"Name":"badnetwork","Subnets":[{"Cidr":"10.1.0.0/24","StaticAllocationMethod":"bogus"}]}`,
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "invalid subnet 10.1.0.0/24: Unknown allocation method: 'bogus'"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Patch network with unknown allocation method",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPatch,
+				PathParameters: map[string]string{"networkId": "testnetwork"},
+				Headers:        map[string]string{"content-type": server.MergePatchContentType},
+				Body:           `{"Subnets":[{"Cidr":"10.1.0.0/24","StaticAllocationMethod":"bogus"}]}`,
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "invalid subnet 10.1.0.0/24: Unknown allocation method: 'bogus'"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Patch network with allocation range outside the subnet",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPatch,
+				PathParameters: map[string]string{"networkId": "testnetwork"},
+				Headers:        map[string]string{"content-type": server.MergePatchContentType},
+				Body:           `{"Subnets":[{"Cidr":"10.1.0.0/24","AllocationRanges":[{"Start":"10.2.0.10","End":"10.2.0.20"}]}]}`,
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "invalid subnet 10.1.0.0/24: range 10.2.0.10-10.2.0.20 isn't within 10.1.0.0/24"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Patch network with exclusion outside the subnet",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPatch,
+				PathParameters: map[string]string{"networkId": "testnetwork"},
+				Headers:        map[string]string{"content-type": server.JSONPatchContentType},
+				Body:           `[{"op":"add","path":"/Subnets","value":[{"Cidr":"10.1.0.0/24","Exclusions":[{"Start":"10.1.1.1"}]}]}]`,
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "invalid subnet 10.1.0.0/24: range 10.1.1.1 isn't within 10.1.0.0/24"),
+		},
+	}
+	cases.RunTests(t, Handler)
+
+	stored, err := inv.Network().GetNetworkByID("testnetwork")
+	if err != nil || len(stored.Subnets) != 0 {
+		t.Errorf("expected the invalid patch not to be written, got %v: %v", stored, err)
+	}
+}
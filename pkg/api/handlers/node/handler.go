@@ -0,0 +1,131 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// GetHandler handles GET method requests from the API gateway
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if nodeId, ok := request.PathParameters["nodeId"]; ok {
+		return server.GetObjectResponse(inv.Node().GetNodeByID(nodeId))
+	}
+
+	if macString, ok := request.QueryStringParameters["mac"]; ok {
+		mac, err := net.ParseMAC(macString)
+		if err != nil {
+			return lambdautils.ErrBadRequest(err.Error())
+		}
+
+		node, err := inv.Node().GetNodeByMAC(mac)
+		return server.GetObjectResponse([]*inventorytypes.Node{node}, err)
+	} else if nodeID, ok := request.QueryStringParameters["id"]; ok {
+		node, err := inv.Node().GetNodeByID(nodeID)
+		return server.GetObjectResponse([]*inventorytypes.Node{node}, err)
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, true)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	nodes, next, err := inv.Node().ListNodes(opts)
+	return server.ListResponse(nodes, next, err)
+}
+
+// PutHandler updates the specified node record
+func PutHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	nodeId, ok := request.PathParameters["nodeId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Updating all nodes not allowed.")
+	}
+
+	// parse request body.  Should be a node
+	updatedNode := &inventorytypes.Node{}
+	err := json.Unmarshal([]byte(request.Body), updatedNode)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid node.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.UpdateObject(inv.WithActor(server.Actor(request)).Node(), updatedNode, nodeId, server.IfMatch(request))
+}
+
+// PostHandler updates the specified node record
+func PostHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Posting not allowed here.")
+	}
+
+	// parse request body.  Should be a node
+	newNode := &inventorytypes.Node{}
+	err := json.Unmarshal([]byte(request.Body), newNode)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid node.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.CreateObject(inv.WithActor(server.Actor(request)).Node(), newNode)
+}
+
+// PatchHandler applies a merge patch or json patch to the specified node record
+func PatchHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	nodeId, ok := request.PathParameters["nodeId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Patching all nodes not allowed.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	get := func() (server.InventoryObject, error) {
+		return inv.Node().GetNodeByID(nodeId)
+	}
+	newObj := func() server.InventoryObject {
+		return &inventorytypes.Node{}
+	}
+	return server.PatchObject(inv.WithActor(server.Actor(request)).Node(), request, get, newObj, nil)
+}
+
+// DeleteHandler updates the specified node record
+func DeleteHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	nodeId, ok := request.PathParameters["nodeId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Deleting all nodes not allowed.")
+	}
+	node := &inventorytypes.Node{InventoryID: nodeId}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.DeleteObject(inv.WithActor(server.Actor(request)).Node(), node, server.IfMatch(request))
+}
+
+// Handler handles requests for nodes
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	case http.MethodPut:
+		return PutHandler(ctx, request)
+	case http.MethodPost:
+		return PostHandler(ctx, request)
+	case http.MethodDelete:
+		return DeleteHandler(ctx, request)
+	case http.MethodPatch:
+		return PatchHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
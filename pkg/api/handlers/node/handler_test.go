@@ -1,22 +1,21 @@
-package main
+package node
 
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"testing"
 	"time"
 
-	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
 	"github.com/PolarGeospatialCenter/inventory/pkg/api/testutils"
-	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
 	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
-	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func testNode() *inventorytypes.Node {
@@ -35,21 +34,9 @@ func testNode() *inventorytypes.Node {
 func TestGetHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
-	err = inv.Network().Create(&types.Network{Name: "testnet"})
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
 	if err != nil {
 		t.Fatalf("unable to create network: %v", err)
 	}
@@ -60,7 +47,7 @@ func TestGetHandler(t *testing.T) {
 		t.Fatalf("unable to create test record: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	cases := testutils.TestCases{
 		testutils.TestCase{Ctx: handlerCtx,
@@ -138,7 +125,7 @@ func TestGetHandler(t *testing.T) {
 				HTTPMethod:            http.MethodGet,
 				QueryStringParameters: map[string]string{"badparam": "foo"},
 			},
-			TestResult: testutils.ExpectError(http.StatusBadRequest),
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "unsupported query parameter: badparam"),
 		},
 		testutils.TestCase{Ctx: handlerCtx,
 			Name:    "Get all nodes",
@@ -155,21 +142,9 @@ func TestGetHandler(t *testing.T) {
 func TestGetHandlerNullEntries(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
+	inv := memorystore.NewMemoryStore()
 
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
-
-	err = inv.Network().Create(&types.Network{Name: "testnet"})
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
 	if err != nil {
 		t.Fatalf("unable to create network: %v", err)
 	}
@@ -185,7 +160,7 @@ func TestGetHandlerNullEntries(t *testing.T) {
 	node.Metadata = inventorytypes.Metadata{}
 	node.Tags = []string{}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	cases := testutils.TestCases{
 		testutils.TestCase{Ctx: handlerCtx,
@@ -206,22 +181,10 @@ func TestGetHandlerNullEntries(t *testing.T) {
 func TestPutHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	testNet := &types.Network{Name: "testnet"}
-	err = inv.Network().Update(testNet)
+	err := inv.Network().Update(testNet)
 	if err != nil {
 		t.Fatalf("unable to create test network: %v", err)
 	}
@@ -233,7 +196,7 @@ func TestPutHandler(t *testing.T) {
 		t.Errorf("Unable to marshal node json: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	updatedMac, _ := net.ParseMAC("01:02:03:04:05:06")
 	updatedNode := *node
@@ -266,6 +229,7 @@ func TestPutHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: updatedNode,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -278,6 +242,7 @@ func TestPutHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: &updatedNode,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -295,19 +260,7 @@ func TestPutHandler(t *testing.T) {
 func TestPostHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	testNet := &types.Network{
 		Name: "testnet",
@@ -322,7 +275,7 @@ func TestPostHandler(t *testing.T) {
 			},
 		},
 	}
-	err = inv.Network().Update(testNet)
+	err := inv.Network().Update(testNet)
 	if err != nil {
 		t.Fatalf("unable to create test network: %v", err)
 	}
@@ -330,7 +283,7 @@ func TestPostHandler(t *testing.T) {
 	node := testNode()
 	node.InventoryID = "testnode-002"
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	nodeJson, err := json.Marshal(node)
 	if err != nil {
@@ -364,21 +317,9 @@ func TestPostHandler(t *testing.T) {
 func TestDeleteHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
+	inv := memorystore.NewMemoryStore()
 
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
-
-	err = inv.Network().Create(&types.Network{Name: "testnet"})
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
 	if err != nil {
 		t.Fatalf("unable to create network: %v", err)
 	}
@@ -390,7 +331,7 @@ func TestDeleteHandler(t *testing.T) {
 		t.Errorf("unable to create test record: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	cases := testutils.TestCases{
 		testutils.TestCase{Ctx: handlerCtx,
@@ -422,3 +363,237 @@ func TestDeleteHandler(t *testing.T) {
 	}
 	cases.RunTests(t, Handler)
 }
+
+func TestListHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inv := memorystore.NewMemoryStore()
+
+	nodes := make([]*inventorytypes.Node, 0)
+	for i, system := range []string{"foo", "bar", "foo", "foo"} {
+		node := &inventorytypes.Node{
+			InventoryID: fmt.Sprintf("node%04d", i),
+			System:      system,
+			Networks:    inventorytypes.NICInfoMap{},
+			Tags:        inventorytypes.Tags{},
+			Metadata:    inventorytypes.Metadata{},
+			LastUpdated: time.Now(),
+		}
+		err := inv.Node().Create(node)
+		if err != nil {
+			t.Fatalf("unable to create test record: %v", err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
+
+	cases := testutils.TestCases{
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "First page of filtered nodes",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				QueryStringParameters: map[string]string{"system": "foo", "limit": "2"},
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedBodyObject: []*inventorytypes.Node{nodes[0], nodes[2]},
+				ExpectedStatus:     http.StatusOK,
+				ExpectedHeaders:    map[string]string{server.NextCursorHeader: inventory.EncodeCursor([]byte("node0002"))},
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Last page of filtered nodes",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				QueryStringParameters: map[string]string{"system": "foo", "limit": "2", "next": inventory.EncodeCursor([]byte("node0002"))},
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedBodyObject: []*inventorytypes.Node{nodes[3]},
+				ExpectedStatus:     http.StatusOK,
+				ExpectedHeaders:    map[string]string{server.NextCursorHeader: ""},
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Invalid limit",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				QueryStringParameters: map[string]string{"limit": "0"},
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "limit must be a positive integer"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Invalid cursor",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				QueryStringParameters: map[string]string{"next": "%%%"},
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, inventory.ErrInvalidCursor.Error()),
+		},
+	}
+	cases.RunTests(t, Handler)
+}
+
+func TestConditionalWrites(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inv := memorystore.NewMemoryStore()
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
+
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	err = inv.Node().Create(testNode())
+	if err != nil {
+		t.Fatalf("unable to create test record: %v", err)
+	}
+
+	pathParameters := map[string]string{"nodeId": "testnode"}
+	response, err := Handler(handlerCtx, events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, PathParameters: pathParameters})
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("unable to get node: %v %v", response, err)
+	}
+	etag := response.Headers[server.ETagHeader]
+	if etag == "" {
+		t.Fatalf("no etag returned with node")
+	}
+
+	update := func(role string, ifMatch string) *events.APIGatewayProxyResponse {
+		node := testNode()
+		node.Role = role
+		body, _ := json.Marshal(node)
+		response, err := Handler(handlerCtx, events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPut,
+			PathParameters: pathParameters,
+			Headers:        map[string]string{"If-Match": ifMatch},
+			Body:           string(body),
+		})
+		if err != nil {
+			t.Fatalf("error updating node: %v", err)
+		}
+		return response
+	}
+
+	response = update("worker", etag)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("update with current etag failed: %s", response.Body)
+	}
+	updatedETag := response.Headers[server.ETagHeader]
+	if updatedETag == "" || updatedETag == etag {
+		t.Errorf("expected a new etag after update, got '%s'", updatedETag)
+	}
+
+	response = update("master", etag)
+	if response.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected stale update to fail with 412, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	node, err := inv.Node().GetNodeByID("testnode")
+	if err != nil || node.Role != "worker" {
+		t.Errorf("stale update overwrote the node: %v %v", node, err)
+	}
+
+	for _, ifMatch := range []string{etag, "not-an-etag"} {
+		response, err = Handler(handlerCtx, events.APIGatewayProxyRequest{HTTPMethod: http.MethodDelete, PathParameters: pathParameters, Headers: map[string]string{"If-Match": ifMatch}})
+		if err != nil || response.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("expected delete with If-Match %s to fail with 412: %v %v", ifMatch, response, err)
+		}
+	}
+
+	response, err = Handler(handlerCtx, events.APIGatewayProxyRequest{HTTPMethod: http.MethodDelete, PathParameters: pathParameters, Headers: map[string]string{"If-Match": updatedETag}})
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("delete with current etag failed: %v %v", response, err)
+	}
+}
+
+func TestPatchHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inv := memorystore.NewMemoryStore()
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
+
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	err = inv.Node().Create(testNode())
+	if err != nil {
+		t.Fatalf("unable to create test record: %v", err)
+	}
+
+	patch := func(contentType string, body string, headers map[string]string) *events.APIGatewayProxyResponse {
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers["Content-Type"] = contentType
+		response, err := Handler(handlerCtx, events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPatch,
+			PathParameters: map[string]string{"nodeId": "testnode"},
+			Headers:        headers,
+			Body:           body,
+		})
+		if err != nil {
+			t.Fatalf("error patching node: %v", err)
+		}
+		return response
+	}
+
+	response := patch(server.MergePatchContentType, `{"Role":"worker","Metadata":{"owner":"ops"}}`, nil)
+	if response.StatusCode != http.StatusOK || response.Headers[server.ETagHeader] == "" {
+		t.Fatalf("merge patch failed: %d %s", response.StatusCode, response.Body)
+	}
+
+	node, err := inv.Node().GetNodeByID("testnode")
+	if err != nil || node.Role != "worker" || node.Metadata["owner"] != "ops" || len(node.Networks["testnet"].NICs) != 1 {
+		t.Errorf("merge patch not applied correctly: %v %v", node, err)
+	}
+
+	response = patch(server.JSONPatchContentType, `[{"op":"test","path":"/Role","value":"worker"},{"op":"add","path":"/Networks/testnet/nics/-","value":"00:01:02:03:04:06"}]`, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("json patch failed: %d %s", response.StatusCode, response.Body)
+	}
+
+	// the patched node is written through the node store, so the mac index is updated
+	mac, _ := net.ParseMAC("00:01:02:03:04:06")
+	node, err = inv.Node().GetNodeByMAC(mac)
+	if err != nil || node.ID() != "testnode" {
+		t.Errorf("unable to lookup node by patched mac: %v %v", node, err)
+	}
+
+	cases := []struct {
+		Name           string
+		ContentType    string
+		Body           string
+		Headers        map[string]string
+		ExpectedStatus int
+	}{
+		{"failed test", server.JSONPatchContentType, `[{"op":"test","path":"/Role","value":"master"}]`, nil, http.StatusConflict},
+		{"invalid json patch", server.JSONPatchContentType, `{"Role":"master"}`, nil, http.StatusBadRequest},
+		{"change id", server.MergePatchContentType, `{"InventoryID":"othernode"}`, nil, http.StatusBadRequest},
+		{"invalid node", server.MergePatchContentType, `{"Networks":"none"}`, nil, http.StatusBadRequest},
+		{"unsupported content type", "text/plain", `{"Role":"master"}`, nil, http.StatusUnsupportedMediaType},
+		{"stale etag", server.MergePatchContentType, `{"Role":"master"}`, map[string]string{"If-Match": server.ETag(time.Time{})}, http.StatusPreconditionFailed},
+	}
+	for _, c := range cases {
+		response = patch(c.ContentType, c.Body, c.Headers)
+		if response.StatusCode != c.ExpectedStatus {
+			t.Errorf("%s: expected status %d, got %d: %s", c.Name, c.ExpectedStatus, response.StatusCode, response.Body)
+		}
+	}
+
+	node, err = inv.Node().GetNodeByID("testnode")
+	if err != nil || node.Role != "worker" {
+		t.Errorf("failed patches modified the node: %v %v", node, err)
+	}
+
+	response, err = Handler(handlerCtx, events.APIGatewayProxyRequest{
+		HTTPMethod:     http.MethodPatch,
+		PathParameters: map[string]string{"nodeId": "missing"},
+		Body:           `{"Role":"worker"}`,
+	})
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected not found patching missing node: %v %v", response, err)
+	}
+}
@@ -0,0 +1,75 @@
+package nodeconfig
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// GetHandler handles GET method requests from the API gateway.  A node can be
+// looked up as it would have been compiled at a past time with the at query
+// parameter.
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if atString, ok := request.QueryStringParameters["at"]; ok {
+		// looking up a node as it was compiled at a past time
+		nodeId, ok := request.PathParameters["nodeId"]
+		if !ok {
+			return lambdautils.ErrBadRequest("at is only supported when looking up a node by id")
+		}
+
+		at, err := time.Parse(time.RFC3339, atString)
+		if err != nil {
+			return lambdautils.ErrBadRequest(fmt.Sprintf("at must be an RFC3339 timestamp: %v", err))
+		}
+
+		node, err := inv.InventoryNode().GetInventoryNodeByIDAt(nodeId, at)
+		return server.GetObjectResponse(node, err)
+	}
+
+	if nodeId, ok := request.PathParameters["nodeId"]; ok {
+		// looking up an individual node
+		node, err := inv.InventoryNode().GetInventoryNodeByID(nodeId)
+		return server.GetObjectResponse(node, err)
+	}
+
+	if macString, ok := request.QueryStringParameters["mac"]; ok {
+		mac, err := net.ParseMAC(macString)
+		if err != nil {
+			return lambdautils.ErrBadRequest(err.Error())
+		}
+
+		node, err := inv.InventoryNode().GetInventoryNodeByMAC(mac)
+		return server.GetObjectResponse([]*inventorytypes.InventoryNode{node}, err)
+	} else if nodeID, ok := request.QueryStringParameters["id"]; ok {
+		node, err := inv.InventoryNode().GetInventoryNodeByID(nodeID)
+		return server.GetObjectResponse([]*inventorytypes.InventoryNode{node}, err)
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, true)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	nodes, next, err := inv.InventoryNode().ListInventoryNodes(opts)
+	return server.ListResponse(nodes, next, err)
+}
+
+// Handler handles requests for nodes
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
@@ -1,38 +1,24 @@
-package main
+package nodeconfig
 
 import (
 	"context"
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
-	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
 	"github.com/PolarGeospatialCenter/inventory/pkg/api/testutils"
-	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
 	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
-	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func TestGetHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	node := inventorytypes.NewNode()
 	node.InventoryID = "testnode"
@@ -42,6 +28,7 @@ func TestGetHandler(t *testing.T) {
 	node.Role = "Role1"
 	node.Tags = inventorytypes.Tags{}
 	node.Metadata = inventorytypes.Metadata{}
+	node.LastUpdated = time.Now()
 	node.Networks = types.NICInfoMap{
 		"testnetwork": &inventorytypes.NetworkInterface{NICs: []net.HardwareAddr{testMac}, Metadata: types.Metadata{}},
 	}
@@ -56,7 +43,7 @@ func TestGetHandler(t *testing.T) {
 	_, testsubnet, _ := net.ParseCIDR("10.0.0.0/24")
 	network.Subnets = []*inventorytypes.Subnet{&inventorytypes.Subnet{Cidr: testsubnet}}
 
-	err = inv.Network().Create(network)
+	err := inv.Network().Create(network)
 	if err != nil {
 		t.Errorf("unable to create test record: %v", err)
 	}
@@ -85,7 +72,7 @@ func TestGetHandler(t *testing.T) {
 		t.Errorf("unable to create test record: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 	inv.IPReservation().CreateIPReservation(&types.IPReservation{
 		IP:  &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)},
 		MAC: testMac,
@@ -116,6 +103,36 @@ func TestGetHandler(t *testing.T) {
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Lookup test node at a time after it was created",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				PathParameters:        map[string]string{"nodeId": "testnode"},
+				QueryStringParameters: map[string]string{"at": time.Now().Add(time.Hour).Format(time.RFC3339)},
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedBodyObject: inventoryNode,
+				ExpectedStatus:     http.StatusOK,
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Lookup test node at a time before it was created",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				PathParameters:        map[string]string{"nodeId": "testnode"},
+				QueryStringParameters: map[string]string{"at": "2000-01-01T00:00:00Z"},
+			},
+			TestResult: testutils.ExpectError(http.StatusNotFound, "Object not found"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Test at input validation",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				PathParameters:        map[string]string{"nodeId": "testnode"},
+				QueryStringParameters: map[string]string{"at": "last week"},
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, `at must be an RFC3339 timestamp: parsing time "last week" as "2006-01-02T15:04:05Z07:00": cannot parse "last week" as "2006"`),
+		},
 		testutils.TestCase{Ctx: handlerCtx,
 			Name: "Lookup test node by id query",
 			Request: events.APIGatewayProxyRequest{
@@ -0,0 +1,29 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Actor is recorded in history as the author of each reaped reservation
+const Actor = "reaper"
+
+// Handler runs on a schedule, archiving ip reservations that have expired.
+// Expired reservations are never allocated from, so this only keeps them from
+// accumulating in the reservation table.
+func Handler(ctx context.Context, event events.CloudWatchEvent) error {
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	reaped, err := inventory.ReapExpiredIPReservations(inv, time.Now(), Actor)
+	log.Printf("reaped %d expired ip reservations", len(reaped))
+	if err != nil {
+		return fmt.Errorf("unable to reap expired ip reservations: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package reaper
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+
+	start := time.Now().Add(-2 * time.Hour)
+	end := time.Now().Add(-time.Hour)
+	r := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}, Start: &start, End: &end}
+	err := inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = Handler(ctx, events.CloudWatchEvent{DetailType: "Scheduled Event"})
+	if err != nil {
+		t.Fatalf("unable to reap reservations: %v", err)
+	}
+
+	expired, err := inv.IPReservation().GetExpiredIPReservations(time.Now())
+	if err != nil || len(expired) != 0 {
+		t.Errorf("expected expired reservations to be reaped, got %v %v", expired, err)
+	}
+}
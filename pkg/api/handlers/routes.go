@@ -0,0 +1,88 @@
+// Package handlers collects the api gateway handlers so that they can be
+// served outside of lambda.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/boot"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/health"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/history"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/ipamip"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/ipamsubnet"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/network"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/node"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/nodeconfig"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/system"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/handlers/webhook"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+)
+
+// Route maps a method and api gateway resource to a handler
+type Route struct {
+	Method   string
+	Resource string
+	Handler  server.LambdaHandler
+}
+
+// Routes lists the api endpoints, matching the paths in template.yml
+var Routes = []Route{
+	{http.MethodGet, "/health", health.Handler},
+
+	{http.MethodGet, "/node", node.Handler},
+	{http.MethodPost, "/node", node.Handler},
+	{http.MethodGet, "/node/{nodeId}", node.Handler},
+	{http.MethodPut, "/node/{nodeId}", node.Handler},
+	{http.MethodPatch, "/node/{nodeId}", node.Handler},
+	{http.MethodDelete, "/node/{nodeId}", node.Handler},
+	{http.MethodGet, "/node/{nodeId}/history", history.Handler},
+
+	{http.MethodGet, "/network", network.Handler},
+	{http.MethodPost, "/network", network.Handler},
+	{http.MethodGet, "/network/{networkId}", network.Handler},
+	{http.MethodPut, "/network/{networkId}", network.Handler},
+	{http.MethodPatch, "/network/{networkId}", network.Handler},
+	{http.MethodDelete, "/network/{networkId}", network.Handler},
+	{http.MethodGet, "/network/{networkId}/history", history.Handler},
+	{http.MethodGet, "/network/{networkId}/usage", ipamsubnet.Handler},
+
+	{http.MethodGet, "/system", system.Handler},
+	{http.MethodPost, "/system", system.Handler},
+	{http.MethodGet, "/system/{systemId}", system.Handler},
+	{http.MethodPut, "/system/{systemId}", system.Handler},
+	{http.MethodPatch, "/system/{systemId}", system.Handler},
+	{http.MethodDelete, "/system/{systemId}", system.Handler},
+	{http.MethodGet, "/system/{systemId}/history", history.Handler},
+
+	{http.MethodGet, "/nodeconfig", nodeconfig.Handler},
+	{http.MethodGet, "/nodeconfig/{nodeId}", nodeconfig.Handler},
+
+	{http.MethodGet, "/ipam/ip", ipamip.Handler},
+	{http.MethodPost, "/ipam/ip", ipamip.Handler},
+	{http.MethodGet, "/ipam/ip/{ipAddress}", ipamip.Handler},
+	{http.MethodPost, "/ipam/ip/{ipAddress}", ipamip.Handler},
+	{http.MethodPut, "/ipam/ip/{ipAddress}", ipamip.Handler},
+	{http.MethodDelete, "/ipam/ip/{ipAddress}", ipamip.Handler},
+	{http.MethodGet, "/ipam/ip/{ipAddress}/history", history.Handler},
+	{http.MethodGet, "/ipam/subnet", ipamsubnet.Handler},
+
+	{http.MethodGet, "/boot/ipxe", boot.Handler},
+	{http.MethodGet, "/boot/cloud-init/{nodeId}/{document}", boot.Handler},
+
+	{http.MethodGet, "/webhook", webhook.Handler},
+	{http.MethodPost, "/webhook", webhook.Handler},
+	{http.MethodGet, "/webhook/{webhookId}", webhook.Handler},
+	{http.MethodPut, "/webhook/{webhookId}", webhook.Handler},
+	{http.MethodDelete, "/webhook/{webhookId}", webhook.Handler},
+}
+
+// NewRouter returns a router serving all of the api endpoints.  newContext is
+// passed to server.NewRouter.
+func NewRouter(newContext func(context.Context) context.Context) *server.Router {
+	router := server.NewRouter(newContext)
+	for _, r := range Routes {
+		router.Handle(r.Method, r.Resource, r.Handler)
+	}
+	return router
+}
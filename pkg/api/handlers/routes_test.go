@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestRoutes(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	router := NewRouter(func(ctx context.Context) context.Context {
+		return server.NewInventoryStoreContext(ctx, inv)
+	})
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/system", "application/json", strings.NewReader(`{"Name":"testsys"}`))
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status creating system: %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/system/testsys")
+	if err != nil {
+		t.Fatalf("unable to get system: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status getting system: %d", resp.StatusCode)
+	}
+
+	system := &types.System{}
+	err = json.NewDecoder(resp.Body).Decode(system)
+	if err != nil {
+		t.Fatalf("unable to decode system: %v", err)
+	}
+	if system.Name != "testsys" {
+		t.Errorf("wrong system returned: %v", system)
+	}
+
+	resp, err = http.Get(srv.URL + "/system/testsys/history")
+	if err != nil {
+		t.Fatalf("unable to get system history: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status getting system history: %d", resp.StatusCode)
+	}
+
+	history := []*types.HistoryRecord{}
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	if err != nil {
+		t.Fatalf("unable to decode system history: %v", err)
+	}
+	if len(history) != 1 || history[0].Action != types.HistoryActionCreate || history[0].Actor != "127.0.0.1" {
+		t.Errorf("expected the creation of the system to be recorded: %v", history)
+	}
+
+	resp, err = http.Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatalf("unable to get health: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status from health check: %d", resp.StatusCode)
+	}
+}
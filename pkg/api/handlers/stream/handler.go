@@ -0,0 +1,40 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler publishes a change event for each change recorded in the streams of
+// the inventory tables.  This covers every write to the tables, including
+// those made outside the api, such as by the push tool.  Errors are returned
+// so that the batch is retried, which means events may be published more than
+// once.
+func Handler(ctx context.Context, event events.DynamoDBEvent) error {
+	publisher := server.ConnectToEventPublisherFromContext(ctx)
+	if publisher == nil {
+		return fmt.Errorf("no event publisher configured, set %s", server.EventsTopicEnv)
+	}
+
+	decoder := dynamodbclient.NewStreamDecoder(nil)
+	for _, record := range event.Records {
+		e, err := decoder.ChangeEvent(record)
+		if err != nil {
+			return fmt.Errorf("unable to decode stream record %s: %v", record.EventID, err)
+		}
+
+		if e == nil {
+			continue
+		}
+
+		err = publisher.Publish(e)
+		if err != nil {
+			return fmt.Errorf("unable to publish %s event for %s %s: %v", e.Type, e.Kind, e.ObjectID, err)
+		}
+	}
+	return nil
+}
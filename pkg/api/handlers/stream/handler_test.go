@@ -0,0 +1,77 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type testPublisher struct {
+	events []*types.ChangeEvent
+	err    error
+}
+
+func (p *testPublisher) Publish(e *types.ChangeEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	return nil
+}
+
+func testRecord(eventName string, table string, id string) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventID:        id,
+		EventName:      eventName,
+		EventSourceArn: "arn:aws:dynamodb:us-east-2:123456789012:table/" + table + "/stream/2019-06-01T00:00:00.000",
+		Change: events.DynamoDBStreamRecord{
+			NewImage: map[string]events.DynamoDBAttributeValue{
+				"id":   events.NewStringAttribute(id),
+				"Name": events.NewStringAttribute(id),
+			},
+		},
+	}
+}
+
+func TestHandler(t *testing.T) {
+	publisher := &testPublisher{}
+	ctx := server.NewEventPublisherContext(context.Background(), publisher)
+
+	event := events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		testRecord("INSERT", "inventory_systems", "tst"),
+		testRecord("INSERT", "inventory_history", "ignored"),
+		testRecord("MODIFY", "inventory_networks", "provisioning"),
+	}}
+
+	err := Handler(ctx, event)
+	if err != nil {
+		t.Fatalf("unable to handle stream event: %v", err)
+	}
+
+	if len(publisher.events) != 2 {
+		t.Fatalf("expected an event for each inventory record, got %d", len(publisher.events))
+	}
+
+	if e := publisher.events[0]; e.Type != types.ChangeEventCreated || e.Kind != types.HistoryObjectSystem || e.ObjectID != "tst" {
+		t.Errorf("wrong event published for system: %v", e)
+	}
+
+	if e := publisher.events[1]; e.Type != types.ChangeEventUpdated || e.Kind != types.HistoryObjectNetwork || e.ObjectID != "provisioning" {
+		t.Errorf("wrong event published for network: %v", e)
+	}
+
+	publisher.err = fmt.Errorf("unavailable")
+	err = Handler(ctx, event)
+	if err == nil {
+		t.Errorf("expected publish failures to be returned so the batch is retried")
+	}
+
+	err = Handler(context.Background(), event)
+	if err == nil {
+		t.Errorf("expected an error when no publisher is configured")
+	}
+}
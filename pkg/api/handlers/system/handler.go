@@ -0,0 +1,122 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// GetHandler handles GET method requests from the API gateway
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if systemID, ok := request.PathParameters["systemId"]; ok {
+		system, err := inv.System().GetSystemByID(systemID)
+		return server.GetObjectResponse(system, err)
+	}
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrBadRequest()
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, false)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	systems, next, err := inv.System().ListSystems(opts)
+	return server.ListResponse(systems, next, err)
+}
+
+// PutHandler updates the specified system record
+func PutHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	systemId, ok := request.PathParameters["systemId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Updating all systems not allowed.")
+	}
+
+	// parse request body.  Should be a system
+	updatedSystem := &inventorytypes.System{}
+	err := json.Unmarshal([]byte(request.Body), updatedSystem)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid system.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.UpdateObject(inv.WithActor(server.Actor(request)).System(), updatedSystem, systemId, server.IfMatch(request))
+}
+
+// PostHandler updates the specified system record
+func PostHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Posting not allowed here.")
+	}
+
+	// parse request body.  Should be a system
+	newSystem := &inventorytypes.System{}
+	err := json.Unmarshal([]byte(request.Body), newSystem)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid system.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.CreateObject(inv.WithActor(server.Actor(request)).System(), newSystem)
+}
+
+// PatchHandler applies a merge patch or json patch to the specified system record
+func PatchHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	systemId, ok := request.PathParameters["systemId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Patching all systems not allowed.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	get := func() (server.InventoryObject, error) {
+		return inv.System().GetSystemByID(systemId)
+	}
+	newObj := func() server.InventoryObject {
+		return &inventorytypes.System{}
+	}
+	return server.PatchObject(inv.WithActor(server.Actor(request)).System(), request, get, newObj, nil)
+}
+
+// DeleteHandler updates the specified system record
+func DeleteHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	systemId, ok := request.PathParameters["systemId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Deleting all systems not allowed.")
+	}
+	system := &inventorytypes.System{Name: systemId}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.DeleteObject(inv.WithActor(server.Actor(request)).System(), system, server.IfMatch(request))
+}
+
+// Handler handles requests for systems
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	case http.MethodPut:
+		return PutHandler(ctx, request)
+	case http.MethodPost:
+		return PostHandler(ctx, request)
+	case http.MethodDelete:
+		return DeleteHandler(ctx, request)
+	case http.MethodPatch:
+		return PatchHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
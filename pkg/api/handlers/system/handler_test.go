@@ -1,4 +1,4 @@
-package main
+package system
 
 import (
 	"context"
@@ -7,32 +7,17 @@ import (
 	"testing"
 	"time"
 
-	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
 	"github.com/PolarGeospatialCenter/inventory/pkg/api/testutils"
-	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
 	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
-	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func TestHandler(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	dbInstance, err := dynamodbtest.Run(ctx)
-	if err != nil {
-		t.Errorf("unable to start dynamodb: %v", err)
-	}
-	defer dbInstance.Stop(ctx)
-
-	db := dynamodb.New(session.New(dbInstance.Config()))
-	inv := dynamodbclient.NewDynamoDBStore(db, nil)
-
-	err = inv.InitializeTables()
-	if err != nil {
-		t.Errorf("unable to initialize tables")
-	}
+	inv := memorystore.NewMemoryStore()
 
 	system := inventorytypes.NewSystem()
 	system.Name = "testsystem"
@@ -58,7 +43,7 @@ func TestHandler(t *testing.T) {
 		t.Errorf("unable to marshal json for modified system: %v", err)
 	}
 
-	handlerCtx := lambdautils.NewAwsConfigContext(ctx, dbInstance.Config())
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
 
 	cases := testutils.TestCases{
 		testutils.TestCase{Ctx: handlerCtx,
@@ -101,6 +86,7 @@ func TestHandler(t *testing.T) {
 			TestResult: &testutils.TestResult{
 				ExpectedStatus:     http.StatusOK,
 				ExpectedBodyObject: &modifiedSystem,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 			},
 		},
 		testutils.TestCase{Ctx: handlerCtx,
@@ -111,6 +97,7 @@ func TestHandler(t *testing.T) {
 			},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: &modifiedSystem,
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
@@ -119,6 +106,7 @@ func TestHandler(t *testing.T) {
 			Request: events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet},
 			TestResult: &testutils.TestResult{
 				ExpectedBodyObject: []*inventorytypes.System{&modifiedSystem},
+				IgnoredBodyFields:  []string{"LastUpdated"},
 				ExpectedStatus:     http.StatusOK,
 			},
 		},
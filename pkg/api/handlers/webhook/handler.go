@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// redact clears the secret of webhooks returned by the api, secrets can be
+// set but not read back
+func redact(hook *inventorytypes.Webhook) *inventorytypes.Webhook {
+	if hook != nil {
+		hook.Secret = ""
+	}
+	return hook
+}
+
+// secretStore clears the secret of webhooks once they've been written, so
+// that it isn't included in the response
+type secretStore struct {
+	inventory.WebhookStore
+}
+
+func redactWritten(obj interface{}, err error) error {
+	if hook, ok := obj.(*inventorytypes.Webhook); ok && err == nil {
+		redact(hook)
+	}
+	return err
+}
+
+func (s secretStore) ObjCreate(obj interface{}) error {
+	return redactWritten(obj, s.WebhookStore.ObjCreate(obj))
+}
+
+func (s secretStore) ObjUpdate(obj interface{}) error {
+	return redactWritten(obj, s.WebhookStore.ObjUpdate(obj))
+}
+
+func (s secretStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	return redactWritten(obj, s.WebhookStore.ObjUpdateIfVersion(obj, version))
+}
+
+// GetHandler handles GET method requests from the API gateway
+func GetHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if webhookID, ok := request.PathParameters["webhookId"]; ok {
+		hook, err := inv.Webhook().GetWebhookByID(webhookID)
+		return server.GetObjectResponse(redact(hook), err)
+	}
+
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrBadRequest()
+	}
+
+	opts, err := server.ParseListOptions(request.QueryStringParameters, false)
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	hooks, next, err := inv.Webhook().ListWebhooks(opts)
+	for _, hook := range hooks {
+		redact(hook)
+	}
+	return server.ListResponse(hooks, next, err)
+}
+
+// PutHandler updates the specified webhook.  The stored secret is kept if
+// the updated webhook doesn't include one.
+func PutHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	webhookID, ok := request.PathParameters["webhookId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Updating all webhooks not allowed.")
+	}
+
+	updatedHook := &inventorytypes.Webhook{}
+	err := json.Unmarshal([]byte(request.Body), updatedHook)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid webhook.")
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	if updatedHook.Secret == "" && updatedHook.ID() == webhookID {
+		stored, err := inv.Webhook().GetWebhookByID(webhookID)
+		switch err {
+		case nil:
+			updatedHook.Secret = stored.Secret
+		case inventory.ErrObjectNotFound:
+			return lambdautils.ErrNotFound()
+		default:
+			log.Printf("unable to get webhook %s: %v", webhookID, err)
+			return lambdautils.ErrInternalServerError()
+		}
+	}
+
+	err = updatedHook.Validate()
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	return server.UpdateObject(secretStore{inv.Webhook()}, updatedHook, webhookID, server.IfMatch(request))
+}
+
+// PostHandler creates a webhook
+func PostHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if len(request.PathParameters) != 0 {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Posting not allowed here.")
+	}
+
+	newHook := &inventorytypes.Webhook{}
+	err := json.Unmarshal([]byte(request.Body), newHook)
+	if err != nil {
+		return lambdautils.ErrBadRequest("Body should contain a valid webhook.")
+	}
+
+	err = newHook.Validate()
+	if err != nil {
+		return lambdautils.ErrBadRequest(err.Error())
+	}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.CreateObject(secretStore{inv.Webhook()}, newHook)
+}
+
+// DeleteHandler deletes the specified webhook
+func DeleteHandler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	webhookID, ok := request.PathParameters["webhookId"]
+	if !ok {
+		return lambdautils.ErrStringResponse(http.StatusMethodNotAllowed, "Deleting all webhooks not allowed.")
+	}
+	hook := &inventorytypes.Webhook{Name: webhookID}
+
+	inv := server.ConnectToInventoryFromContext(ctx)
+
+	return server.DeleteObject(inv.Webhook(), hook, server.IfMatch(request))
+}
+
+// Handler handles requests for webhook subscriptions
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return GetHandler(ctx, request)
+	case http.MethodPut:
+		return PutHandler(ctx, request)
+	case http.MethodPost:
+		return PostHandler(ctx, request)
+	case http.MethodDelete:
+		return DeleteHandler(ctx, request)
+	default:
+		return lambdautils.ErrNotImplemented()
+	}
+}
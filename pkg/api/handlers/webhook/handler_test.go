@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/testutils"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	inventorytypes "github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inv := memorystore.NewMemoryStore()
+
+	hook := inventorytypes.NewWebhook()
+	hook.Name = "dhcp"
+	hook.URL = "https://dhcp.example.com/hook"
+	hook.Secret = "s3cret"
+	hook.Kinds = []string{inventorytypes.HistoryObjectIPReservation}
+	hook.Tags = inventorytypes.Tags{"dhcp"}
+	hook.Metadata = inventorytypes.Metadata{}
+	hook.LastUpdated = time.Now()
+
+	hookJson, err := json.Marshal(hook)
+	if err != nil {
+		t.Errorf("unable to marshal json for webhook: %v", err)
+	}
+
+	redacted := *hook
+	redacted.Secret = ""
+
+	modifiedHook := redacted
+	modifiedHook.URL = "https://dhcp2.example.com/hook"
+	modifiedHookJson, err := json.Marshal(&modifiedHook)
+	if err != nil {
+		t.Errorf("unable to marshal json for modified webhook: %v", err)
+	}
+
+	invalidHook := *hook
+	invalidHook.URL = "dhcp.example.com"
+	invalidHookJson, _ := json.Marshal(&invalidHook)
+
+	handlerCtx := server.NewInventoryStoreContext(ctx, inv)
+
+	cases := testutils.TestCases{
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Create invalid webhook",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Body:       string(invalidHookJson),
+			},
+			TestResult: testutils.ExpectError(http.StatusBadRequest, "webhook url must be an absolute http or https url"),
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Create test webhook",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Body:       string(hookJson),
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedStatus:     http.StatusCreated,
+				ExpectedBodyObject: &redacted,
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Get test webhook",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				PathParameters: map[string]string{"webhookId": "dhcp"},
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedStatus:     http.StatusOK,
+				ExpectedBodyObject: &redacted,
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Update test webhook without secret",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPut,
+				PathParameters: map[string]string{"webhookId": "dhcp"},
+				Body:           string(modifiedHookJson),
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedStatus:     http.StatusOK,
+				ExpectedBodyObject: &modifiedHook,
+				IgnoredBodyFields:  []string{"LastUpdated"},
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name:    "Get all webhooks",
+			Request: events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet},
+			TestResult: &testutils.TestResult{
+				ExpectedStatus:     http.StatusOK,
+				ExpectedBodyObject: []*inventorytypes.Webhook{&modifiedHook},
+				IgnoredBodyFields:  []string{"LastUpdated"},
+			},
+		},
+	}
+	cases.RunTests(t, Handler)
+
+	stored, err := inv.Webhook().GetWebhookByID("dhcp")
+	if err != nil {
+		t.Fatalf("unable to get stored webhook: %v", err)
+	}
+
+	if stored.Secret != "s3cret" || stored.URL != modifiedHook.URL {
+		t.Errorf("expected the update to keep the stored secret, got %s %s", stored.URL, stored.Secret)
+	}
+
+	cases = testutils.TestCases{
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Delete test webhook",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodDelete,
+				PathParameters: map[string]string{"webhookId": "dhcp"},
+			},
+			TestResult: &testutils.TestResult{
+				ExpectedStatus:     http.StatusOK,
+				ExpectedBodyObject: "",
+			},
+		},
+		testutils.TestCase{Ctx: handlerCtx,
+			Name: "Get deleted test webhook",
+			Request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				PathParameters: map[string]string{"webhookId": "dhcp"},
+			},
+			TestResult: testutils.ExpectError(http.StatusNotFound, "Object not found"),
+		},
+	}
+	cases.RunTests(t, Handler)
+}
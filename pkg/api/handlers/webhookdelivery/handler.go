@@ -0,0 +1,49 @@
+package webhookdelivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/webhook"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// sender delivers events to webhooks
+var sender = webhook.NewSender(nil)
+
+// Handler delivers the change events published to the events topic to the
+// webhooks subscribed to them.  Deliveries are retried by the sender, and if
+// an event still couldn't be delivered to every webhook an error is returned,
+// so that lambda retries the invocation and then sends it to the dead letter
+// queue.  Webhooks that did receive an event may be sent it again, receivers
+// can use the event's kind, object id and timestamp to ignore duplicates.
+// Invalid events are logged and ignored, since retrying won't help.
+func Handler(ctx context.Context, event events.SNSEvent) error {
+	inv := server.ConnectToInventoryFromContext(ctx)
+	dispatcher := webhook.NewDispatcher(inv, sender)
+
+	failed := 0
+	for _, record := range event.Records {
+		e := &types.ChangeEvent{}
+		err := json.Unmarshal([]byte(record.SNS.Message), e)
+		if err != nil {
+			log.Printf("ignoring invalid change event %s: %v", record.SNS.MessageID, err)
+			continue
+		}
+
+		err = dispatcher.Dispatch(e)
+		if err != nil {
+			log.Printf("unable to deliver %s event for %s %s: %v", e.Type, e.Kind, e.ObjectID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to deliver %d of %d change events", failed, len(event.Records))
+	}
+	return nil
+}
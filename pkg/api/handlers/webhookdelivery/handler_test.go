@@ -0,0 +1,91 @@
+package webhookdelivery
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/api/server"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/webhook"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	sender.Backoff = time.Millisecond
+
+	received := make(chan *types.ChangeEvent, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !webhook.Verify("s3cret", body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		e := &types.ChangeEvent{}
+		json.Unmarshal(body, e)
+		received <- e
+	}))
+	defer srv.Close()
+
+	inv := memorystore.NewMemoryStore()
+	err := inv.Webhook().Create(&types.Webhook{Name: "monitoring", URL: srv.URL, Secret: "s3cret", Kinds: []string{types.HistoryObjectSystem}})
+	if err != nil {
+		t.Fatalf("unable to create webhook: %v", err)
+	}
+
+	message := func(e *types.ChangeEvent) events.SNSEventRecord {
+		doc, _ := json.Marshal(e)
+		return events.SNSEventRecord{SNS: events.SNSEntity{Message: string(doc)}}
+	}
+
+	systemEvent, _ := types.NewChangeEvent(types.ChangeEventCreated, types.HistoryObjectSystem, "tst", nil, &types.System{Name: "tst"})
+	networkEvent, _ := types.NewChangeEvent(types.ChangeEventCreated, types.HistoryObjectNetwork, "provisioning", nil, &types.Network{Name: "provisioning"})
+
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+	err = Handler(ctx, events.SNSEvent{Records: []events.SNSEventRecord{
+		message(systemEvent),
+		message(networkEvent),
+		{SNS: events.SNSEntity{Message: "not json"}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to handle event: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected only the system event to be delivered, got %d", len(received))
+	}
+
+	if e := <-received; e.Kind != types.HistoryObjectSystem || e.ObjectID != "tst" {
+		t.Errorf("wrong event delivered: %v", e)
+	}
+}
+
+func TestHandlerDeliveryFailure(t *testing.T) {
+	sender.Backoff = time.Millisecond
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	inv := memorystore.NewMemoryStore()
+	err := inv.Webhook().Create(&types.Webhook{Name: "monitoring", URL: srv.URL, Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("unable to create webhook: %v", err)
+	}
+
+	e, _ := types.NewChangeEvent(types.ChangeEventCreated, types.HistoryObjectSystem, "tst", nil, &types.System{Name: "tst"})
+	doc, _ := json.Marshal(e)
+
+	ctx := server.NewInventoryStoreContext(context.Background(), inv)
+	err = Handler(ctx, events.SNSEvent{Records: []events.SNSEventRecord{{SNS: events.SNSEntity{Message: string(doc)}}}})
+	if err == nil {
+		t.Errorf("expected an error when the event couldn't be delivered")
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// ETagHeader identifies the version of the object in a response
+	ETagHeader = "ETag"
+
+	// IfMatchHeader makes an update or delete conditional on the object still
+	// being at the version identified by the ETag
+	IfMatchHeader = "If-Match"
+)
+
+// ETag returns the entity tag for an object at version, which is the
+// object's LastUpdated timestamp
+func ETag(version time.Time) string {
+	if version.IsZero() {
+		return `"0"`
+	}
+	return fmt.Sprintf(`"%d"`, version.UnixNano())
+}
+
+// ParseETag returns the version identified by an entity tag returned by ETag
+func ParseETag(tag string) (time.Time, error) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return time.Time{}, fmt.Errorf("invalid entity tag: %s", tag)
+	}
+
+	nanoseconds, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid entity tag: %s", tag)
+	}
+
+	if nanoseconds == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(0, nanoseconds), nil
+}
+
+// IfMatch returns the value of the If-Match header.  Api gateway passes
+// headers through with the case used by the client, so the lookup is case
+// insensitive.
+func IfMatch(request events.APIGatewayProxyRequest) string {
+	return header(request, IfMatchHeader)
+}
+
+func header(request events.APIGatewayProxyRequest, name string) string {
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// versioned is implemented by objects whose LastUpdated timestamp identifies
+// their revision
+type versioned interface {
+	Version() time.Time
+}
+
+// versionHeaders returns the ETag header for obj, if it's versioned
+func versionHeaders(obj interface{}) map[string]string {
+	headers := map[string]string{}
+	if v, ok := obj.(versioned); ok {
+		headers[ETagHeader] = ETag(v.Version())
+	}
+	return headers
+}
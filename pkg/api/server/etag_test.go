@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestETag(t *testing.T) {
+	for _, version := range []time.Time{time.Now(), time.Date(2019, 6, 1, 10, 0, 0, 123, time.FixedZone("CDT", -5*3600)), {}} {
+		parsed, err := ParseETag(ETag(version))
+		if err != nil {
+			t.Errorf("unable to parse etag for %v: %v", version, err)
+			continue
+		}
+
+		if !parsed.Equal(version) {
+			t.Errorf("parsed version %v doesn't match %v", parsed, version)
+		}
+	}
+
+	for _, tag := range []string{"", `"`, "1234", `W/"1234"`, `"abc"`} {
+		_, err := ParseETag(tag)
+		if err == nil {
+			t.Errorf("expected error parsing invalid etag %s", tag)
+		}
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	request := events.APIGatewayProxyRequest{Headers: map[string]string{"if-match": `"1234"`}}
+	if value := IfMatch(request); value != `"1234"` {
+		t.Errorf("expected header to be found regardless of case, got '%s'", value)
+	}
+
+	if value := IfMatch(events.APIGatewayProxyRequest{}); value != "" {
+		t.Errorf("expected empty value without header, got '%s'", value)
+	}
+}
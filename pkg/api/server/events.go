@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+)
+
+// EventsTopicEnv names the environment variable holding the ARN of the SNS
+// topic change events are published to.  Events aren't published if it isn't
+// set.
+const EventsTopicEnv = "INVENTORY_EVENTS_TOPIC_ARN"
+
+// EventPublisher publishes change events
+type EventPublisher interface {
+	Publish(*types.ChangeEvent) error
+}
+
+// SNSPublisher publishes change events to an SNS topic as json.  The event
+// type and object kind are also set as message attributes, so that
+// subscriptions can filter on them.
+type SNSPublisher struct {
+	client   snsiface.SNSAPI
+	topicArn string
+}
+
+// NewSNSPublisher creates an SNSPublisher for the topic
+func NewSNSPublisher(client snsiface.SNSAPI, topicArn string) *SNSPublisher {
+	return &SNSPublisher{client: client, topicArn: topicArn}
+}
+
+func (p *SNSPublisher) Publish(e *types.ChangeEvent) error {
+	message, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.Publish(&sns.PublishInput{
+		TopicArn: aws.String(p.topicArn),
+		Message:  aws.String(string(message)),
+		MessageAttributes: map[string]*sns.MessageAttributeValue{
+			"type": {DataType: aws.String("String"), StringValue: aws.String(e.Type)},
+			"kind": {DataType: aws.String("String"), StringValue: aws.String(e.Kind)},
+		},
+	})
+	return err
+}
+
+type eventPublisherContextKey struct{}
+
+// NewEventPublisherContext attaches an event publisher to the context.
+// Handlers called with this context will publish events with the attached
+// publisher instead of connecting to SNS.
+func NewEventPublisherContext(parentCtx context.Context, publisher EventPublisher) context.Context {
+	return context.WithValue(parentCtx, eventPublisherContextKey{}, publisher)
+}
+
+// ConnectToEventPublisherFromContext returns the event publisher attached to
+// the context, or creates an SNSPublisher for the topic configured in the
+// environment.  It returns nil if neither is available.
+func ConnectToEventPublisherFromContext(ctx context.Context) EventPublisher {
+	if publisher, ok := ctx.Value(eventPublisherContextKey{}).(EventPublisher); ok {
+		return publisher
+	}
+
+	topicArn := os.Getenv(EventsTopicEnv)
+	if topicArn == "" {
+		return nil
+	}
+	return NewSNSPublisher(ConnectToSNSFromContext(ctx), topicArn)
+}
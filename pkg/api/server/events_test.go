@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+)
+
+// testPublisher keeps the events it's asked to publish
+type testPublisher struct {
+	events []*types.ChangeEvent
+}
+
+func (p *testPublisher) Publish(e *types.ChangeEvent) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+type testSNSClient struct {
+	snsiface.SNSAPI
+	inputs []*sns.PublishInput
+}
+
+func (c *testSNSClient) Publish(in *sns.PublishInput) (*sns.PublishOutput, error) {
+	c.inputs = append(c.inputs, in)
+	return &sns.PublishOutput{}, nil
+}
+
+func TestSNSPublisher(t *testing.T) {
+	client := &testSNSClient{}
+	publisher := NewSNSPublisher(client, "arn:aws:sns:us-east-2:123456789012:inventory_node_events")
+
+	e, err := types.NewChangeEvent(types.ChangeEventDeleted, types.HistoryObjectSystem, "test", &types.System{Name: "test"}, nil)
+	if err != nil {
+		t.Fatalf("unable to create event: %v", err)
+	}
+
+	err = publisher.Publish(e)
+	if err != nil {
+		t.Fatalf("unable to publish event: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("expected one message to be published, got %d", len(client.inputs))
+	}
+	in := client.inputs[0]
+
+	if aws.StringValue(in.TopicArn) != "arn:aws:sns:us-east-2:123456789012:inventory_node_events" {
+		t.Errorf("published to the wrong topic: %s", aws.StringValue(in.TopicArn))
+	}
+
+	if aws.StringValue(in.MessageAttributes["type"].StringValue) != types.ChangeEventDeleted || aws.StringValue(in.MessageAttributes["kind"].StringValue) != types.HistoryObjectSystem {
+		t.Errorf("wrong message attributes: %v", in.MessageAttributes)
+	}
+
+	published := &types.ChangeEvent{}
+	err = json.Unmarshal([]byte(aws.StringValue(in.Message)), published)
+	if err != nil {
+		t.Fatalf("unable to unmarshal message: %v", err)
+	}
+
+	if published.Type != types.ChangeEventDeleted || published.ObjectID != "test" || len(published.Before) == 0 || len(published.After) != 0 {
+		t.Errorf("wrong event published: %v", published)
+	}
+}
+
+func TestConnectToEventPublisherFromContext(t *testing.T) {
+	os.Unsetenv(EventsTopicEnv)
+	if publisher := ConnectToEventPublisherFromContext(context.Background()); publisher != nil {
+		t.Errorf("expected no publisher without a configured topic, got %v", publisher)
+	}
+
+	attached := &testPublisher{}
+	if publisher := ConnectToEventPublisherFromContext(NewEventPublisherContext(context.Background(), attached)); publisher != attached {
+		t.Errorf("expected the attached publisher, got %v", publisher)
+	}
+
+	os.Setenv(EventsTopicEnv, "arn:aws:sns:us-east-2:123456789012:inventory_node_events")
+	defer os.Unsetenv(EventsTopicEnv)
+	if publisher, ok := ConnectToEventPublisherFromContext(context.Background()).(*SNSPublisher); !ok || publisher.topicArn != os.Getenv(EventsTopicEnv) {
+		t.Errorf("expected an sns publisher for the configured topic, got %v", publisher)
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Actor identifies the caller that made the request from the identity api
+// gateway attached to the request context.  Requests signed with IAM
+// credentials are identified by the caller's ARN, other requests by the most
+// specific identity available, falling back to the source ip.
+func Actor(request events.APIGatewayProxyRequest) string {
+	identity := request.RequestContext.Identity
+	for _, actor := range []string{identity.UserArn, identity.User, identity.Caller, identity.CognitoIdentityID, identity.SourceIP} {
+		if actor != "" {
+			return actor
+		}
+	}
+	return "unknown"
+}
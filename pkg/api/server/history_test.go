@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/go-test/deep"
+)
+
+func TestActor(t *testing.T) {
+	cases := map[string]events.APIGatewayRequestIdentity{
+		"arn:aws:iam::123456789012:user/admin": {UserArn: "arn:aws:iam::123456789012:user/admin", User: "AIDAEXAMPLE", SourceIP: "10.0.0.1"},
+		"AIDAEXAMPLE":                          {User: "AIDAEXAMPLE", SourceIP: "10.0.0.1"},
+		"10.0.0.1":                             {SourceIP: "10.0.0.1"},
+		"unknown":                              {},
+	}
+
+	for expected, identity := range cases {
+		request := events.APIGatewayProxyRequest{RequestContext: events.APIGatewayProxyRequestContext{Identity: identity}}
+		if actor := Actor(request); actor != expected {
+			t.Errorf("expected actor %s, got %s", expected, actor)
+		}
+	}
+}
+
+func TestObjectHistory(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	request := events.APIGatewayProxyRequest{RequestContext: events.APIGatewayProxyRequestContext{
+		Identity: events.APIGatewayRequestIdentity{UserArn: "arn:aws:iam::123456789012:user/admin"},
+	}}
+	recorder := inv.WithActor(Actor(request)).System()
+
+	response, _ := CreateObject(recorder, &types.System{Name: "test", Roles: []string{"worker"}})
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("unable to create system: %d %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = UpdateObject(recorder, &types.System{Name: "test", Roles: []string{"master"}}, "test", "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("unable to update system: %d %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = UpdateObject(recorder, &types.System{Name: "test"}, "test", `"1"`)
+	if response.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected stale update to fail: %d %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = DeleteObject(recorder, &types.System{Name: "test"}, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("unable to delete system: %d %s", response.StatusCode, response.Body)
+	}
+
+	records, _, err := inv.History().GetHistory(types.HistoryObjectSystem, "test", inventory.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to get history: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected a record of each successful change, got %d", len(records))
+	}
+
+	roles := func(doc json.RawMessage) []string {
+		if len(doc) == 0 {
+			return nil
+		}
+		system := &types.System{}
+		err := json.Unmarshal(doc, system)
+		if err != nil {
+			t.Errorf("unable to unmarshal system: %v", err)
+		}
+		return system.Roles
+	}
+
+	expected := []struct {
+		action string
+		before []string
+		after  []string
+	}{
+		{types.HistoryActionCreate, nil, []string{"worker"}},
+		{types.HistoryActionUpdate, []string{"worker"}, []string{"master"}},
+		{types.HistoryActionDelete, []string{"master"}, nil},
+	}
+
+	for i, e := range expected {
+		r := records[i]
+		if r.Action != e.action || r.Actor != "arn:aws:iam::123456789012:user/admin" || r.ObjectID != "test" {
+			t.Errorf("record %d is wrong: %v", i, r)
+		}
+
+		if diff := deep.Equal(roles(r.Before), e.before); len(diff) > 0 {
+			t.Errorf("record %d has the wrong previous roles: %v", i, diff)
+		}
+
+		if diff := deep.Equal(roles(r.After), e.after); len(diff) > 0 {
+			t.Errorf("record %d has the wrong new roles: %v", i, diff)
+		}
+	}
+}
+
+func TestObjectHistoryWithoutActor(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+
+	response, _ := CreateObject(inv.System(), &types.System{Name: "test"})
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("unable to create system: %d %s", response.StatusCode, response.Body)
+	}
+
+	records, _, err := inv.History().GetHistory(types.HistoryObjectSystem, "test", inventory.ListOptions{})
+	if err != nil || len(records) != 0 {
+		t.Errorf("expected no history without an actor, got %v: %v", records, err)
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LambdaHandler is the signature shared by all of the api gateway handlers
+type LambdaHandler func(context.Context, events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error)
+
+type route struct {
+	method   string
+	resource string
+	segments []string
+	handler  LambdaHandler
+}
+
+// match returns the path parameters if the escaped path matches the route's
+// resource.  Parameters are unescaped, so they may contain slashes.
+func (r *route) match(path string) (map[string]string, bool) {
+	segments := splitPath(path)
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = value
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+// Router serves api gateway handlers over plain http.  Resources use the same
+// syntax as the api gateway, ie: /node/{nodeId}.
+type Router struct {
+	routes     []*route
+	newContext func(context.Context) context.Context
+}
+
+// NewRouter creates a Router.  If newContext is not nil, it's called to
+// prepare the context for each request, for example to attach an inventory
+// store.
+func NewRouter(newContext func(context.Context) context.Context) *Router {
+	return &Router{newContext: newContext}
+}
+
+// Handle registers a handler for the method and resource
+func (r *Router) Handle(method string, resource string, handler LambdaHandler) {
+	r.routes = append(r.routes, &route{
+		method:   strings.ToUpper(method),
+		resource: resource,
+		segments: splitPath(resource),
+		handler:  handler,
+	})
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	pathMatched := false
+	for _, rt := range r.routes {
+		params, ok := rt.match(req.URL.EscapedPath())
+		if !ok {
+			continue
+		}
+		pathMatched = true
+
+		if rt.method != req.Method {
+			continue
+		}
+
+		r.serveRoute(w, req, rt, params)
+		return
+	}
+
+	if pathMatched {
+		response, err := lambdautils.ErrStringResponse(http.StatusMethodNotAllowed)
+		writeResponse(w, response, err)
+		return
+	}
+	response, err := lambdautils.ErrNotFound()
+	writeResponse(w, response, err)
+}
+
+func (r *Router) serveRoute(w http.ResponseWriter, req *http.Request, rt *route, params map[string]string) {
+	proxyRequest, err := NewAPIGatewayProxyRequest(req, rt.resource, params)
+	if err != nil {
+		response, err := lambdautils.ErrBadRequest(err.Error())
+		writeResponse(w, response, err)
+		return
+	}
+
+	ctx := req.Context()
+	if r.newContext != nil {
+		ctx = r.newContext(ctx)
+	}
+
+	response, err := rt.handler(ctx, proxyRequest)
+	if err != nil {
+		log.Printf("error handling %s %s: %v", req.Method, req.URL.Path, err)
+		response, err := lambdautils.ErrInternalServerError()
+		writeResponse(w, response, err)
+		return
+	}
+	writeResponse(w, response, nil)
+}
+
+// NewAPIGatewayProxyRequest builds the request api gateway would send to a
+// lambda for the http request
+func NewAPIGatewayProxyRequest(req *http.Request, resource string, pathParameters map[string]string) (events.APIGatewayProxyRequest, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for key, values := range req.Header {
+		headers[key] = values[0]
+	}
+
+	query := req.URL.Query()
+	queryParameters := make(map[string]string, len(query))
+	for key, values := range query {
+		queryParameters[key] = values[0]
+	}
+
+	sourceIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		sourceIP = req.RemoteAddr
+	}
+
+	return events.APIGatewayProxyRequest{
+		Resource:                        resource,
+		Path:                            req.URL.Path,
+		HTTPMethod:                      req.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               map[string][]string(req.Header),
+		QueryStringParameters:           queryParameters,
+		MultiValueQueryStringParameters: map[string][]string(query),
+		PathParameters:                  pathParameters,
+		Body:                            string(body),
+		RequestContext: events.APIGatewayProxyRequestContext{
+			ResourcePath: resource,
+			HTTPMethod:   req.Method,
+			Identity:     events.APIGatewayRequestIdentity{SourceIP: sourceIP},
+		},
+	}, nil
+}
+
+func writeResponse(w http.ResponseWriter, response *events.APIGatewayProxyResponse, err error) {
+	if err != nil || response == nil {
+		log.Printf("unable to build response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	for key, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	// api gateway defaults to json when the handler doesn't set a content type
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		body, err = base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			log.Printf("unable to decode response body: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/go-test/deep"
+)
+
+type testContextKey struct{}
+
+func TestRouter(t *testing.T) {
+	var received events.APIGatewayProxyRequest
+	var receivedCtx context.Context
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+		received = request
+		receivedCtx = ctx
+		return lambdautils.SimpleOKResponse(map[string]string{"status": "ok"})
+	}
+
+	router := NewRouter(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, testContextKey{}, "value")
+	})
+	router.Handle("get", "/ipam/ip/{ipAddress}", handler)
+	router.Handle(http.MethodPut, "/ipam/ip/{ipAddress}", handler)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/ipam/ip/10.0.0.1%2F24?mac=00:01:02:03:04:05&mac=other", strings.NewReader(`{"foo":"bar"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `{"status":"ok"}` {
+		t.Errorf("unexpected body: %s", string(body))
+	}
+
+	if received.HTTPMethod != http.MethodPut || received.Resource != "/ipam/ip/{ipAddress}" || received.Body != `{"foo":"bar"}` {
+		t.Errorf("request not translated correctly: %v", received)
+	}
+
+	if diff := deep.Equal(received.PathParameters, map[string]string{"ipAddress": "10.0.0.1/24"}); diff != nil {
+		t.Errorf("wrong path parameters: %v", diff)
+	}
+
+	if received.QueryStringParameters["mac"] != "00:01:02:03:04:05" || len(received.MultiValueQueryStringParameters["mac"]) != 2 {
+		t.Errorf("wrong query parameters: %v %v", received.QueryStringParameters, received.MultiValueQueryStringParameters)
+	}
+
+	if receivedCtx.Value(testContextKey{}) != "value" {
+		t.Errorf("context not passed to handler")
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+		return lambdautils.SimpleOKResponse(nil)
+	}
+
+	router := NewRouter(nil)
+	router.Handle(http.MethodGet, "/node/{nodeId}", handler)
+
+	cases := []struct {
+		Method string
+		Path   string
+		Status int
+	}{
+		{http.MethodGet, "/node/foo", http.StatusOK},
+		{http.MethodGet, "/node", http.StatusNotFound},
+		{http.MethodGet, "/node/foo/bar", http.StatusNotFound},
+		{http.MethodDelete, "/node/foo", http.StatusMethodNotAllowed},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(c.Method, c.Path, nil))
+		if w.Code != c.Status {
+			t.Errorf("%s %s: got status %d, expected %d", c.Method, c.Path, w.Code, c.Status)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// NextCursorHeader holds the cursor for the next page of a list response.  It
+// isn't set on the last page.
+const NextCursorHeader = "X-Next-Cursor"
+
+// Page sizes for list responses.  Lists are always paged so that a response
+// stays within the lambda response size limit.
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// ParseListOptions reads the limit and next query parameters.  If
+// filterNodes is true the system, role, environment, tag and rack node
+// filters are accepted as well.  Any other parameter is an error.  The limit
+// defaults to DefaultListLimit and is capped at MaxListLimit.
+func ParseListOptions(query map[string]string, filterNodes bool) (inventory.ListOptions, error) {
+	opts := inventory.ListOptions{Limit: DefaultListLimit}
+	for key, value := range query {
+		switch {
+		case key == "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return opts, fmt.Errorf("limit must be a positive integer")
+			}
+			opts.Limit = limit
+			if limit > MaxListLimit {
+				opts.Limit = MaxListLimit
+			}
+		case key == "next":
+			opts.Next = value
+		case filterNodes && key == "system":
+			opts.Filter.System = value
+		case filterNodes && key == "role":
+			opts.Filter.Role = value
+		case filterNodes && key == "environment":
+			opts.Filter.Environment = value
+		case filterNodes && key == "tag":
+			opts.Filter.Tag = value
+		case filterNodes && key == "rack":
+			opts.Filter.Rack = value
+		default:
+			return opts, fmt.Errorf("unsupported query parameter: %s", key)
+		}
+	}
+	return opts, nil
+}
+
+// ListResponse returns a page of objects, with the cursor for the next page in
+// the NextCursorHeader header
+func ListResponse(objs interface{}, next string, err error) (*events.APIGatewayProxyResponse, error) {
+	switch err {
+	case inventory.ErrInvalidCursor:
+		return lambdautils.ErrBadRequest(err.Error())
+	case nil:
+		headers := map[string]string{}
+		if next != "" {
+			headers[NextCursorHeader] = next
+		}
+		return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusOK, headers, objs)
+	default:
+		log.Printf("Returning internal server error.  Actual error was: %v", err)
+		return lambdautils.ErrInternalServerError()
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestParseListOptionsLimit(t *testing.T) {
+	cases := []struct {
+		Query    map[string]string
+		Expected int
+	}{
+		{map[string]string{}, DefaultListLimit},
+		{map[string]string{"limit": "5"}, 5},
+		{map[string]string{"limit": "100000"}, MaxListLimit},
+	}
+
+	for _, c := range cases {
+		opts, err := ParseListOptions(c.Query, false)
+		if err != nil {
+			t.Errorf("unable to parse %v: %v", c.Query, err)
+			continue
+		}
+
+		if opts.Limit != c.Expected {
+			t.Errorf("expected limit %d for %v, got %d", c.Expected, c.Query, opts.Limit)
+		}
+	}
+
+	_, err := ParseListOptions(map[string]string{"limit": "0"}, false)
+	if err == nil {
+		t.Errorf("expected a limit of 0 to be rejected")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/dynamodbclient"
 	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
 	"github.com/aws/aws-lambda-go/events"
@@ -15,20 +16,40 @@ import (
 
 // InventoryDatabase defines the interface we're expecting for the inventory
 type InventoryDatabase interface {
-	ObjExists(interface{}) (bool, error)
-	ObjUpdate(interface{}) error
-	ObjDelete(interface{}) error
-	ObjCreate(interface{}) error
+	inventory.VersionedObjectStore
 }
 
 type InventoryObject interface {
 	ID() string
 	Timestamp() int64
 	SetTimestamp(time.Time)
+	Version() time.Time
 }
 
-// ConnectToInventoryFromContext creates a dynamodb inventory client from credentials attached to the context
-func ConnectToInventoryFromContext(ctx context.Context) *dynamodbclient.DynamoDBStore {
+type contextKey struct{}
+
+var inventoryStoreContextKey = &contextKey{}
+
+// NewInventoryStoreContext attaches an inventory store to the context.  Handlers
+// called with this context will use the attached store instead of connecting
+// to dynamodb.
+func NewInventoryStoreContext(parentCtx context.Context, store inventory.Store) context.Context {
+	return context.WithValue(parentCtx, inventoryStoreContextKey, store)
+}
+
+// InventoryStoreFromContext returns the inventory store attached to the context, if any
+func InventoryStoreFromContext(ctx context.Context) (inventory.Store, bool) {
+	store, ok := ctx.Value(inventoryStoreContextKey).(inventory.Store)
+	return store, ok
+}
+
+// ConnectToInventoryFromContext returns the inventory store attached to the
+// context, or creates a dynamodb inventory client from credentials attached to
+// the context if no store is attached
+func ConnectToInventoryFromContext(ctx context.Context) inventory.Store {
+	if store, ok := InventoryStoreFromContext(ctx); ok {
+		return store
+	}
 	db := dynamodb.New(lambdautils.AwsContextConfigProvider(ctx))
 	return dynamodbclient.NewDynamoDBStore(db, nil)
 }
@@ -38,8 +59,10 @@ func ConnectToSNSFromContext(ctx context.Context) *sns.SNS {
 	return sns.New(lambdautils.AwsContextConfigProvider(ctx))
 }
 
-// UpdateObject updates an object
-func UpdateObject(inv InventoryDatabase, obj InventoryObject, id string) (*events.APIGatewayProxyResponse, error) {
+// UpdateObject updates an object.  If ifMatch is set to an ETag, the object is
+// only updated if it's still at the version identified by the ETag.  The
+// object's LastUpdated timestamp is always set, so that its version changes.
+func UpdateObject(inv InventoryDatabase, obj InventoryObject, id string, ifMatch string) (*events.APIGatewayProxyResponse, error) {
 	if obj.ID() != id {
 		return lambdautils.ErrBadRequest("ID of updated object must match the id specified in the request.")
 	}
@@ -55,19 +78,49 @@ func UpdateObject(inv InventoryDatabase, obj InventoryObject, id string) (*event
 		return lambdautils.ErrResponse(http.StatusInternalServerError, nil)
 	}
 
-	if obj.Timestamp() == (&time.Time{}).Unix() {
-		obj.SetTimestamp(time.Now())
+	obj.SetTimestamp(time.Now())
+
+	if ifMatch == "" || ifMatch == "*" {
+		err = inv.ObjUpdate(obj)
+	} else {
+		err = writeIfMatch(inv.ObjUpdateIfVersion, obj, ifMatch)
 	}
 
-	err = inv.ObjUpdate(obj)
-	if err == nil {
-		return lambdautils.SimpleOKResponse(obj)
+	switch err {
+	case nil:
+		return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusOK, versionHeaders(obj), obj)
+	case inventory.ErrObjectNotFound:
+		return lambdautils.ErrNotFound()
+	case inventory.ErrVersionMismatch:
+		return errPreconditionFailed()
+	case inventory.ErrTooManyChanges:
+		return errTooManyChanges()
 	}
 
 	log.Printf("unable to update object '%v': %v", obj, err)
 	return lambdautils.ErrInternalServerError()
 }
 
+// writeIfMatch calls write with the version identified by ifMatch.  Entity
+// tags that weren't returned by the api can't match any version.
+func writeIfMatch(write func(interface{}, time.Time) error, obj interface{}, ifMatch string) error {
+	version, err := ParseETag(ifMatch)
+	if err != nil {
+		return inventory.ErrVersionMismatch
+	}
+	return write(obj, version)
+}
+
+func errPreconditionFailed() (*events.APIGatewayProxyResponse, error) {
+	return lambdautils.ErrStringResponse(http.StatusPreconditionFailed, "The object has been modified since it was read, fetch it again and retry.")
+}
+
+// errTooManyChanges is the response to writes that would change more records
+// than can be changed atomically.  Nothing was written.
+func errTooManyChanges() (*events.APIGatewayProxyResponse, error) {
+	return lambdautils.ErrStringResponse(http.StatusUnprocessableEntity, "The change affects too many records to be made at once, split it into smaller changes.")
+}
+
 // CreateObject creates an object
 func CreateObject(inv InventoryDatabase, obj InventoryObject) (*events.APIGatewayProxyResponse, error) {
 	exists, err := inv.ObjExists(obj)
@@ -86,16 +139,20 @@ func CreateObject(inv InventoryDatabase, obj InventoryObject) (*events.APIGatewa
 	}
 
 	err = inv.ObjCreate(obj)
-	if err == nil {
-		return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusCreated, map[string]string{}, obj)
+	switch err {
+	case nil:
+		return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusCreated, versionHeaders(obj), obj)
+	case inventory.ErrTooManyChanges:
+		return errTooManyChanges()
 	}
 
 	log.Printf("unable to create object '%v': %v", obj, err)
 	return lambdautils.ErrInternalServerError()
 }
 
-// DeleteObject deletes an object
-func DeleteObject(inv InventoryDatabase, obj InventoryObject) (*events.APIGatewayProxyResponse, error) {
+// DeleteObject deletes an object.  If ifMatch is set to an ETag, the object is
+// only deleted if it's still at the version identified by the ETag.
+func DeleteObject(inv InventoryDatabase, obj InventoryObject, ifMatch string) (*events.APIGatewayProxyResponse, error) {
 	exists, err := inv.ObjExists(obj)
 	switch {
 	case exists:
@@ -107,22 +164,35 @@ func DeleteObject(inv InventoryDatabase, obj InventoryObject) (*events.APIGatewa
 		return lambdautils.ErrInternalServerError()
 	}
 
-	err = inv.ObjDelete(obj)
-	if err == nil {
+	if ifMatch == "" || ifMatch == "*" {
+		err = inv.ObjDelete(obj)
+	} else {
+		err = writeIfMatch(inv.ObjDeleteIfVersion, obj, ifMatch)
+	}
+
+	switch err {
+	case nil:
 		return lambdautils.SimpleOKResponse("")
+	case inventory.ErrObjectNotFound:
+		return lambdautils.ErrNotFound("Objects must exist before you can delete them.")
+	case inventory.ErrVersionMismatch:
+		return errPreconditionFailed()
+	case inventory.ErrTooManyChanges:
+		return errTooManyChanges()
 	}
 
 	log.Printf("unable to delete object '%v': %v", obj, err)
 	return lambdautils.ErrInternalServerError()
 }
 
-// GetObjectResponse looks up the appropriate response for object
+// GetObjectResponse looks up the appropriate response for object.  Responses
+// with a single versioned object carry its ETag.
 func GetObjectResponse(obj interface{}, err error) (*events.APIGatewayProxyResponse, error) {
 	switch err {
-	case dynamodbclient.ErrObjectNotFound:
+	case inventory.ErrObjectNotFound:
 		return lambdautils.ErrNotFound(err.Error())
 	case nil:
-		return lambdautils.SimpleOKResponse(obj)
+		return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusOK, versionHeaders(obj), obj)
 	default:
 		log.Printf("Returning internal server error.  Actual error was: %v", err)
 		return lambdautils.ErrInternalServerError()
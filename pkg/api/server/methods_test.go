@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// tooManyChangesStore rejects every write as changing too many records
+type tooManyChangesStore struct {
+	inventory.SystemStore
+}
+
+func (s *tooManyChangesStore) ObjCreate(interface{}) error {
+	return inventory.ErrTooManyChanges
+}
+
+func (s *tooManyChangesStore) ObjUpdate(interface{}) error {
+	return inventory.ErrTooManyChanges
+}
+
+func (s *tooManyChangesStore) ObjUpdateIfVersion(interface{}, time.Time) error {
+	return inventory.ErrTooManyChanges
+}
+
+func (s *tooManyChangesStore) ObjDelete(interface{}) error {
+	return inventory.ErrTooManyChanges
+}
+
+func TestTooManyChanges(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	err := inv.System().Create(&types.System{Name: "existing"})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+	store := &tooManyChangesStore{inv.System()}
+
+	response, _ := CreateObject(store, &types.System{Name: "test"})
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected create to be rejected: %d %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = UpdateObject(store, &types.System{Name: "existing"}, "existing", "")
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected update to be rejected: %d %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = DeleteObject(store, &types.System{Name: "existing"}, "")
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected delete to be rejected: %d %s", response.StatusCode, response.Body)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/jsonpatch"
+	"github.com/PolarGeospatialCenter/inventory/pkg/lambdautils"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// MergePatchContentType identifies an RFC 7396 merge patch
+	MergePatchContentType = "application/merge-patch+json"
+
+	// JSONPatchContentType identifies an RFC 6902 json patch
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// maxPatchAttempts limits how often a patch is applied again after the object
+// was modified by a concurrent write
+const maxPatchAttempts = 5
+
+// patchFunc returns the function that applies patches of the content type.
+// Plain json bodies are treated as merge patches.
+func patchFunc(contentType string) (func(doc []byte, patch []byte) ([]byte, error), error) {
+	if contentType == "" {
+		return jsonpatch.MergePatch, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mediaType {
+	case MergePatchContentType, "application/json":
+		return jsonpatch.MergePatch, nil
+	case JSONPatchContentType:
+		return jsonpatch.Apply, nil
+	}
+	return nil, fmt.Errorf("unsupported patch content type: %s", mediaType)
+}
+
+// PatchObject applies the patch in the request body to the object returned by
+// get and updates the object with the result.  The content type selects an
+// RFC 7396 merge patch or an RFC 6902 json patch.  newObj returns an empty
+// object to unmarshal the patched document into.  If validate isn't nil it's
+// called with the patched object before it's written, and the patch is
+// rejected as a bad request if it returns an error.
+//
+// The update only succeeds if the object hasn't been modified since it was
+// read.  If it was, the patch is applied again to the new version, unless the
+// request has an If-Match header, in which case the patch is only applied to
+// the version identified by the ETag.
+func PatchObject(inv InventoryDatabase, request events.APIGatewayProxyRequest, get func() (InventoryObject, error), newObj func() InventoryObject, validate func(InventoryObject) error) (*events.APIGatewayProxyResponse, error) {
+	applyPatch, err := patchFunc(header(request, "Content-Type"))
+	if err != nil {
+		return lambdautils.ErrStringResponse(http.StatusUnsupportedMediaType, err.Error())
+	}
+
+	ifMatch := IfMatch(request)
+	conditional := ifMatch != "" && ifMatch != "*"
+	for attempt := 0; attempt < maxPatchAttempts; attempt++ {
+		current, err := get()
+		switch err {
+		case nil:
+			break
+		case inventory.ErrObjectNotFound:
+			return lambdautils.ErrNotFound(err.Error())
+		default:
+			log.Printf("unable to get object to patch: %v", err)
+			return lambdautils.ErrInternalServerError()
+		}
+
+		if conditional {
+			version, err := ParseETag(ifMatch)
+			if err != nil || !version.Equal(current.Version()) {
+				return errPreconditionFailed()
+			}
+		}
+
+		doc, err := json.Marshal(current)
+		if err != nil {
+			log.Printf("unable to marshal object '%v': %v", current, err)
+			return lambdautils.ErrInternalServerError()
+		}
+
+		patched, err := applyPatch(doc, []byte(request.Body))
+		if opErr, ok := err.(*jsonpatch.OperationError); ok && (opErr.Err == jsonpatch.ErrTestFailed || opErr.Err == jsonpatch.ErrPathNotFound) {
+			return lambdautils.ErrStringResponse(http.StatusConflict, err.Error())
+		} else if err != nil {
+			return lambdautils.ErrBadRequest(err.Error())
+		}
+
+		obj := newObj()
+		err = json.Unmarshal(patched, obj)
+		if err != nil {
+			return lambdautils.ErrBadRequest(fmt.Sprintf("The patched object isn't valid: %v", err))
+		}
+
+		if obj.ID() != current.ID() {
+			return lambdautils.ErrBadRequest("Patches can't change the id of an object.")
+		}
+
+		if validate != nil {
+			err = validate(obj)
+			if err != nil {
+				return lambdautils.ErrBadRequest(err.Error())
+			}
+		}
+
+		obj.SetTimestamp(time.Now())
+		err = inv.ObjUpdateIfVersion(obj, current.Version())
+		switch {
+		case err == nil:
+			return lambdautils.NewJSONAPIGatewayProxyResponse(http.StatusOK, versionHeaders(obj), obj)
+		case err == inventory.ErrVersionMismatch && !conditional:
+			continue
+		case err == inventory.ErrVersionMismatch:
+			return errPreconditionFailed()
+		case err == inventory.ErrObjectNotFound:
+			return lambdautils.ErrNotFound(err.Error())
+		case err == inventory.ErrTooManyChanges:
+			return errTooManyChanges()
+		}
+
+		log.Printf("unable to patch object '%v': %v", obj, err)
+		return lambdautils.ErrInternalServerError()
+	}
+
+	return lambdautils.ErrStringResponse(http.StatusConflict, "The object was modified by other requests while applying the patch, please retry.")
+}
@@ -16,6 +16,11 @@ import (
 type TestResult struct {
 	ExpectedStatus     int
 	ExpectedBodyObject interface{}
+	ExpectedHeaders    map[string]string
+	// IgnoredBodyFields are left out when comparing the body of the response,
+	// and of each element if it's a list.  Useful for fields set by the server,
+	// like LastUpdated.
+	IgnoredBodyFields []string
 }
 
 type TestCases []TestCase
@@ -62,7 +67,21 @@ func (c TestCase) ResponseEqual(t *testing.T, response *events.APIGatewayProxyRe
 		return fmt.Errorf("status mismatch")
 	}
 
-	if diff := UnmarshalAndCompare(response.Body, result.ExpectedBodyObject); len(diff) > 0 {
+	for key, expected := range result.ExpectedHeaders {
+		if value := response.Headers[key]; value != expected {
+			t.Errorf("Expected header %s to be '%s', got '%s'", key, expected, value)
+			return fmt.Errorf("header mismatch")
+		}
+	}
+
+	compare := UnmarshalAndCompare
+	if len(result.IgnoredBodyFields) > 0 {
+		compare = func(marshaled string, obj interface{}) []string {
+			return CompareIgnoringFields(marshaled, obj, result.IgnoredBodyFields...)
+		}
+	}
+
+	if diff := compare(response.Body, result.ExpectedBodyObject); len(diff) > 0 {
 		t.Errorf("body doesn't match expected:")
 		for _, l := range diff {
 			t.Errorf(l)
@@ -84,3 +103,41 @@ func UnmarshalAndCompare(marshaled string, obj interface{}) []string {
 	}
 	return deep.Equal(reflect.Indirect(body).Interface(), obj)
 }
+
+// CompareIgnoringFields compares the json fields of marshaled and obj, other
+// than the ignored fields.  If they're lists the fields are ignored in each
+// element.
+func CompareIgnoringFields(marshaled string, obj interface{}, ignored ...string) []string {
+	var body interface{}
+	err := json.Unmarshal([]byte(marshaled), &body)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to unmarshal string: %v", err)}
+	}
+
+	expectedJSON, err := json.Marshal(obj)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to marshal expected object: %v", err)}
+	}
+
+	var expected interface{}
+	err = json.Unmarshal(expectedJSON, &expected)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to unmarshal expected object: %v", err)}
+	}
+
+	return deep.Equal(withoutFields(body, ignored), withoutFields(expected, ignored))
+}
+
+func withoutFields(value interface{}, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range fields {
+			delete(v, field)
+		}
+	case []interface{}:
+		for _, element := range v {
+			withoutFields(element, fields)
+		}
+	}
+	return value
+}
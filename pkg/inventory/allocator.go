@@ -0,0 +1,193 @@
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
+)
+
+// Allocation methods available by default
+const (
+	AllocationMethodRandom     = "random"
+	AllocationMethodSequential = "sequential"
+	AllocationMethodLocation   = "location"
+	AllocationMethodID         = "id"
+)
+
+// Allocator chooses a free address in the subnet for a reservation and creates
+// the reservation.  node is the node the reservation is for, or nil if it
+// isn't for a known node.
+type Allocator interface {
+	Allocate(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, node *types.Node) (*types.IPReservation, error)
+}
+
+// AllocatorFunc allows a function to be used as an Allocator
+type AllocatorFunc func(IPReservationStore, *types.IPReservation, *types.Subnet, *types.Node) (*types.IPReservation, error)
+
+// Allocate calls f
+func (f AllocatorFunc) Allocate(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, node *types.Node) (*types.IPReservation, error) {
+	return f(store, r, subnet, node)
+}
+
+var (
+	allocatorsLock sync.RWMutex
+	allocators     = map[string]Allocator{
+		AllocationMethodRandom: AllocatorFunc(func(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, _ *types.Node) (*types.IPReservation, error) {
+			return CreateRandomIPReservation(store, r, subnet)
+		}),
+		AllocationMethodSequential: AllocatorFunc(CreateSequentialIPReservation),
+		AllocationMethodLocation:   AllocatorFunc(CreateLocationIPReservation),
+		AllocationMethodID:         AllocatorFunc(CreateIDIPReservation),
+	}
+)
+
+// RegisterAllocator makes an allocator available as an allocation method for
+// subnets, replacing any allocator already registered with the same name.
+func RegisterAllocator(method string, allocator Allocator) {
+	allocatorsLock.Lock()
+	defer allocatorsLock.Unlock()
+	allocators[method] = allocator
+}
+
+// LookupAllocator returns the allocator registered for the allocation method
+func LookupAllocator(method string) (Allocator, error) {
+	allocatorsLock.RLock()
+	defer allocatorsLock.RUnlock()
+	allocator, ok := allocators[method]
+	if !ok {
+		return nil, fmt.Errorf("%v: '%s'", ErrUnknownAllocationMethod, method)
+	}
+	return allocator, nil
+}
+
+// AllocateIPReservation reserves an address in the subnet for the reservation
+// using the allocator registered for method
+func AllocateIPReservation(store IPReservationStore, method string, r *types.IPReservation, subnet *types.Subnet, node *types.Node) (*types.IPReservation, error) {
+	allocator, err := LookupAllocator(method)
+	if err != nil {
+		return nil, err
+	}
+	return allocator.Allocate(store, r, subnet, node)
+}
+
+// ValidateNetwork checks that every subnet in the network uses registered
+// allocation methods, and that its allocation ranges and exclusions lie within
+// the subnet.  Empty allocation methods disable allocation.
+func ValidateNetwork(network *types.Network) error {
+	for _, subnet := range network.Subnets {
+		if err := subnet.ValidateRanges(); err != nil {
+			return fmt.Errorf("invalid subnet %s: %v", subnet.Cidr, err)
+		}
+
+		for _, method := range []string{subnet.StaticAllocationMethod, subnet.DynamicAllocationMethod} {
+			if method == "" {
+				continue
+			}
+
+			if _, err := LookupAllocator(method); err != nil {
+				return fmt.Errorf("invalid subnet %s: %v", subnet.Cidr, err)
+			}
+		}
+	}
+	return nil
+}
+
+// reservedIPs returns the addresses of the existing reservations in the subnet
+func reservedIPs(existing types.IPReservationList) []net.IP {
+	reserved := make([]net.IP, 0, len(existing))
+	for _, r := range existing {
+		if r.IP != nil {
+			reserved = append(reserved, r.IP.IP)
+		}
+	}
+	return reserved
+}
+
+// checkAllocatable returns an error if ip can't be allocated in the subnet
+func checkAllocatable(subnet *types.Subnet, ip net.IP) error {
+	if !subnet.Allocatable(ip) {
+		return fmt.Errorf("%s is excluded from allocation in %s", ip, subnet.Cidr)
+	}
+	return nil
+}
+
+// createCandidateIPReservation creates a reservation for the address chosen
+// by candidate from the existing reservations in the subnet, retrying if the
+// address is reserved concurrently.
+func createCandidateIPReservation(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, candidate func(types.IPReservationList) (net.IP, error)) (*types.IPReservation, error) {
+	maxCount := 10
+	reservation := *r
+	if reservation.Start == nil {
+		start := time.Now()
+		reservation.Start = &start
+	}
+
+	for count := 0; count < maxCount; count++ {
+		existingReservations, err := store.GetIPReservations(subnet.Cidr)
+		if err != nil {
+			return nil, err
+		}
+
+		ip, err := candidate(existingReservations)
+		if err != nil {
+			return nil, err
+		}
+		reservation.IP = &net.IPNet{IP: ip, Mask: subnet.Cidr.Mask}
+
+		err = store.CreateIPReservation(&reservation)
+		if err == ErrAlreadyExists {
+			continue
+		}
+		return &reservation, err
+	}
+	return nil, fmt.Errorf("retry limit exceeded: giving up on reserving an ip for %v", r)
+}
+
+// CreateSequentialIPReservation reserves the lowest free address in the
+// subnet's pools for the reservation
+func CreateSequentialIPReservation(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, _ *types.Node) (*types.IPReservation, error) {
+	return createCandidateIPReservation(store, r, subnet, func(existing types.IPReservationList) (net.IP, error) {
+		return lowestFreeIP(subnet, existing)
+	})
+}
+
+// CreateLocationIPReservation reserves the address of the node's chassis
+// location in the subnet for the reservation
+func CreateLocationIPReservation(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, node *types.Node) (*types.IPReservation, error) {
+	if node == nil {
+		return nil, fmt.Errorf("location allocation requires a node")
+	}
+
+	return createCandidateIPReservation(store, r, subnet, func(existing types.IPReservationList) (net.IP, error) {
+		ip, err := subnet.LocationIP(node, reservedIPs(existing)...)
+		if err != nil {
+			return nil, err
+		}
+		return ip, checkAllocatable(subnet, ip)
+	})
+}
+
+// CreateIDIPReservation reserves the address in the subnet matching the
+// numeric id of the node for the reservation
+func CreateIDIPReservation(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet, node *types.Node) (*types.IPReservation, error) {
+	if node == nil {
+		return nil, fmt.Errorf("id allocation requires a node")
+	}
+
+	id, err := node.NumericId()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get numeric id of node %s: %v", node.ID(), err)
+	}
+
+	return createCandidateIPReservation(store, r, subnet, func(existing types.IPReservationList) (net.IP, error) {
+		ip, err := ipam.GetIpById(id, subnet.Cidr, reservedIPs(existing)...)
+		if err != nil {
+			return nil, err
+		}
+		return ip, checkAllocatable(subnet, ip)
+	})
+}
@@ -0,0 +1,124 @@
+package inventory_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
+)
+
+func TestSequentialAllocator(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/30")
+	subnet := &types.Subnet{Cidr: cidr, StaticAllocationMethod: inventory.AllocationMethodSequential}
+
+	expected := []string{"10.0.0.1", "10.0.0.2"}
+	for _, e := range expected {
+		r, err := inventory.AllocateIPReservation(inv.IPReservation(), subnet.StaticAllocationMethod, types.NewStaticIPReservation(), subnet, nil)
+		if err != nil {
+			t.Fatalf("unable to allocate address: %v", err)
+		}
+
+		if !r.IP.IP.Equal(net.ParseIP(e)) {
+			t.Errorf("expected %s to be allocated, got %s", e, r.IP.IP)
+		}
+	}
+
+	_, err := inventory.AllocateIPReservation(inv.IPReservation(), subnet.StaticAllocationMethod, types.NewStaticIPReservation(), subnet, nil)
+	if err == nil {
+		t.Errorf("expected full subnet to fail allocation")
+	}
+}
+
+func TestNodeAllocators(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/16")
+	subnet := &types.Subnet{Cidr: cidr}
+	node := &types.Node{InventoryID: "node0042", ChassisLocation: &types.ChassisLocation{Rack: "xr20", BottomU: 31}, ChassisSubIndex: "a"}
+
+	cases := map[string]string{
+		inventory.AllocationMethodLocation: "10.0.81.250",
+		inventory.AllocationMethodID:       "10.0.0.42",
+	}
+
+	for method, expected := range cases {
+		r, err := inventory.AllocateIPReservation(inv.IPReservation(), method, types.NewStaticIPReservation(), subnet, node)
+		if err != nil {
+			t.Errorf("unable to allocate %s address: %v", method, err)
+			continue
+		}
+
+		if !r.IP.IP.Equal(net.ParseIP(expected)) {
+			t.Errorf("expected %s allocation to return %s, got %s", method, expected, r.IP.IP)
+		}
+
+		_, err = inventory.AllocateIPReservation(inv.IPReservation(), method, types.NewStaticIPReservation(), subnet, node)
+		if err == nil {
+			t.Errorf("expected second %s allocation to conflict with the first", method)
+		}
+
+		_, err = inventory.AllocateIPReservation(inv.IPReservation(), method, types.NewStaticIPReservation(), subnet, nil)
+		if err == nil {
+			t.Errorf("expected %s allocation without a node to fail", method)
+		}
+	}
+}
+
+func TestValidateNetwork(t *testing.T) {
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	network := &types.Network{Name: "test", Subnets: types.SubnetList{{Cidr: cidr, StaticAllocationMethod: "location", DynamicAllocationMethod: "random"}}}
+	if err := inventory.ValidateNetwork(network); err != nil {
+		t.Errorf("expected network to be valid: %v", err)
+	}
+
+	network.Subnets[0].DynamicAllocationMethod = "bogus"
+	if err := inventory.ValidateNetwork(network); err == nil {
+		t.Errorf("expected unknown allocation method to be invalid")
+	}
+
+	inventory.RegisterAllocator("bogus", inventory.AllocatorFunc(inventory.CreateSequentialIPReservation))
+	if err := inventory.ValidateNetwork(network); err != nil {
+		t.Errorf("expected registered allocation method to be valid: %v", err)
+	}
+}
+
+func TestAllocatorsHonorPools(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	subnet := &types.Subnet{
+		Cidr:             cidr,
+		Gateway:          net.ParseIP("10.0.0.10"),
+		DNS:              []net.IP{net.ParseIP("10.0.0.11")},
+		AllocationRanges: []ipam.IPRange{{Start: net.ParseIP("10.0.0.10"), End: net.ParseIP("10.0.0.20")}},
+		Exclusions:       []ipam.IPRange{{Start: net.ParseIP("10.0.0.12"), End: net.ParseIP("10.0.0.15")}, {Start: net.ParseIP("10.0.0.17")}},
+	}
+
+	allocated := map[string]bool{}
+	for _, method := range []string{inventory.AllocationMethodSequential, inventory.AllocationMethodRandom, inventory.AllocationMethodSequential, inventory.AllocationMethodRandom} {
+		r, err := inventory.AllocateIPReservation(inv.IPReservation(), method, types.NewStaticIPReservation(), subnet, nil)
+		if err != nil {
+			t.Fatalf("unable to allocate %s address: %v", method, err)
+		}
+		allocated[r.IP.IP.String()] = true
+	}
+
+	for _, ip := range []string{"10.0.0.16", "10.0.0.18", "10.0.0.19", "10.0.0.20"} {
+		if !allocated[ip] {
+			t.Errorf("expected %s to be allocated, got %v", ip, allocated)
+		}
+	}
+
+	_, err := inventory.AllocateIPReservation(inv.IPReservation(), inventory.AllocationMethodRandom, types.NewStaticIPReservation(), subnet, nil)
+	if err == nil {
+		t.Errorf("expected allocation to fail once the pool is full")
+	}
+
+	node := &types.Node{InventoryID: "node0013"}
+	_, err = inventory.AllocateIPReservation(inv.IPReservation(), inventory.AllocationMethodID, types.NewStaticIPReservation(), subnet, node)
+	if err == nil {
+		t.Errorf("expected allocation of an excluded address to fail")
+	}
+}
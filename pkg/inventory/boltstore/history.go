@@ -0,0 +1,72 @@
+package boltstore
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+type HistoryStore struct {
+	*BoltStore
+}
+
+// historySequence formats the sequence of a record so that keys sort in the
+// order the records were written
+func historySequence(r *types.HistoryRecord) string {
+	return fmt.Sprintf("%020d", r.Sequence())
+}
+
+func historyPrefix(objectType string, objectID string) string {
+	return types.HistoryKey(objectType, objectID) + "/"
+}
+
+// AppendHistory stores the record, moving its timestamp forward until it
+// doesn't collide with an existing record for the object
+func (db *HistoryStore) AppendHistory(r *types.HistoryRecord) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		prefix := historyPrefix(r.ObjectType, r.ObjectID)
+		for b.Get([]byte(prefix+historySequence(r))) != nil {
+			r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+		}
+		return put(b, []byte(prefix+historySequence(r)), r)
+	})
+}
+
+// GetHistory returns a page of the changes to the object, oldest first
+func (db *HistoryStore) GetHistory(objectType string, objectID string, opts inventory.ListOptions) ([]*types.HistoryRecord, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefix := historyPrefix(objectType, objectID)
+	values := [][]byte{}
+	err = db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k, v := c.Seek([]byte(prefix + pager.After())); k != nil && bytes.HasPrefix(k, []byte(prefix)) && !pager.Done(); k, v = c.Next() {
+			sequence := k[len(prefix):]
+			if bytes.Contains(sequence, []byte("/")) {
+				continue
+			}
+			if pager.Add(string(sequence)) {
+				values = append(values, v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := make([]*types.HistoryRecord, 0)
+	err = unmarshalList(values, &records)
+	if err != nil {
+		return nil, "", err
+	}
+	return records, pager.Next(), nil
+}
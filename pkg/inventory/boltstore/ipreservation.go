@@ -0,0 +1,496 @@
+package boltstore
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Reservations are stored in a sub-bucket per network, keyed by ip, giving a
+// unique (net, ip) key.  The mac index has a sub-bucket per mac, with keys of
+// the form "<net>|<ip>" pointing back at the reservation.
+
+func netKey(ipNet *net.IPNet) []byte {
+	return []byte(ipNet.IP.Mask(ipNet.Mask).String())
+}
+
+func reservationKey(ipNet *net.IPNet) ([]byte, []byte, error) {
+	if ipNet == nil {
+		return nil, nil, types.ErrKeyNotSet
+	}
+	return netKey(ipNet), []byte(ipNet.IP.String()), nil
+}
+
+func macIndexKey(network, ip []byte) []byte {
+	return bytes.Join([][]byte{network, ip}, []byte("|"))
+}
+
+func getReservation(tx *bolt.Tx, network, ip []byte) (*types.IPReservation, error) {
+	r := &types.IPReservation{}
+	err := get(tx.Bucket(ipReservationBucket).Bucket(network), ip, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// getActiveReservation returns the reservation, or ErrObjectNotFound if it has
+// ended
+func getActiveReservation(tx *bolt.Tx, network, ip []byte) (*types.IPReservation, error) {
+	r, err := getReservation(tx, network, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Ended(time.Now()) {
+		return nil, inventory.ErrObjectNotFound
+	}
+	return r, nil
+}
+
+// putReservation stores the reservation and adds it to the mac index
+func putReservation(tx *bolt.Tx, r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(ipReservationBucket).CreateBucketIfNotExists(network)
+	if err != nil {
+		return err
+	}
+
+	err = put(b, ip, r)
+	if err != nil {
+		return err
+	}
+
+	if len(r.MAC) == 0 {
+		return nil
+	}
+
+	idx, err := tx.Bucket(ipReservationMacIndexBucket).CreateBucketIfNotExists([]byte(r.MAC.String()))
+	if err != nil {
+		return err
+	}
+	key := macIndexKey(network, ip)
+	return idx.Put(key, key)
+}
+
+// deleteReservation removes the reservation and its mac index entry
+func deleteReservation(tx *bolt.Tx, r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	if len(r.MAC) > 0 {
+		if idx := tx.Bucket(ipReservationMacIndexBucket).Bucket([]byte(r.MAC.String())); idx != nil {
+			err = idx.Delete(macIndexKey(network, ip))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	b := tx.Bucket(ipReservationBucket).Bucket(network)
+	if b == nil {
+		return nil
+	}
+	return b.Delete(ip)
+}
+
+// getReservationsByMac returns all reservations for the mac whose index key
+// starts with prefix
+func getReservationsByMac(tx *bolt.Tx, mac net.HardwareAddr, prefix []byte) (types.IPReservationList, error) {
+	reservations := types.IPReservationList{}
+	idx := tx.Bucket(ipReservationMacIndexBucket).Bucket([]byte(mac.String()))
+	if idx == nil {
+		return reservations, nil
+	}
+
+	c := idx.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		parts := bytes.SplitN(k, []byte("|"), 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid mac index entry: %s", k)
+		}
+		r, err := getReservation(tx, parts[0], parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("unable to lookup indexed reservation %s: %v", k, err)
+		}
+		reservations = append(reservations, r)
+	}
+	return reservations, nil
+}
+
+type IPReservationStore struct {
+	*BoltStore
+}
+
+func (db *IPReservationStore) GetIPReservation(ipNet *net.IPNet) (*types.IPReservation, error) {
+	network, ip, err := reservationKey(ipNet)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *types.IPReservation
+	err = db.view(func(tx *bolt.Tx) error {
+		r, err = getActiveReservation(tx, network, ip)
+		return err
+	})
+	return r, err
+}
+
+// allReservations returns every stored reservation, including those that have
+// ended
+func (db *IPReservationStore) allReservations() (types.IPReservationList, error) {
+	reservations := make(types.IPReservationList, 0)
+	err := db.view(func(tx *bolt.Tx) error {
+		values := [][]byte{}
+		err := tx.Bucket(ipReservationBucket).ForEach(func(network, _ []byte) error {
+			return tx.Bucket(ipReservationBucket).Bucket(network).ForEach(func(ip, v []byte) error {
+				values = append(values, v)
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		return unmarshalList(values, &reservations)
+	})
+	return reservations, err
+}
+
+func (db *IPReservationStore) GetAllIPReservations() (types.IPReservationList, error) {
+	reservations, err := db.allReservations()
+	if err != nil {
+		return nil, fmt.Errorf("error getting all ip reservations: %v", err)
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+func (db *IPReservationStore) GetIPReservationsByMac(mac net.HardwareAddr) (types.IPReservationList, error) {
+	if len(mac) == 0 {
+		return types.IPReservationList{}, nil
+	}
+
+	var reservations types.IPReservationList
+	err := db.view(func(tx *bolt.Tx) error {
+		var err error
+		reservations, err = getReservationsByMac(tx, mac, []byte{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// GetIPReservationsByMacAt returns the reservations for the mac that were valid
+// at the time specified, including those that have since been deleted or
+// replaced
+func (db *IPReservationStore) GetIPReservationsByMacAt(mac net.HardwareAddr, at time.Time) (types.IPReservationList, error) {
+	if len(mac) == 0 {
+		return types.IPReservationList{}, nil
+	}
+
+	var reservations types.IPReservationList
+	err := db.view(func(tx *bolt.Tx) error {
+		var err error
+		reservations, err = getReservationsByMac(tx, mac, []byte{})
+		if err != nil {
+			return err
+		}
+
+		reservations, err = inventory.AddArchivedIPReservations(&RevisionStore{&BoltStore{db: db.db, tx: tx}}, reservations, mac, at)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// GetIPReservations returns all current reservations in the specified subnet
+func (db *IPReservationStore) GetIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
+	reservations, err := db.GetStoredIPReservations(ipNet)
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// GetStoredIPReservations returns every reservation stored in the specified
+// subnet, including those that have ended
+func (db *IPReservationStore) GetStoredIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
+	if ipNet == nil {
+		return nil, fmt.Errorf("specified network is nil")
+	}
+
+	reservations := make(types.IPReservationList, 0)
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ipReservationBucket).Bucket(netKey(ipNet))
+		if b == nil {
+			return nil
+		}
+
+		values := [][]byte{}
+		err := b.ForEach(func(ip, v []byte) error {
+			values = append(values, v)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return unmarshalList(values, &reservations)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (db *IPReservationStore) GetExistingIPReservationInSubnet(subnetCidr *net.IPNet, mac net.HardwareAddr) (*types.IPReservation, error) {
+	if len(mac) == 0 {
+		return nil, nil
+	}
+
+	var results types.IPReservationList
+	err := db.view(func(tx *bolt.Tx) error {
+		var err error
+		results, err = getReservationsByMac(tx, mac, macIndexKey(netKey(subnetCidr), []byte{}))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results = results.Active(time.Now())
+	if len(results) == 0 {
+		return nil, inventory.ErrObjectNotFound
+	}
+
+	if len(results) > 1 {
+		return nil, fmt.Errorf("unable to lookup exactly one item: found %d matching", len(results))
+	}
+
+	return results[0], nil
+}
+
+func (db *IPReservationStore) CreateRandomIPReservation(r *types.IPReservation, subnet *types.Subnet) (*types.IPReservation, error) {
+	return inventory.CreateRandomIPReservation(db, r, subnet)
+}
+
+// CreateIPReservation creates the reservation only if no current reservation
+// exists for the address, replacing and archiving any reservation that has
+// ended.  The check and writes happen in the same transaction.
+func (db *IPReservationStore) CreateIPReservation(r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		return txdb.update(func(tx *bolt.Tx) error {
+			existing, err := getReservation(tx, network, ip)
+			switch {
+			case err == inventory.ErrObjectNotFound:
+			case err != nil:
+				return err
+			case !existing.Ended(time.Now()):
+				return inventory.ErrAlreadyExists
+			default:
+				err = inventory.ArchiveIPReservation(txdb.Revision(), existing)
+				if err != nil {
+					return err
+				}
+
+				err = deleteReservation(tx, existing)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = putReservation(tx, r)
+			if err != nil {
+				return err
+			}
+			return txdb.recordChange(types.HistoryObjectIPReservation, r.IP.IP.String(), nil, r)
+		})
+	})
+}
+
+// UpdateIPReservation replaces an existing reservation, only if the mac
+// matches the existing reservation
+func (db *IPReservationStore) UpdateIPReservation(r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		return txdb.update(func(tx *bolt.Tx) error {
+			existing, err := getActiveReservation(tx, network, ip)
+			if err == inventory.ErrObjectNotFound {
+				return inventory.ErrUpdateConflict
+			} else if err != nil {
+				return err
+			}
+
+			if len(existing.MAC) == 0 || existing.MAC.String() != r.MAC.String() {
+				return inventory.ErrUpdateConflict
+			}
+
+			err = putReservation(tx, r)
+			if err != nil {
+				return err
+			}
+			return txdb.recordChange(types.HistoryObjectIPReservation, r.IP.IP.String(), existing, r)
+		})
+	})
+}
+
+func (db *IPReservationStore) CreateOrUpdateIPReservation(r *types.IPReservation) error {
+	err := db.UpdateIPReservation(r)
+	if err != inventory.ErrUpdateConflict {
+		return err
+	}
+
+	return db.CreateIPReservation(r)
+}
+
+func (db *IPReservationStore) Exists(r *types.IPReservation) (bool, error) {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return false, err
+	}
+
+	var found bool
+	err = db.view(func(tx *bolt.Tx) error {
+		_, err := getActiveReservation(tx, network, ip)
+		found = err == nil
+		if err == inventory.ErrObjectNotFound {
+			return nil
+		}
+		return err
+	})
+	return found, err
+}
+
+// Delete marks the reservation as deleted, keeping it and its mac index entry
+// so that it can still be looked up as it was in the past
+func (db *IPReservationStore) Delete(r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		return txdb.update(func(tx *bolt.Tx) error {
+			existing, err := getActiveReservation(tx, network, ip)
+			if err == inventory.ErrObjectNotFound {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			deleted := *existing
+			now := time.Now()
+			deleted.Deleted = &now
+			err = putReservation(tx, &deleted)
+			if err != nil {
+				return err
+			}
+			return txdb.recordChange(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
+		})
+	})
+}
+
+// GetExpiredIPReservations returns the reservations that expired before the
+// time specified, including those that were deleted
+func (db *IPReservationStore) GetExpiredIPReservations(at time.Time) (types.IPReservationList, error) {
+	reservations, err := db.allReservations()
+	if err != nil {
+		return nil, fmt.Errorf("error getting expired ip reservations: %v", err)
+	}
+	return reservations.Expired(at), nil
+}
+
+// PurgeIPReservation removes the reservation and its mac index entry if it has
+// expired, archiving it so that GetIPReservationsByMacAt can still find it,
+// and records its removal in history unless it was deleted
+func (db *IPReservationStore) PurgeIPReservation(r *types.IPReservation) error {
+	network, ip, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		return txdb.update(func(tx *bolt.Tx) error {
+			existing, err := getReservation(tx, network, ip)
+			if err == inventory.ErrObjectNotFound {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if !existing.Expired(time.Now()) {
+				return inventory.ErrUpdateConflict
+			}
+
+			err = inventory.ArchiveIPReservation(txdb.Revision(), existing)
+			if err != nil {
+				return err
+			}
+
+			err = deleteReservation(tx, existing)
+			if err != nil {
+				return err
+			}
+
+			if existing.Deleted != nil {
+				// the deletion was recorded when the reservation was deleted
+				return nil
+			}
+			return txdb.recordChange(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
+		})
+	})
+}
+
+func (db *IPReservationStore) ObjExists(obj interface{}) (bool, error) {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(r)
+}
+
+func (db *IPReservationStore) ObjCreate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.CreateIPReservation(r)
+}
+
+func (db *IPReservationStore) ObjUpdate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIPReservation(r)
+}
+
+func (db *IPReservationStore) ObjDelete(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(r)
+}
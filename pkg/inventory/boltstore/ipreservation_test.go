@@ -0,0 +1,277 @@
+package boltstore
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/go-test/deep"
+)
+
+func TestGetIPReservationInSubnetByMAC(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	r := types.NewStaticIPReservation()
+	r.IP = &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+	r.MAC = mac
+	zeroTime := time.Unix(0, 0)
+	r.Start = &zeroTime
+
+	err := inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Errorf("Unable to create IP reservation %v: %v", r, err)
+	}
+
+	r2 := types.NewStaticIPReservation()
+	r2.IP = &net.IPNet{IP: net.ParseIP("10.0.1.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+	r2.MAC = mac
+	r2.Start = &zeroTime
+
+	err = inv.IPReservation().CreateIPReservation(r2)
+	if err != nil {
+		t.Errorf("Unable to create IP reservation %v: %v", r2, err)
+	}
+
+	r3 := types.NewStaticIPReservation()
+	r3.IP = &net.IPNet{IP: net.ParseIP("10.0.2.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+
+	err = inv.IPReservation().CreateIPReservation(r3)
+	if err != nil {
+		t.Errorf("Unable to create IP reservation %v: %v", r3, err)
+	}
+
+	rResult, err := inv.IPReservation().GetExistingIPReservationInSubnet(r.IP, mac)
+	if err != nil {
+		t.Errorf("error getting reservation for mac in subnet: %v", err)
+	}
+
+	if diff := deep.Equal(r, rResult); len(diff) > 0 {
+		t.Errorf("Reservations not equal: %v", diff)
+	}
+
+	_, err = inv.IPReservation().GetExistingIPReservationInSubnet(r3.IP, mac)
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected not found error for subnet without reservation for mac, got: %v", err)
+	}
+
+	reservations, err := inv.IPReservation().GetIPReservationsByMac(mac)
+	if err != nil {
+		t.Errorf("error getting reservation for mac in subnet: %v", err)
+	}
+
+	if len(reservations) != 2 {
+		t.Errorf("wrong number of reservations returned")
+	}
+
+	reservations, err = inv.IPReservation().GetIPReservations(r.IP)
+	if err != nil {
+		t.Errorf("unable to get IP reservations in subnet: %v", err)
+	}
+
+	if len(reservations) != 1 {
+		t.Errorf("wrong number of reservations returned")
+	}
+
+	reservations, err = inv.IPReservation().GetIPReservationsByMac(net.HardwareAddr{})
+	if err != nil {
+		t.Errorf("error getting reservation for empty mac: %v", err)
+	}
+
+	if len(reservations) != 0 {
+		t.Errorf("wrong number of reservations returned")
+	}
+}
+
+func TestIPReservationConditionalWrites(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	otherMac, _ := net.ParseMAC("00:01:02:03:04:06")
+	r := types.NewStaticIPReservation()
+	r.IP = &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+	r.MAC = mac
+
+	err := inv.IPReservation().UpdateIPReservation(r)
+	if err != inventory.ErrUpdateConflict {
+		t.Errorf("expected update of non-existent reservation to conflict, got: %v", err)
+	}
+
+	err = inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = inv.IPReservation().CreateIPReservation(r)
+	if err != inventory.ErrAlreadyExists {
+		t.Errorf("expected second create to fail with already exists, got: %v", err)
+	}
+
+	r.Metadata["hostname"] = "foo"
+	err = inv.IPReservation().UpdateIPReservation(r)
+	if err != nil {
+		t.Errorf("unable to update reservation: %v", err)
+	}
+
+	stolen := *r
+	stolen.MAC = otherMac
+	err = inv.IPReservation().UpdateIPReservation(&stolen)
+	if err != inventory.ErrUpdateConflict {
+		t.Errorf("expected update with different mac to conflict, got: %v", err)
+	}
+
+	result, err := inv.IPReservation().GetIPReservation(r.IP)
+	if err != nil {
+		t.Fatalf("unable to get reservation: %v", err)
+	}
+
+	if hostname, _ := result.Metadata.GetString("hostname"); hostname != "foo" || result.MAC.String() != mac.String() {
+		t.Errorf("reservation not updated as expected: %v", result)
+	}
+}
+
+func TestCreateRandomIPReservation(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/30")
+	subnet := &types.Subnet{Cidr: cidr, DynamicAllocationMethod: "random"}
+
+	for i := 0; i < 2; i++ {
+		r, err := inv.IPReservation().CreateRandomIPReservation(types.NewDynamicIPReservation(time.Hour), subnet)
+		if err != nil {
+			t.Fatalf("unable to create random reservation: %v", err)
+		}
+		if !cidr.Contains(r.IP.IP) {
+			t.Errorf("reserved ip outside of subnet: %s", r.IP)
+		}
+	}
+
+	_, err := inv.IPReservation().CreateRandomIPReservation(types.NewDynamicIPReservation(time.Hour), subnet)
+	if err == nil {
+		t.Errorf("expected error reserving an address in a full subnet")
+	}
+}
+
+func TestIPReservationSoftDelete(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	otherMac, _ := net.ParseMAC("00:01:02:03:04:06")
+	r := types.NewStaticIPReservation()
+	r.IP = &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+	r.MAC = mac
+	start := time.Now().Add(-time.Hour)
+	r.Start = &start
+
+	err := inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = inv.IPReservation().Delete(r)
+	if err != nil {
+		t.Fatalf("unable to delete reservation: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	_, err = inv.IPReservation().GetIPReservation(r.IP)
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected deleted reservation not to be found, got: %v", err)
+	}
+
+	if exists, err := inv.IPReservation().Exists(r); exists || err != nil {
+		t.Errorf("expected deleted reservation not to exist: %v", err)
+	}
+
+	if current, err := inv.IPReservation().GetIPReservationsByMac(mac); len(current) != 0 || err != nil {
+		t.Errorf("expected no current reservations for the mac, got %d: %v", len(current), err)
+	}
+
+	if stored, err := inv.IPReservation().GetStoredIPReservations(r.IP); err != nil || len(stored) != 1 {
+		t.Errorf("expected the deleted reservation to still be stored, got %v: %v", stored, err)
+	}
+
+	past, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(past) != 1 || !past[0].IP.IP.Equal(r.IP.IP) {
+		t.Errorf("expected to find the deleted reservation as it was, got %v: %v", past, err)
+	}
+
+	err = inv.IPReservation().UpdateIPReservation(r)
+	if err != inventory.ErrUpdateConflict {
+		t.Errorf("expected update of deleted reservation to conflict, got: %v", err)
+	}
+
+	replacement := types.NewStaticIPReservation()
+	replacement.IP = r.IP
+	replacement.MAC = otherMac
+	err = inv.IPReservation().CreateIPReservation(replacement)
+	if err != nil {
+		t.Fatalf("unable to replace deleted reservation: %v", err)
+	}
+
+	result, err := inv.IPReservation().GetIPReservation(r.IP)
+	if err != nil || result.MAC.String() != otherMac.String() {
+		t.Errorf("expected the replacement reservation, got %v: %v", result, err)
+	}
+
+	replaced, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(replaced) != 1 || !replaced[0].IP.IP.Equal(r.IP.IP) || replaced[0].MAC.String() != mac.String() {
+		t.Errorf("expected to find the replaced reservation as it was, got %v: %v", replaced, err)
+	}
+}
+
+func TestIPReservationPurge(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	start := time.Now().Add(-2 * time.Hour)
+	end := time.Now().Add(-time.Hour)
+	r := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}, MAC: mac, Start: &start, End: &end}
+
+	err := inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	expired, err := inv.IPReservation().GetExpiredIPReservations(time.Now())
+	if err != nil || len(expired) != 1 {
+		t.Fatalf("expected to find the expired reservation, got %v: %v", expired, err)
+	}
+
+	if old, err := inv.IPReservation().GetExpiredIPReservations(start); err != nil || len(old) != 0 {
+		t.Errorf("expected no reservations to have expired before the start, got %v: %v", old, err)
+	}
+
+	err = inv.IPReservation().PurgeIPReservation(expired[0])
+	if err != nil {
+		t.Fatalf("unable to purge reservation: %v", err)
+	}
+
+	if remaining, err := inv.IPReservation().GetExpiredIPReservations(time.Now()); err != nil || len(remaining) != 0 {
+		t.Errorf("expected the purged reservation to be removed, got %v: %v", remaining, err)
+	}
+
+	past, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(past) != 1 || !past[0].IP.IP.Equal(r.IP.IP) {
+		t.Errorf("expected to find the purged reservation as it was, got %v: %v", past, err)
+	}
+
+	current := types.NewStaticIPReservation()
+	current.IP = r.IP
+	err = inv.IPReservation().CreateIPReservation(current)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = inv.IPReservation().PurgeIPReservation(current)
+	if err != inventory.ErrUpdateConflict {
+		t.Errorf("expected purge of a current reservation to conflict, got: %v", err)
+	}
+}
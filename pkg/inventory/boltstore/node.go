@@ -0,0 +1,252 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+type NodeStore struct {
+	*BoltStore
+}
+
+func (db *NodeStore) GetNodes() (map[string]*types.Node, error) {
+	nodeList := make([]*types.Node, 0, 0)
+	err := db.getAll(nodeBucket, &nodeList)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*types.Node)
+	for _, n := range nodeList {
+		nodes[n.ID()] = n
+	}
+	return nodes, nil
+}
+
+// ListNodes returns a page of the nodes matching the filter in opts
+func (db *NodeStore) ListNodes(opts inventory.ListOptions) ([]*types.Node, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodes := make([]*types.Node, 0)
+	err = db.list(nodeBucket, pager, func(value []byte) error {
+		node := &types.Node{}
+		err := json.Unmarshal(value, node)
+		if err != nil {
+			return err
+		}
+		if opts.Filter.Match(node) && pager.Add(node.ID()) {
+			nodes = append(nodes, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return nodes, pager.Next(), nil
+}
+
+func (db *NodeStore) GetNodeByID(id string) (*types.Node, error) {
+	if id == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	node := &types.Node{}
+	err := db.get(nodeBucket, id, node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (db *NodeStore) GetNodeByMAC(mac net.HardwareAddr) (*types.Node, error) {
+	var nodeID string
+	err := db.view(func(tx *bolt.Tx) error {
+		id := tx.Bucket(nodeMacIndexBucket).Get([]byte(mac.String()))
+		if id == nil {
+			return inventory.ErrObjectNotFound
+		}
+		nodeID = string(id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetNodeByID(nodeID)
+}
+
+// reconcileMacIndex points every mac attached to the node at the node, and
+// removes entries for macs that are no longer attached
+func reconcileMacIndex(tx *bolt.Tx, node *types.Node) error {
+	b := tx.Bucket(nodeMacIndexBucket)
+
+	oldMacs := [][]byte{}
+	err := b.ForEach(func(mac, nodeID []byte) error {
+		if string(nodeID) == node.ID() {
+			oldMacs = append(oldMacs, mac)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, mac := range oldMacs {
+		err = b.Delete(mac)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, iface := range node.Networks {
+		for _, mac := range iface.NICs {
+			err = b.Put([]byte(mac.String()), []byte(node.ID()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (db *NodeStore) Create(newNode *types.Node) error {
+	return db.Update(newNode)
+}
+
+func (db *NodeStore) Update(updatedNode *types.Node) error {
+	return db.write(updatedNode, false, nil)
+}
+
+// UpdateIfVersion updates the node if the stored copy is at version
+func (db *NodeStore) UpdateIfVersion(node *types.Node, version time.Time) error {
+	return db.write(node, false, &version)
+}
+
+func (db *NodeStore) Exists(node *types.Node) (bool, error) {
+	if node.ID() == "" {
+		return false, types.ErrKeyNotSet
+	}
+	return db.exists(nodeBucket, node.ID())
+}
+
+func (db *NodeStore) Delete(node *types.Node) error {
+	return db.write(node, true, nil)
+}
+
+// DeleteIfVersion deletes the node if the stored copy is at version
+func (db *NodeStore) DeleteIfVersion(node *types.Node, version time.Time) error {
+	return db.write(node, true, &version)
+}
+
+// write reconciles the node's ip reservations, updates the mac index, writes
+// or deletes the node and records a revision of it in a single bolt
+// transaction, so none of the changes are kept if any of them fail.  If
+// version isn't nil, the stored copy of the node must be at version.
+func (db *NodeStore) write(node *types.Node, deleteNode bool, version *time.Time) error {
+	if node.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		tx := txdb.tx
+		b := tx.Bucket(nodeBucket)
+		before, err := previous(b, []byte(node.ID()), &types.Node{}, version)
+		if err != nil {
+			return err
+		}
+
+		if deleteNode {
+			node.Networks = types.NICInfoMap{}
+		}
+
+		// reservation changes are part of the node's history record
+		err = inventory.ReconcileNodeIPs(txdb.WithActor(""), node)
+		if err != nil {
+			return err
+		}
+
+		err = reconcileMacIndex(tx, node)
+		if err != nil {
+			return err
+		}
+
+		if deleteNode {
+			err = b.Delete([]byte(node.ID()))
+			if err != nil {
+				return err
+			}
+
+			err = inventory.PutDeletedRevision(txdb.Revision(), types.HistoryObjectNode, node.ID())
+			if err != nil {
+				return err
+			}
+			return txdb.recordChange(types.HistoryObjectNode, node.ID(), before, nil)
+		}
+
+		err = put(b, []byte(node.ID()), node)
+		if err != nil {
+			return err
+		}
+
+		err = inventory.PutRevision(txdb.Revision(), types.HistoryObjectNode, node)
+		if err != nil {
+			return err
+		}
+		return txdb.recordChange(types.HistoryObjectNode, node.ID(), before, node)
+	})
+}
+
+func (db *NodeStore) ObjDelete(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(node)
+}
+
+func (db *NodeStore) ObjCreate(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Create(node)
+}
+
+func (db *NodeStore) ObjUpdate(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Update(node)
+}
+
+func (db *NodeStore) ObjExists(obj interface{}) (bool, error) {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(node)
+}
+
+func (db *NodeStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(node, version)
+}
+
+func (db *NodeStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(node, version)
+}
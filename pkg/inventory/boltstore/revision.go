@@ -0,0 +1,95 @@
+package boltstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+type RevisionStore struct {
+	*BoltStore
+}
+
+func revisionSequence(sequence int64) string {
+	return fmt.Sprintf("%020d", sequence)
+}
+
+// PutRevision stores the revision, replacing any revision of the object with
+// the same timestamp
+func (db *RevisionStore) PutRevision(r *types.Revision) error {
+	return db.put(revisionBucket, historyPrefix(r.ObjectType, r.ObjectID)+revisionSequence(r.Sequence()), r)
+}
+
+// AppendRevision stores the revision, moving its timestamp forward until it
+// doesn't collide with an existing revision of the object
+func (db *RevisionStore) AppendRevision(r *types.Revision) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(revisionBucket)
+		prefix := historyPrefix(r.ObjectType, r.ObjectID)
+		for b.Get([]byte(prefix+revisionSequence(r.Sequence()))) != nil {
+			r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+		}
+		return put(b, []byte(prefix+revisionSequence(r.Sequence())), r)
+	})
+}
+
+// GetRevisionAt returns the latest revision of the object at or before at
+func (db *RevisionStore) GetRevisionAt(objectType string, objectID string, at time.Time) (*types.Revision, error) {
+	prefix := []byte(historyPrefix(objectType, objectID))
+	target := append(append([]byte{}, prefix...), revisionSequence(types.RevisionSequence(at))...)
+
+	r := &types.Revision{}
+	err := db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(revisionBucket).Cursor()
+		k, v := c.Seek(target)
+		switch {
+		case k == nil:
+			k, v = c.Last()
+		case !bytes.Equal(k, target):
+			k, v = c.Prev()
+		}
+
+		if k == nil || !bytes.HasPrefix(k, prefix) || bytes.Contains(k[len(prefix):], []byte("/")) {
+			return inventory.ErrObjectNotFound
+		}
+		return json.Unmarshal(v, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRevisionsSince returns the revisions of the object at or after since,
+// oldest first
+func (db *RevisionStore) GetRevisionsSince(objectType string, objectID string, since time.Time) ([]*types.Revision, error) {
+	prefix := []byte(historyPrefix(objectType, objectID))
+	first := append(append([]byte{}, prefix...), revisionSequence(types.RevisionSequence(since))...)
+
+	revisions := make([]*types.Revision, 0)
+	err := db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(revisionBucket).Cursor()
+		for k, v := c.Seek(first); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if bytes.Contains(k[len(prefix):], []byte("/")) {
+				continue
+			}
+
+			r := &types.Revision{}
+			err := json.Unmarshal(v, r)
+			if err != nil {
+				return err
+			}
+			revisions = append(revisions, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
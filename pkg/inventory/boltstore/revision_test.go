@@ -0,0 +1,103 @@
+package boltstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestRevisions(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	created := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	system := &types.System{Name: "test", Roles: []string{"worker"}, LastUpdated: created}
+	err := inv.System().Create(system)
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	other := &types.System{Name: "test2", Roles: []string{"storage"}, LastUpdated: updated}
+	err = inv.System().Create(other)
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	system.Roles = []string{"master"}
+	system.LastUpdated = updated
+	err = inv.System().Update(system)
+	if err != nil {
+		t.Fatalf("unable to update system: %v", err)
+	}
+
+	err = inv.System().Delete(system)
+	if err != nil {
+		t.Fatalf("unable to delete system: %v", err)
+	}
+
+	_, err = inv.Revision().GetRevisionAt(types.HistoryObjectSystem, "test", created.Add(-time.Second))
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected no revision before the system was created, got: %v", err)
+	}
+
+	for at, role := range map[time.Time]string{created: "worker", updated.Add(-time.Nanosecond): "worker", updated: "master"} {
+		r, err := inv.Revision().GetRevisionAt(types.HistoryObjectSystem, "test", at)
+		if err != nil {
+			t.Fatalf("unable to get revision at %s: %v", at, err)
+		}
+
+		s := &types.System{}
+		err = r.Unmarshal(s)
+		if err != nil {
+			t.Fatalf("unable to unmarshal revision at %s: %v", at, err)
+		}
+
+		if r.Deleted || len(s.Roles) != 1 || s.Roles[0] != role {
+			t.Errorf("wrong revision at %s: %v", at, s)
+		}
+	}
+
+	r, err := inv.Revision().GetRevisionAt(types.HistoryObjectSystem, "test", time.Now().Add(time.Hour))
+	if err != nil || !r.Deleted {
+		t.Errorf("expected the latest revision to record the delete, got %v: %v", r, err)
+	}
+}
+
+func TestAppendRevision(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	at := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	for _, value := range []string{"first", "second"} {
+		r, err := types.NewRevision("test", "object", at, value)
+		if err != nil {
+			t.Fatalf("unable to create revision: %v", err)
+		}
+
+		err = inv.Revision().AppendRevision(r)
+		if err != nil {
+			t.Fatalf("unable to append revision: %v", err)
+		}
+	}
+
+	revisions, err := inv.Revision().GetRevisionsSince("test", "object", at)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("expected both revisions to be kept, got %v: %v", revisions, err)
+	}
+
+	for i, expected := range []string{"first", "second"} {
+		var value string
+		err = revisions[i].Unmarshal(&value)
+		if err != nil || value != expected {
+			t.Errorf("expected revision %d to be %s, got %s: %v", i, expected, value, err)
+		}
+	}
+
+	if later, err := inv.Revision().GetRevisionsSince("test", "object", at.Add(time.Second)); err != nil || len(later) != 0 {
+		t.Errorf("expected no revisions after the timestamp, got %v: %v", later, err)
+	}
+}
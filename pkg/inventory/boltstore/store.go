@@ -0,0 +1,325 @@
+// Package boltstore provides an inventory backend that persists records in an
+// embedded bbolt database, for sites that don't run in AWS.
+package boltstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ inventory.Store = &BoltStore{}
+
+var (
+	nodeBucket                  = []byte("inventory_nodes")
+	networkBucket               = []byte("inventory_networks")
+	systemBucket                = []byte("inventory_systems")
+	nodeMacIndexBucket          = []byte("inventory_node_mac_lookup")
+	ipReservationBucket         = []byte("inventory_ipam_ip")
+	ipReservationMacIndexBucket = []byte("inventory_ipam_ip_mac")
+	historyBucket               = []byte("inventory_history")
+	revisionBucket              = []byte("inventory_revisions")
+	webhookBucket               = []byte("inventory_webhooks")
+)
+
+// BoltStore is an inventory store backed by a bbolt database.  Objects are
+// stored as json.  A BoltStore bound to a writable transaction makes all of
+// its reads and writes in that transaction, so that a series of changes can
+// be committed or rolled back together.  Changes are recorded in history if
+// the store has an actor.
+type BoltStore struct {
+	db    *bolt.DB
+	tx    *bolt.Tx
+	actor string
+}
+
+// NewBoltStore creates a BoltStore
+func NewBoltStore(db *bolt.DB) *BoltStore {
+	return &BoltStore{db: db}
+}
+
+// InitializeBuckets creates any buckets missing from the database
+func (db *BoltStore) InitializeBuckets() error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{nodeBucket, networkBucket, systemBucket, nodeMacIndexBucket, ipReservationBucket, ipReservationMacIndexBucket, historyBucket, revisionBucket, webhookBucket} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// view runs fn in the bound transaction, or a new read-only transaction
+func (db *BoltStore) view(fn func(*bolt.Tx) error) error {
+	if db.tx != nil {
+		return fn(db.tx)
+	}
+	return db.db.View(fn)
+}
+
+// update runs fn in the bound transaction, or a new writable transaction
+func (db *BoltStore) update(fn func(*bolt.Tx) error) error {
+	if db.tx != nil {
+		return fn(db.tx)
+	}
+	return db.db.Update(fn)
+}
+
+// inTransaction calls fn with a store bound to a new writable transaction,
+// committing the transaction if fn succeeds
+func (db *BoltStore) inTransaction(fn func(*BoltStore) error) error {
+	return db.update(func(tx *bolt.Tx) error {
+		return fn(&BoltStore{db: db.db, tx: tx, actor: db.actor})
+	})
+}
+
+func put(b *bolt.Bucket, key []byte, obj interface{}) error {
+	value, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, value)
+}
+
+func get(b *bolt.Bucket, key []byte, out interface{}) error {
+	if b == nil {
+		return inventory.ErrObjectNotFound
+	}
+	value := b.Get(key)
+	if value == nil {
+		return inventory.ErrObjectNotFound
+	}
+	return json.Unmarshal(value, out)
+}
+
+// versioned is implemented by objects whose LastUpdated timestamp identifies
+// their revision
+type versioned interface {
+	Version() time.Time
+}
+
+// checkVersion returns ErrVersionMismatch unless the object stored at key is at
+// version.  The stored object is unmarshaled into current.
+func checkVersion(b *bolt.Bucket, key []byte, current versioned, version time.Time) error {
+	err := get(b, key, current)
+	if err != nil {
+		return err
+	}
+
+	if !current.Version().Equal(version) {
+		return inventory.ErrVersionMismatch
+	}
+	return nil
+}
+
+// unmarshalList unmarshals a list of json encoded objects into out, which must
+// be a pointer to a slice
+func unmarshalList(values [][]byte, out interface{}) error {
+	list := append([]byte("["), bytes.Join(values, []byte(","))...)
+	list = append(list, ']')
+	return json.Unmarshal(list, out)
+}
+
+func (db *BoltStore) put(bucket []byte, key string, obj interface{}) error {
+	return db.update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucket), []byte(key), obj)
+	})
+}
+
+// putIfVersion stores obj if the object stored at key is at version
+func (db *BoltStore) putIfVersion(bucket []byte, key string, obj interface{}, current versioned, version time.Time) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		err := checkVersion(b, []byte(key), current, version)
+		if err != nil {
+			return err
+		}
+		return put(b, []byte(key), obj)
+	})
+}
+
+// revisioned is implemented by objects that have revisions
+type revisioned interface {
+	versioned
+	ID() string
+}
+
+// write stores obj in bucket, or deletes it if deleteObj is set, along with a
+// revision of it and, if the store has an actor, a record of the change in
+// history, all in a single transaction.  The stored copy is read into
+// current, and if version isn't nil it must be at version.
+func (db *BoltStore) write(bucket []byte, objectType string, obj revisioned, deleteObj bool, current versioned, version *time.Time) error {
+	if obj.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+
+	return db.inTransaction(func(txdb *BoltStore) error {
+		b := txdb.tx.Bucket(bucket)
+		before, err := previous(b, []byte(obj.ID()), current, version)
+		if err != nil {
+			return err
+		}
+
+		if deleteObj {
+			err = b.Delete([]byte(obj.ID()))
+			if err != nil {
+				return err
+			}
+
+			err = inventory.PutDeletedRevision(txdb.Revision(), objectType, obj.ID())
+			if err != nil {
+				return err
+			}
+			return txdb.recordChange(objectType, obj.ID(), before, nil)
+		}
+
+		err = put(b, []byte(obj.ID()), obj)
+		if err != nil {
+			return err
+		}
+
+		err = inventory.PutRevision(txdb.Revision(), objectType, obj)
+		if err != nil {
+			return err
+		}
+		return txdb.recordChange(objectType, obj.ID(), before, obj)
+	})
+}
+
+// previous reads the object stored at key into current, returning nil if
+// there isn't one.  If version isn't nil the object must be stored, and be at
+// version.
+func previous(b *bolt.Bucket, key []byte, current versioned, version *time.Time) (interface{}, error) {
+	if version != nil {
+		err := checkVersion(b, key, current, *version)
+		if err != nil {
+			return nil, err
+		}
+		return current, nil
+	}
+
+	err := get(b, key, current)
+	if err == inventory.ErrObjectNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// recordChange appends a record of a change made by the store's actor to
+// history.  It's called in the transaction that makes the change, so the
+// record is only kept along with the change.
+func (db *BoltStore) recordChange(objectType string, objectID string, before interface{}, after interface{}) error {
+	record, err := inventory.NewChangeRecord(db.actor, objectType, objectID, before, after)
+	if err != nil || record == nil {
+		return err
+	}
+	return db.History().AppendHistory(record)
+}
+
+func (db *BoltStore) get(bucket []byte, key string, out interface{}) error {
+	return db.view(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(bucket), []byte(key), out)
+	})
+}
+
+func (db *BoltStore) getAll(bucket []byte, out interface{}) error {
+	return db.view(func(tx *bolt.Tx) error {
+		values := [][]byte{}
+		err := tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+			values = append(values, v)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return unmarshalList(values, out)
+	})
+}
+
+// list passes the values in bucket to add in key order, starting from the
+// pager's cursor, until the pager is done
+func (db *BoltStore) list(bucket []byte, pager *inventory.Pager, add func(value []byte) error) error {
+	return db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		for k, v := c.Seek([]byte(pager.After())); k != nil && !pager.Done(); k, v = c.Next() {
+			err := add(v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *BoltStore) exists(bucket []byte, key string) (bool, error) {
+	var found bool
+	err := db.view(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return found, err
+}
+
+func (db *BoltStore) delete(bucket []byte, key string) error {
+	return db.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	})
+}
+
+// deleteIfVersion deletes the object stored at key if it's at version
+func (db *BoltStore) deleteIfVersion(bucket []byte, key string, current versioned, version time.Time) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		err := checkVersion(b, []byte(key), current, version)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (db *BoltStore) Node() inventory.NodeStore {
+	return &NodeStore{BoltStore: db}
+}
+
+func (db *BoltStore) InventoryNode() inventory.InventoryNodeStore {
+	return inventory.NewInventoryNodeStore(db)
+}
+
+func (db *BoltStore) Network() inventory.NetworkStore {
+	return &NetworkStore{BoltStore: db}
+}
+
+func (db *BoltStore) System() inventory.SystemStore {
+	return &SystemStore{BoltStore: db}
+}
+
+func (db *BoltStore) IPReservation() inventory.IPReservationStore {
+	return &IPReservationStore{BoltStore: db}
+}
+
+func (db *BoltStore) History() inventory.HistoryStore {
+	return &HistoryStore{BoltStore: db}
+}
+
+func (db *BoltStore) Revision() inventory.RevisionStore {
+	return &RevisionStore{BoltStore: db}
+}
+
+func (db *BoltStore) Webhook() inventory.WebhookStore {
+	return &WebhookStore{BoltStore: db}
+}
+
+// WithActor returns a store for the same database that records the changes
+// made through it in history as changes made by actor
+func (db *BoltStore) WithActor(actor string) inventory.Store {
+	return &BoltStore{db: db.db, tx: db.tx, actor: actor}
+}
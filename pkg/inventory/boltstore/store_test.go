@@ -0,0 +1,70 @@
+package boltstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTestStore opens an initialized BoltStore in a temporary directory.  The
+// returned function closes the database and removes the directory.
+func openTestStore(t *testing.T) (*BoltStore, string, func()) {
+	dir, err := ioutil.TempDir("", "boltstore")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	path := filepath.Join(dir, "inventory.db")
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to open bolt database: %v", err)
+	}
+
+	inv := NewBoltStore(db)
+	err = inv.InitializeBuckets()
+	if err != nil {
+		t.Errorf("unable to initialize buckets: %v", err)
+	}
+
+	return inv, path, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	inv, path, cleanup := openTestStore(t)
+	defer cleanup()
+
+	err := inv.System().Create(&types.System{Name: "testsystem", Roles: []string{"worker"}})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	inv.db.Close()
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("unable to reopen bolt database: %v", err)
+	}
+	inv = NewBoltStore(db)
+
+	err = inv.InitializeBuckets()
+	if err != nil {
+		t.Errorf("unable to initialize buckets on existing database: %v", err)
+	}
+
+	system, err := inv.System().GetSystemByID("testsystem")
+	if err != nil {
+		t.Fatalf("unable to get system after reopening database: %v", err)
+	}
+
+	if len(system.Roles) != 1 || system.Roles[0] != "worker" {
+		t.Errorf("system not persisted correctly: %v", system)
+	}
+	db.Close()
+}
@@ -0,0 +1,147 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+type SystemStore struct {
+	*BoltStore
+}
+
+func (db *SystemStore) GetSystems() (map[string]*types.System, error) {
+	systemList := make([]*types.System, 0, 0)
+	err := db.getAll(systemBucket, &systemList)
+	if err != nil {
+		return nil, err
+	}
+	systems := make(map[string]*types.System)
+	for _, n := range systemList {
+		systems[n.ID()] = n
+	}
+	return systems, nil
+}
+
+// ListSystems returns a page of systems
+func (db *SystemStore) ListSystems(opts inventory.ListOptions) ([]*types.System, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	systems := make([]*types.System, 0)
+	err = db.list(systemBucket, pager, func(value []byte) error {
+		system := &types.System{}
+		err := json.Unmarshal(value, system)
+		if err != nil {
+			return err
+		}
+		if pager.Add(system.ID()) {
+			systems = append(systems, system)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return systems, pager.Next(), nil
+}
+
+func (db *SystemStore) GetSystemByID(id string) (*types.System, error) {
+	if id == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	system := &types.System{}
+	err := db.get(systemBucket, id, system)
+	if err != nil {
+		return nil, err
+	}
+
+	return system, nil
+}
+
+func (db *SystemStore) Exists(system *types.System) (bool, error) {
+	if system.ID() == "" {
+		return false, types.ErrKeyNotSet
+	}
+	return db.exists(systemBucket, system.ID())
+}
+
+func (db *SystemStore) Create(system *types.System) error {
+	return db.Update(system)
+}
+
+func (db *SystemStore) Update(system *types.System) error {
+	return db.write(system, false, nil)
+}
+
+func (db *SystemStore) Delete(system *types.System) error {
+	return db.write(system, true, nil)
+}
+
+// UpdateIfVersion updates the system if the stored copy is at version
+func (db *SystemStore) UpdateIfVersion(system *types.System, version time.Time) error {
+	return db.write(system, false, &version)
+}
+
+// DeleteIfVersion deletes the system if the stored copy is at version
+func (db *SystemStore) DeleteIfVersion(system *types.System, version time.Time) error {
+	return db.write(system, true, &version)
+}
+
+// write stores the system, or deletes it, along with its revision and history
+func (db *SystemStore) write(system *types.System, deleteSystem bool, version *time.Time) error {
+	return db.BoltStore.write(systemBucket, types.HistoryObjectSystem, system, deleteSystem, &types.System{}, version)
+}
+
+func (db *SystemStore) ObjDelete(obj interface{}) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(system)
+}
+
+func (db *SystemStore) ObjCreate(obj interface{}) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Create(system)
+}
+
+func (db *SystemStore) ObjUpdate(obj interface{}) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Update(system)
+}
+
+func (db *SystemStore) ObjExists(obj interface{}) (bool, error) {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(system)
+}
+
+func (db *SystemStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(system, version)
+}
+
+func (db *SystemStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(system, version)
+}
@@ -0,0 +1,155 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// WebhookStore manages webhook subscriptions
+type WebhookStore struct {
+	*BoltStore
+}
+
+func (db *WebhookStore) GetWebhooks() (map[string]*types.Webhook, error) {
+	hookList := make([]*types.Webhook, 0, 0)
+	err := db.getAll(webhookBucket, &hookList)
+	if err != nil {
+		return nil, err
+	}
+	hooks := make(map[string]*types.Webhook)
+	for _, n := range hookList {
+		hooks[n.ID()] = n
+	}
+	return hooks, nil
+}
+
+// ListWebhooks returns a page of webhooks
+func (db *WebhookStore) ListWebhooks(opts inventory.ListOptions) ([]*types.Webhook, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	hooks := make([]*types.Webhook, 0)
+	err = db.list(webhookBucket, pager, func(value []byte) error {
+		hook := &types.Webhook{}
+		err := json.Unmarshal(value, hook)
+		if err != nil {
+			return err
+		}
+		if pager.Add(hook.ID()) {
+			hooks = append(hooks, hook)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return hooks, pager.Next(), nil
+}
+
+func (db *WebhookStore) GetWebhookByID(id string) (*types.Webhook, error) {
+	if id == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	hook := &types.Webhook{}
+	err := db.get(webhookBucket, id, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+func (db *WebhookStore) Exists(hook *types.Webhook) (bool, error) {
+	if hook.ID() == "" {
+		return false, types.ErrKeyNotSet
+	}
+	return db.exists(webhookBucket, hook.ID())
+}
+
+func (db *WebhookStore) Create(hook *types.Webhook) error {
+	return db.Update(hook)
+}
+
+func (db *WebhookStore) Update(hook *types.Webhook) error {
+	if hook.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+	return db.put(webhookBucket, hook.ID(), hook)
+}
+
+func (db *WebhookStore) Delete(hook *types.Webhook) error {
+	if hook.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+	return db.delete(webhookBucket, hook.ID())
+}
+
+// UpdateIfVersion updates the webhook if the stored copy is at version
+func (db *WebhookStore) UpdateIfVersion(hook *types.Webhook, version time.Time) error {
+	if hook.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+	return db.putIfVersion(webhookBucket, hook.ID(), hook, &types.Webhook{}, version)
+}
+
+// DeleteIfVersion deletes the webhook if the stored copy is at version
+func (db *WebhookStore) DeleteIfVersion(hook *types.Webhook, version time.Time) error {
+	if hook.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+	return db.deleteIfVersion(webhookBucket, hook.ID(), &types.Webhook{}, version)
+}
+
+func (db *WebhookStore) ObjDelete(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(hook)
+}
+
+func (db *WebhookStore) ObjCreate(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Create(hook)
+}
+
+func (db *WebhookStore) ObjUpdate(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Update(hook)
+}
+
+func (db *WebhookStore) ObjExists(obj interface{}) (bool, error) {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(hook)
+}
+
+func (db *WebhookStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(hook, version)
+}
+
+func (db *WebhookStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(hook, version)
+}
@@ -0,0 +1,67 @@
+package boltstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/go-test/deep"
+)
+
+func TestWebhooks(t *testing.T) {
+	inv, _, cleanup := openTestStore(t)
+	defer cleanup()
+
+	created := time.Now().Truncate(time.Second)
+	hook := &types.Webhook{
+		Name:        "dhcp",
+		URL:         "https://dhcp.example.com/hook",
+		Secret:      "s3cret",
+		Kinds:       []string{types.HistoryObjectIPReservation},
+		Tags:        types.Tags{"dhcp"},
+		LastUpdated: created,
+	}
+
+	err := inv.Webhook().Create(hook)
+	if err != nil {
+		t.Fatalf("unable to create webhook: %v", err)
+	}
+
+	stored, err := inv.Webhook().GetWebhookByID("dhcp")
+	if err != nil {
+		t.Fatalf("unable to get webhook: %v", err)
+	}
+
+	if diff := deep.Equal(stored, hook); len(diff) > 0 {
+		t.Errorf("stored webhook doesn't match: %v", diff)
+	}
+
+	updated := *hook
+	updated.URL = "https://dhcp2.example.com/hook"
+	updated.LastUpdated = created.Add(time.Second)
+	err = inv.Webhook().UpdateIfVersion(&updated, created.Add(-time.Second))
+	if err != inventory.ErrVersionMismatch {
+		t.Errorf("expected stale update to fail, got: %v", err)
+	}
+
+	err = inv.Webhook().UpdateIfVersion(&updated, created)
+	if err != nil {
+		t.Fatalf("unable to update webhook: %v", err)
+	}
+
+	hooks, _, err := inv.Webhook().ListWebhooks(inventory.ListOptions{})
+	if err != nil || len(hooks) != 1 || hooks[0].URL != updated.URL {
+		t.Errorf("expected the updated webhook to be listed, got %v %v", hooks, err)
+	}
+
+	err = inv.Webhook().Delete(hook)
+	if err != nil {
+		t.Fatalf("unable to delete webhook: %v", err)
+	}
+
+	_, err = inv.Webhook().GetWebhookByID("dhcp")
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected deleted webhook not to be found, got: %v", err)
+	}
+}
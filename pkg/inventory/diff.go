@@ -0,0 +1,97 @@
+package inventory
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Diff renders the change as a line based diff of the yaml representations of
+// the existing and new objects.  Removed lines are prefixed with "-", added
+// lines with "+" and unchanged lines with a space.  LastUpdated is omitted as
+// it's ignored when comparing objects.
+func (c Change) Diff() (string, error) {
+	var before, after []string
+	var err error
+
+	if c.Action != ActionCreate {
+		before, err = yamlLines(c.Existing)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if c.Action != ActionDelete {
+		after, err = yamlLines(c.Object)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	b := &strings.Builder{}
+	for _, line := range diffLines(before, after) {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func yamlLines(obj interface{}) ([]string, error) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if strings.HasPrefix(line, "lastupdated:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// diffLines computes a minimal line diff using the longest common subsequence
+// of a and b.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, "-"+a[i])
+			i++
+		default:
+			result = append(result, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		result = append(result, "+"+b[j])
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package dynamodbclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// maxHistoryAttempts limits how often AppendHistory moves a record's timestamp
+// forward when it collides with an existing record
+const maxHistoryAttempts = 10
+
+// HistoryTable stores the history of each object under a partition named
+// after the object, sorted by the time of each change.  It's also used for
+// revisions, which are keyed in the same way.
+type HistoryTable struct {
+	Name string
+}
+
+func (t *HistoryTable) GetName() string {
+	return t.Name
+}
+
+func (t *HistoryTable) GetPartitionKeyName() string {
+	return "ObjectKey"
+}
+
+func (t *HistoryTable) GetKeySchema() []*dynamodb.KeySchemaElement {
+	return []*dynamodb.KeySchemaElement{
+		{
+			AttributeName: aws.String("ObjectKey"),
+			KeyType:       aws.String("HASH"),
+		},
+		{
+			AttributeName: aws.String("Sequence"),
+			KeyType:       aws.String("RANGE"),
+		},
+	}
+}
+
+func (t *HistoryTable) GetKeyAttributeDefinitions() []*dynamodb.AttributeDefinition {
+	return []*dynamodb.AttributeDefinition{
+		{
+			AttributeName: aws.String("ObjectKey"),
+			AttributeType: aws.String("S"),
+		},
+		{
+			AttributeName: aws.String("Sequence"),
+			AttributeType: aws.String("N"),
+		},
+	}
+}
+
+func (t *HistoryTable) GetCreateTableInput() *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: t.GetKeyAttributeDefinitions(),
+		KeySchema:            t.GetKeySchema(),
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+		TableName: aws.String(t.GetName()),
+	}
+}
+
+// sequenced is implemented by history records and revisions
+type sequenced interface {
+	Key() string
+	Sequence() int64
+}
+
+func (t *HistoryTable) GetKeyFrom(o interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	var objectType, objectID string
+	switch r := o.(type) {
+	case *types.HistoryRecord:
+		objectType, objectID = r.ObjectType, r.ObjectID
+	case *types.Revision:
+		objectType, objectID = r.ObjectType, r.ObjectID
+	default:
+		return nil, fmt.Errorf("unsupported object type: %T", o)
+	}
+
+	if objectType == "" || objectID == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	r := o.(sequenced)
+
+	return dynamodbattribute.MarshalMap(map[string]interface{}{"ObjectKey": r.Key(), "Sequence": r.Sequence()})
+}
+
+func (t *HistoryTable) GetItemQueryInputFrom(o interface{}) (*dynamodb.QueryInput, error) {
+	key, err := t.GetKeyFrom(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(t.GetName()),
+		KeyConditionExpression: aws.String("ObjectKey=:partitionkeyval AND #seq=:rangekeyval"),
+		ExpressionAttributeNames: map[string]*string{
+			"#seq": aws.String("Sequence"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":partitionkeyval": key["ObjectKey"],
+			":rangekeyval":     key["Sequence"],
+		},
+	}, nil
+}
+
+type HistoryStore struct {
+	*DynamoDBStore
+}
+
+// AppendHistory stores the record, moving its timestamp forward if it
+// collides with an existing record for the object
+func (db *HistoryStore) AppendHistory(r *types.HistoryRecord) error {
+	for attempt := 0; attempt < maxHistoryAttempts; attempt++ {
+		putItem, err := db.putItemInput(r)
+		if err != nil {
+			return err
+		}
+		putItem.SetConditionExpression("attribute_not_exists(ObjectKey)")
+
+		_, err = db.db.PutItem(putItem)
+		if !isConditionalCheckFailed(err) {
+			return err
+		}
+		r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+	}
+	return fmt.Errorf("unable to find a free sequence for the history of %s", r.Key())
+}
+
+// GetHistory returns a page of the changes to the object, oldest first.  As
+// with the other list methods, the last page may be empty.
+func (db *HistoryStore) GetHistory(objectType string, objectID string, opts inventory.ListOptions) ([]*types.HistoryRecord, string, error) {
+	table := db.tableMap.LookupTable(&types.HistoryRecord{})
+	if table == nil {
+		return nil, "", ErrInvalidObjectType
+	}
+
+	objectKey, err := dynamodbattribute.Marshal(types.HistoryKey(objectType, objectID))
+	if err != nil {
+		return nil, "", err
+	}
+
+	in := &dynamodb.QueryInput{
+		TableName:                 aws.String(table.GetName()),
+		KeyConditionExpression:    aws.String("ObjectKey=:partitionkeyval"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":partitionkeyval": objectKey},
+		ScanIndexForward:          aws.Bool(true),
+	}
+
+	if opts.Limit > 0 {
+		in.Limit = aws.Int64(int64(opts.Limit))
+	}
+
+	if opts.Next != "" {
+		position, err := inventory.DecodeCursor(opts.Next)
+		if err != nil {
+			return nil, "", err
+		}
+		err = json.Unmarshal(position, &in.ExclusiveStartKey)
+		if err != nil || len(in.ExclusiveStartKey) == 0 {
+			return nil, "", inventory.ErrInvalidCursor
+		}
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	next := ""
+	for {
+		results, err := db.db.Query(in)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to query dynamodb table %s: %v", table.GetName(), err)
+		}
+		items = append(items, results.Items...)
+
+		if results.LastEvaluatedKey == nil {
+			break
+		}
+
+		if opts.Limit > 0 && len(items) >= opts.Limit {
+			next, err = encodeKeyCursor(results.LastEvaluatedKey)
+			if err != nil {
+				return nil, "", err
+			}
+			break
+		}
+		in.ExclusiveStartKey = results.LastEvaluatedKey
+	}
+
+	records := make([]*types.HistoryRecord, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &records)
+	if err != nil {
+		return nil, "", err
+	}
+	return records, next, nil
+}
@@ -47,6 +47,31 @@ func (t *IPReservationTable) GetKeyAttributeDefinitions() []*dynamodb.AttributeD
 	}
 }
 
+func (t *IPReservationTable) GetGlobalSecondaryIndexes() []*dynamodb.GlobalSecondaryIndex {
+	return []*dynamodb.GlobalSecondaryIndex{
+		&dynamodb.GlobalSecondaryIndex{
+			IndexName: aws.String("mac"),
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(1),
+				WriteCapacityUnits: aws.Int64(1),
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{
+					AttributeName: aws.String("MAC"),
+					KeyType:       aws.String("HASH"),
+				},
+				{
+					AttributeName: aws.String("net"),
+					KeyType:       aws.String("RANGE"),
+				},
+			},
+			Projection: &dynamodb.Projection{
+				ProjectionType: aws.String("ALL"),
+			},
+		},
+	}
+}
+
 func (t *IPReservationTable) GetCreateTableInput() *dynamodb.CreateTableInput {
 	input := &dynamodb.CreateTableInput{
 		AttributeDefinitions: t.GetKeyAttributeDefinitions(),
@@ -55,29 +80,8 @@ func (t *IPReservationTable) GetCreateTableInput() *dynamodb.CreateTableInput {
 			ReadCapacityUnits:  aws.Int64(1),
 			WriteCapacityUnits: aws.Int64(1),
 		},
-		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
-			&dynamodb.GlobalSecondaryIndex{
-				IndexName: aws.String("mac"),
-				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(1),
-					WriteCapacityUnits: aws.Int64(1),
-				},
-				KeySchema: []*dynamodb.KeySchemaElement{
-					{
-						AttributeName: aws.String("MAC"),
-						KeyType:       aws.String("HASH"),
-					},
-					{
-						AttributeName: aws.String("net"),
-						KeyType:       aws.String("RANGE"),
-					},
-				},
-				Projection: &dynamodb.Projection{
-					ProjectionType: aws.String("ALL"),
-				},
-			},
-		},
-		TableName: aws.String(t.GetName()),
+		GlobalSecondaryIndexes: t.GetGlobalSecondaryIndexes(),
+		TableName:              aws.String(t.GetName()),
 	}
 
 	return input
@@ -2,18 +2,52 @@ package dynamodbclient
 
 import (
 	"fmt"
-	"math/rand"
 	"net"
 	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/azenk/iputils"
 )
 
+// Conditions used when writing reservations.  A new reservation may replace
+// one that has expired or been deleted, while updates only apply to current
+// reservations.
+const (
+	reservationCreateCondition  = "attribute_not_exists(net) and attribute_not_exists(ip)"
+	reservationReplaceCondition = "#end <= :now or Deleted <= :now"
+	reservationUpdateCondition  = "attribute_exists(ip) and MAC = :mac and (attribute_not_exists(#end) or #end > :now) and attribute_not_exists(Deleted)"
+	reservationDeleteCondition  = "attribute_exists(ip) and (attribute_not_exists(#end) or #end > :now) and attribute_not_exists(Deleted)"
+	reservationPurgeCondition   = "#end <= :now"
+)
+
+// maxReservationWriteAttempts limits how often CreateIPReservation plans its
+// transaction again after it's canceled
+const maxReservationWriteAttempts = 5
+
+// reservationConditionNames holds the placeholder for End, which is a reserved
+// word in dynamodb expressions
+var reservationConditionNames = map[string]*string{"#end": aws.String("End")}
+
+// reservationConditionValues returns the values used by the reservation
+// conditions.  End is stored as a unix timestamp.
+func reservationConditionValues(r *types.IPReservation, now time.Time) (map[string]*dynamodb.AttributeValue, error) {
+	values := map[string]*dynamodb.AttributeValue{}
+	var err error
+	values[":now"], err = dynamodbattribute.Marshal(now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	values[":mac"], err = dynamodbattribute.Marshal(r.MAC.String())
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
 type IPReservationStore struct {
 	*DynamoDBStore
 }
@@ -23,7 +57,14 @@ func (db *IPReservationStore) GetIPReservation(ipNet *net.IPNet) (*types.IPReser
 		IP: ipNet,
 	}
 	err := db.get(r)
-	return r, err
+	if err != nil {
+		return r, err
+	}
+
+	if r.Ended(time.Now()) {
+		return nil, ErrObjectNotFound
+	}
+	return r, nil
 }
 
 func (db *IPReservationStore) GetAllIPReservations() (types.IPReservationList, error) {
@@ -32,10 +73,35 @@ func (db *IPReservationStore) GetAllIPReservations() (types.IPReservationList, e
 	if err != nil {
 		return nil, fmt.Errorf("error getting all ip reservations: %v", err)
 	}
-	return reservations, nil
+	return reservations.Active(time.Now()), nil
 }
 
 func (db *IPReservationStore) GetIPReservationsByMac(mac net.HardwareAddr) (types.IPReservationList, error) {
+	reservations, err := db.getIPReservationsByMac(mac)
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// GetIPReservationsByMacAt returns the reservations for the mac that were valid
+// at the time specified, including those that have since been deleted or
+// replaced
+func (db *IPReservationStore) GetIPReservationsByMacAt(mac net.HardwareAddr, at time.Time) (types.IPReservationList, error) {
+	if len(mac) == 0 {
+		return types.IPReservationList{}, nil
+	}
+
+	reservations, err := db.getIPReservationsByMac(mac)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.AddArchivedIPReservations(db.Revision(), reservations, mac, at)
+}
+
+// getIPReservationsByMac returns every reservation for the mac, including
+// those that have ended
+func (db *IPReservationStore) getIPReservationsByMac(mac net.HardwareAddr) (types.IPReservationList, error) {
 	if len(mac) == 0 {
 		return types.IPReservationList{}, nil
 	}
@@ -62,18 +128,32 @@ func (db *IPReservationStore) GetIPReservationsByMac(mac net.HardwareAddr) (type
 
 	q.ScanIndexForward = aws.Bool(false)
 
-	results, err := db.db.Query(q)
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = db.db.QueryPages(q, func(results *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, results.Items...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	reservations := types.IPReservationList{}
-	err = dynamodbattribute.UnmarshalListOfMaps(results.Items, &reservations)
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &reservations)
 	return reservations, err
 }
 
 // GetIPReservations returns all current reservations in the specified subnet
 func (db *IPReservationStore) GetIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
+	reservations, err := db.GetStoredIPReservations(ipNet)
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// GetStoredIPReservations returns every reservation stored in the specified
+// subnet, including those that have ended
+func (db *IPReservationStore) GetStoredIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
 	table := db.tableMap.LookupTable(&types.IPReservation{})
 	if table == nil {
 		return nil, fmt.Errorf("No table found for object of type %T", &types.IPReservation{})
@@ -97,16 +177,21 @@ func (db *IPReservationStore) GetIPReservations(ipNet *net.IPNet) (types.IPReser
 		ExpressionAttributeValues: queryValues,
 	}
 
-	results, err := db.db.Query(q)
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = db.db.QueryPages(q, func(results *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, results.Items...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	out := make(types.IPReservationList, len(results.Items))
-
-	err = dynamodbattribute.UnmarshalListOfMaps(results.Items, &out)
-
-	return out, err
+	out := make(types.IPReservationList, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (db *IPReservationStore) GetExistingIPReservationInSubnet(subnetCidr *net.IPNet, mac net.HardwareAddr) (*types.IPReservation, error) {
@@ -146,149 +231,240 @@ func (db *IPReservationStore) GetExistingIPReservationInSubnet(subnetCidr *net.I
 		return nil, err
 	}
 
-	if len(results.Items) == 0 {
+	reservations := types.IPReservationList{}
+	err = dynamodbattribute.UnmarshalListOfMaps(results.Items, &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations = reservations.Active(time.Now())
+	if len(reservations) == 0 {
 		return nil, ErrObjectNotFound
 	}
 
-	if len(results.Items) > 1 {
-		return nil, fmt.Errorf("unable to lookup exactly one item: found %d matching", len(results.Items))
+	if len(reservations) > 1 {
+		return nil, fmt.Errorf("unable to lookup exactly one item: found %d matching", len(reservations))
 	}
 
-	reservation := &types.IPReservation{}
-	err = dynamodbattribute.UnmarshalMap(results.Items[0], reservation)
-	return reservation, err
+	return reservations[0], nil
 }
 
 func (db *IPReservationStore) CreateRandomIPReservation(r *types.IPReservation, subnet *types.Subnet) (*types.IPReservation, error) {
-	maxCount := 10
-	reservation := *r
-	for count := 0; count < maxCount; count++ {
-		existingReservations, err := db.GetIPReservations(subnet.Cidr)
+	return inventory.CreateRandomIPReservation(db, r, subnet)
+}
+
+// CreateIPReservation creates the reservation only if no current reservation
+// exists for the address.  A reservation that has ended is replaced, and
+// archived in the same transaction, along with the history record.
+func (db *IPReservationStore) CreateIPReservation(r *types.IPReservation) error {
+	for attempt := 0; attempt < maxReservationWriteAttempts; attempt++ {
+		tx := newTransaction(db.DynamoDBStore)
+		err := (&transactionIPReservationStore{IPReservationStore: db, tx: tx}).CreateIPReservation(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		startOffset, ipLength := subnet.Cidr.Mask.Size()
-		if subnet.Cidr.IP.To4() != nil && len(existingReservations) >= (1<<uint(ipLength-startOffset)-2) {
-			return nil, fmt.Errorf("this subnet is full, cannot allocate an address")
+		err = tx.record(types.HistoryObjectIPReservation, r.IP.IP.String(), nil, r)
+		if err != nil {
+			return err
 		}
 
-		rand.Seed(time.Now().UnixNano())
-
-		reservation.IP = &net.IPNet{Mask: subnet.Cidr.Mask}
-		if reservation.Start == nil {
-			start := time.Now()
-			reservation.Start = &start
+		err = tx.commit()
+		if err != errTransactionCanceled {
+			return err
 		}
+	}
+	return ErrAlreadyExists
+}
 
-		// generate random IP in the subnet
-		// Check to see if reservation list contains a reservation for it
-		// if it's in the list of reserved addresses, try again
-		// if it's not in the list of addresses, try to reserve it
-		// if reservation fails, retry up to N times?  or just error?
-		for {
-			// choose IP at random until we find a free one
-			randomHostPart := rand.Uint64()
-			candidateIP, err := iputils.SetBits(subnet.Cidr.IP, randomHostPart, uint(startOffset), uint(ipLength-startOffset))
-			if err != nil {
-				return nil, fmt.Errorf("unexpected error building ip: %v", err)
-			}
-			reservation.IP.IP = candidateIP
-
-			if !reservation.Validate() || existingReservations.Contains(candidateIP) {
-				continue
-			}
-
-			err = db.CreateIPReservation(&reservation)
-			if err != nil && err == ErrAlreadyExists {
-				break
-			}
-			return &reservation, err
-		}
+// storedReservation returns the reservation stored for the address, whether or
+// not it has ended, or nil if there isn't one
+func (db *IPReservationStore) storedReservation(ipNet *net.IPNet) (*types.IPReservation, error) {
+	r := &types.IPReservation{IP: ipNet}
+	err := db.get(r)
+	if err == ErrObjectNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("retry limit exceeded: giving up on reserving an ip for %v", r)
+	return r, nil
 }
 
-func (db *IPReservationStore) CreateIPReservation(r *types.IPReservation) error {
-	table := db.tableMap.LookupTable(r)
-	if table == nil {
-		return fmt.Errorf("No table found for object of type %T", r)
+// UpdateIPReservation replaces the current reservation for the address, only
+// if the mac matches.  The history record is written in the same transaction.
+func (db *IPReservationStore) UpdateIPReservation(r *types.IPReservation) error {
+	var existing *types.IPReservation
+	if db.actor != "" {
+		var err error
+		existing, err = db.GetIPReservation(r.IP)
+		if err == ErrObjectNotFound {
+			return ErrUpdateConflict
+		} else if err != nil {
+			return err
+		}
 	}
-	putItem := &dynamodb.PutItemInput{}
-	putItem.SetTableName(table.GetName())
-	item, err := dynamodbattribute.MarshalMap(r)
+
+	tx := newTransaction(db.DynamoDBStore)
+	err := (&transactionIPReservationStore{IPReservationStore: db, tx: tx}).UpdateIPReservation(r)
 	if err != nil {
 		return err
 	}
-	putItem.Item = item
 
-	keyMap, err := table.GetKeyFrom(r)
+	err = tx.record(types.HistoryObjectIPReservation, r.IP.IP.String(), existing, r)
 	if err != nil {
 		return err
 	}
 
-	for k, v := range keyMap {
-		putItem.Item[k] = v
+	err = tx.commit()
+	if err == errTransactionCanceled {
+		return ErrUpdateConflict
 	}
-
-	putItem.SetConditionExpression("attribute_not_exists(net) and attribute_not_exists(ip)")
-	_, err = db.db.PutItem(putItem)
 	return err
 }
 
-func (db *IPReservationStore) UpdateIPReservation(r *types.IPReservation) error {
-	table := db.tableMap.LookupTable(r)
-	if table == nil {
-		return fmt.Errorf("No table found for object of type %T", r)
+func (db *IPReservationStore) CreateOrUpdateIPReservation(r *types.IPReservation) error {
+	err := db.UpdateIPReservation(r)
+	if err == nil {
+		return nil
 	}
-	putItem := &dynamodb.PutItemInput{}
-	putItem.SetTableName(table.GetName())
-	item, err := dynamodbattribute.MarshalMap(r)
-	if err != nil {
+
+	if err != ErrUpdateConflict {
+		return err
+	}
+
+	return db.CreateIPReservation(r)
+}
+
+func (db *IPReservationStore) Exists(r *types.IPReservation) (bool, error) {
+	_, err := db.GetIPReservation(r.IP)
+	switch err {
+	case nil:
+		return true, nil
+	case ErrObjectNotFound:
+		return false, nil
+	}
+	return false, err
+}
+
+// Delete marks the reservation as deleted, keeping it so that it can still be
+// looked up as it was in the past.  The history record is written in the same
+// transaction.
+func (db *IPReservationStore) Delete(r *types.IPReservation) error {
+	existing, err := db.GetIPReservation(r.IP)
+	if err == ErrObjectNotFound {
+		return nil
+	} else if err != nil {
 		return err
 	}
-	putItem.Item = item
 
-	keyMap, err := table.GetKeyFrom(r)
+	now := time.Now()
+	nowValue, err := dynamodbattribute.Marshal(now.Unix())
 	if err != nil {
 		return err
 	}
 
-	for k, v := range keyMap {
-		putItem.Item[k] = v
+	deleted := *existing
+	deleted.Deleted = &now
+
+	tx := newTransaction(db.DynamoDBStore)
+	err = tx.put(&deleted, reservationDeleteCondition, reservationConditionNames, map[string]*dynamodb.AttributeValue{":now": nowValue})
+	if err != nil {
+		return err
 	}
 
-	putItem.SetConditionExpression("net = :net and ip = :ip and MAC = :mac")
-	macAddress, err := dynamodbattribute.Marshal(r.MAC.String())
+	err = tx.record(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
 	if err != nil {
 		return err
 	}
-	keyAttributes, err := table.GetKeyFrom(r)
 
-	putItem.SetExpressionAttributeValues(map[string]*dynamodb.AttributeValue{":mac": macAddress, ":net": keyAttributes["net"], ":ip": keyAttributes["ip"]})
-	_, err = db.db.PutItem(putItem)
+	err = tx.commit()
+	if err == errTransactionCanceled {
+		// there's no current reservation to delete
+		return nil
+	}
 	return err
 }
 
-func (db *IPReservationStore) CreateOrUpdateIPReservation(r *types.IPReservation) error {
-	err := db.UpdateIPReservation(r)
-	if err == nil {
-		return nil
+// GetExpiredIPReservations returns the reservations that expired before the
+// time specified, including those that were deleted
+func (db *IPReservationStore) GetExpiredIPReservations(at time.Time) (types.IPReservationList, error) {
+	table := db.tableMap.LookupTable(&types.IPReservation{})
+	if table == nil {
+		return nil, ErrInvalidObjectType
 	}
 
-	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeConditionalCheckFailedException {
-		return err
+	atValue, err := dynamodbattribute.Marshal(at.Unix())
+	if err != nil {
+		return nil, err
 	}
 
-	return db.CreateIPReservation(r)
-}
+	in := &dynamodb.ScanInput{
+		TableName:                 aws.String(table.GetName()),
+		FilterExpression:          aws.String("#end <= :at"),
+		ExpressionAttributeNames:  reservationConditionNames,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":at": atValue},
+	}
 
-func (db *IPReservationStore) Exists(r *types.IPReservation) (bool, error) {
-	return db.DynamoDBStore.exists(r)
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = db.db.ScanPages(in, func(results *dynamodb.ScanOutput, lastPage bool) bool {
+		items = append(items, results.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan for expired ip reservations: %v", err)
+	}
+
+	reservations := types.IPReservationList{}
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &reservations)
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Expired(at), nil
 }
 
-func (db *IPReservationStore) Delete(r *types.IPReservation) error {
-	return db.DynamoDBStore.delete(r)
+// PurgeIPReservation removes the reservation if it has expired, archiving it in
+// the same transaction so that GetIPReservationsByMacAt can still find it, and
+// records its removal in history unless it was deleted
+func (db *IPReservationStore) PurgeIPReservation(r *types.IPReservation) error {
+	existing, err := db.storedReservation(r.IP)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	now := time.Now()
+	if !existing.Expired(now) {
+		return ErrUpdateConflict
+	}
+
+	nowValue, err := dynamodbattribute.Marshal(now.Unix())
+	if err != nil {
+		return err
+	}
+
+	tx := newTransaction(db.DynamoDBStore)
+	err = tx.delete(existing, reservationPurgeCondition, reservationConditionNames, map[string]*dynamodb.AttributeValue{":now": nowValue})
+	if err != nil {
+		return err
+	}
+
+	err = (&transactionIPReservationStore{IPReservationStore: db, tx: tx}).archive(existing)
+	if err != nil {
+		return err
+	}
+
+	if existing.Deleted == nil {
+		// deleted reservations were recorded when they were deleted
+		err = tx.record(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.commit()
+	if err == errTransactionCanceled {
+		return ErrUpdateConflict
+	}
+	return err
 }
 
 func (db *IPReservationStore) ObjExists(obj interface{}) (bool, error) {
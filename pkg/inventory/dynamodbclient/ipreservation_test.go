@@ -116,3 +116,144 @@ func TestGetIPReservationInSubnetByMAC(t *testing.T) {
 	}
 
 }
+
+func TestIPReservationSoftDelete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Fatalf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Fatalf("unable to initialize tables: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	otherMac, _ := net.ParseMAC("00:01:02:03:04:06")
+	r := types.NewStaticIPReservation()
+	r.IP = &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}
+	r.MAC = mac
+	start := time.Now().Add(-time.Hour)
+	r.Start = &start
+
+	err = inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = inv.IPReservation().Delete(r)
+	if err != nil {
+		t.Fatalf("unable to delete reservation: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	_, err = inv.IPReservation().GetIPReservation(r.IP)
+	if err != ErrObjectNotFound {
+		t.Errorf("expected deleted reservation not to be found, got: %v", err)
+	}
+
+	if exists, err := inv.IPReservation().Exists(r); exists || err != nil {
+		t.Errorf("expected deleted reservation not to exist: %v", err)
+	}
+
+	if current, err := inv.IPReservation().GetIPReservationsByMac(mac); len(current) != 0 || err != nil {
+		t.Errorf("expected no current reservations for the mac, got %d: %v", len(current), err)
+	}
+
+	if stored, err := inv.IPReservation().GetStoredIPReservations(r.IP); err != nil || len(stored) != 1 {
+		t.Errorf("expected the deleted reservation to still be stored, got %v: %v", stored, err)
+	}
+
+	past, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(past) != 1 || !past[0].IP.IP.Equal(r.IP.IP) {
+		t.Errorf("expected to find the deleted reservation as it was, got %v: %v", past, err)
+	}
+
+	err = inv.IPReservation().UpdateIPReservation(r)
+	if err != ErrUpdateConflict {
+		t.Errorf("expected update of deleted reservation to conflict, got: %v", err)
+	}
+
+	replacement := types.NewStaticIPReservation()
+	replacement.IP = r.IP
+	replacement.MAC = otherMac
+	err = inv.IPReservation().CreateIPReservation(replacement)
+	if err != nil {
+		t.Fatalf("unable to replace deleted reservation: %v", err)
+	}
+
+	result, err := inv.IPReservation().GetIPReservation(r.IP)
+	if err != nil || result.MAC.String() != otherMac.String() {
+		t.Errorf("expected the replacement reservation, got %v: %v", result, err)
+	}
+
+	replaced, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(replaced) != 1 || !replaced[0].IP.IP.Equal(r.IP.IP) || replaced[0].MAC.String() != mac.String() {
+		t.Errorf("expected to find the replaced reservation as it was, got %v: %v", replaced, err)
+	}
+}
+
+func TestIPReservationPurge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Fatalf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Fatalf("unable to initialize tables: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	start := time.Now().Add(-2 * time.Hour)
+	end := time.Now().Add(-time.Hour)
+	r := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}, MAC: mac, Start: &start, End: &end}
+
+	err = inv.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	expired, err := inv.IPReservation().GetExpiredIPReservations(time.Now())
+	if err != nil || len(expired) != 1 {
+		t.Fatalf("expected to find the expired reservation, got %v: %v", expired, err)
+	}
+
+	err = inv.IPReservation().PurgeIPReservation(expired[0])
+	if err != nil {
+		t.Fatalf("unable to purge reservation: %v", err)
+	}
+
+	if remaining, err := inv.IPReservation().GetExpiredIPReservations(time.Now()); err != nil || len(remaining) != 0 {
+		t.Errorf("expected the purged reservation to be removed, got %v: %v", remaining, err)
+	}
+
+	past, err := inv.IPReservation().GetIPReservationsByMacAt(mac, start.Add(time.Minute))
+	if err != nil || len(past) != 1 || !past[0].IP.IP.Equal(r.IP.IP) {
+		t.Errorf("expected to find the purged reservation as it was, got %v: %v", past, err)
+	}
+
+	current := types.NewStaticIPReservation()
+	current.IP = r.IP
+	err = inv.IPReservation().CreateIPReservation(current)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	err = inv.IPReservation().PurgeIPReservation(current)
+	if err != ErrUpdateConflict {
+		t.Errorf("expected purge of a current reservation to conflict, got: %v", err)
+	}
+}
@@ -2,8 +2,12 @@ package dynamodbclient
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 type NetworkStore struct {
@@ -23,6 +27,24 @@ func (db *NetworkStore) GetNetworks() (map[string]*types.Network, error) {
 	return networks, nil
 }
 
+// ListNetworks returns a page of networks
+func (db *NetworkStore) ListNetworks(opts inventory.ListOptions) ([]*types.Network, string, error) {
+	networks := make([]*types.Network, 0)
+	next, err := db.listPage(&networks, opts, func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		network := &types.Network{}
+		err := dynamodbattribute.UnmarshalMap(item, network)
+		if err != nil {
+			return false, err
+		}
+		networks = append(networks, network)
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing networks: %v", err)
+	}
+	return networks, next, nil
+}
+
 func (db *NetworkStore) GetNetworkByID(id string) (*types.Network, error) {
 	network := &types.Network{}
 	network.Name = id
@@ -42,16 +64,39 @@ func (db *NetworkStore) Exists(network *types.Network) (bool, error) {
 }
 
 func (db *NetworkStore) Create(network *types.Network) error {
-	return db.DynamoDBStore.create(network)
+	return db.write(network, false, nil)
 }
 
 func (db *NetworkStore) Update(network *types.Network) error {
-	return db.DynamoDBStore.update(network)
-
+	return db.write(network, false, nil)
 }
 
 func (db *NetworkStore) Delete(network *types.Network) error {
-	return db.DynamoDBStore.delete(network)
+	return db.write(network, true, nil)
+}
+
+// UpdateIfVersion updates the network if the stored copy is at version
+func (db *NetworkStore) UpdateIfVersion(network *types.Network, version time.Time) error {
+	return db.write(network, false, &version)
+}
+
+// DeleteIfVersion deletes the network if the stored copy is at version
+func (db *NetworkStore) DeleteIfVersion(network *types.Network, version time.Time) error {
+	return db.write(network, true, &version)
+}
+
+// write puts the network, or deletes it, and stores its revision in the same
+// transaction
+func (db *NetworkStore) write(network *types.Network, deleteNetwork bool, version *time.Time) error {
+	revision := types.NewDeletedRevision(types.HistoryObjectNetwork, network.ID(), time.Now())
+	if !deleteNetwork {
+		var err error
+		revision, err = inventory.NewObjectRevision(types.HistoryObjectNetwork, network)
+		if err != nil {
+			return err
+		}
+	}
+	return db.writeWithRevision(network, revision, deleteNetwork, &types.Network{Name: network.ID()}, version)
 }
 
 func (db *NetworkStore) ObjDelete(obj interface{}) error {
@@ -85,3 +130,19 @@ func (db *NetworkStore) ObjExists(obj interface{}) (bool, error) {
 	}
 	return db.Exists(network)
 }
+
+func (db *NetworkStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(network, version)
+}
+
+func (db *NetworkStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(network, version)
+}
@@ -5,7 +5,10 @@ import (
 	"net"
 	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 type NodeStore struct {
@@ -25,6 +28,27 @@ func (db *NodeStore) GetNodes() (map[string]*types.Node, error) {
 	return nodes, nil
 }
 
+// ListNodes returns a page of the nodes matching the filter in opts
+func (db *NodeStore) ListNodes(opts inventory.ListOptions) ([]*types.Node, string, error) {
+	nodes := make([]*types.Node, 0)
+	next, err := db.listPage(&nodes, opts, func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		node := &types.Node{}
+		err := dynamodbattribute.UnmarshalMap(item, node)
+		if err != nil {
+			return false, err
+		}
+		if !opts.Filter.Match(node) {
+			return false, nil
+		}
+		nodes = append(nodes, node)
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing nodes: %v", err)
+	}
+	return nodes, next, nil
+}
+
 func (db *NodeStore) GetNodeByID(id string) (*types.Node, error) {
 	node := &types.Node{}
 	node.InventoryID = id
@@ -44,126 +68,12 @@ func (db *NodeStore) GetNodeByMAC(mac net.HardwareAddr) (*types.Node, error) {
 }
 
 func (db *NodeStore) Create(newNode *types.Node) error {
-
-	err := db.reconcileIPs(newNode)
-	if err != nil {
-		return err
-	}
-
-	err = db.reconcileMacIndex(newNode)
-	if err != nil {
-		return nil
-	}
-
-	return db.DynamoDBStore.create(newNode)
-}
-
-func (db *NodeStore) reconcileIPs(node *types.Node) error {
-	// For a given node, make sure that the list of IPs on each interface is valid and fully populated
-	existingNode, err := db.GetNodeByID(node.ID())
-	if err != nil && err != ErrObjectNotFound {
-		return fmt.Errorf("a node with this id exists already, but we can't get it for comparison: %v", err)
-	}
-
-	macsToRemove := make(map[string]net.HardwareAddr)
-
-	if existingNode != nil {
-		for _, iface := range existingNode.Networks {
-			for _, mac := range iface.NICs {
-				macsToRemove[mac.String()] = mac
-			}
-		}
-	}
-
-	for netname, iface := range node.Networks {
-		// TODO: do we support static IPs without macs?
-		if iface.NICs == nil || len(iface.NICs) == 0 {
-			// if we don't have a mac, we can't reserve any IPs
-			continue
-		}
-
-		// Get network
-		network, err := db.Network().GetNetworkByID(netname)
-		if err != nil {
-			return fmt.Errorf("unable to get network named '%s': %v", netname, err)
-		}
-
-		// For each subnet with an allocation strategy, make sure theres a valid static IP reservation
-		for _, subnet := range network.Subnets {
-			for _, mac := range iface.NICs {
-				// this NIC still exists, we don't need to remove
-				if _, ok := macsToRemove[mac.String()]; ok {
-					delete(macsToRemove, mac.String())
-				}
-			}
-
-			if !subnet.StaticAllocationEnabled() {
-				continue
-			}
-
-			existingReservations := types.IPReservationList{}
-			for _, mac := range iface.NICs {
-				reservation, err := db.IPReservation().GetExistingIPReservationInSubnet(subnet.Cidr, mac)
-				if err != nil && err != ErrObjectNotFound {
-					return fmt.Errorf("unable to get reservation for nic: %v", err)
-				}
-				if reservation != nil {
-					existingReservations = append(existingReservations, reservation)
-				}
-			}
-			// If allocation is enabled for this subnet, then we should have one static reservation.
-			// If allocation is disabled, a static reservation may exist, but we will not create one.
-			// Check for existing reservations
-			// If static reservation exists for any mac on this iface, continue to next interface
-			// If we find a valid dynamic reservation for any mac on the iface make it static
-			// otherwise have the allocator choose an address and create a static reservation
-			existingStatic := existingReservations.Static().ValidAt(time.Now())
-			if len(existingStatic) > 0 {
-				continue
-			}
-
-			// try to upgrade a dynamic reservation
-			existingDynamic := existingReservations.Dynamic().ValidAt(time.Now())
-			if len(existingDynamic) > 0 {
-				reservation := existingDynamic[0]
-				reservation.End = nil
-				reservation.Metadata["hostname"] = node.Hostname()
-				reservation.Metadata["nodeid"] = node.ID()
-				reservation.Metadata["domain"] = network.Domain
-				err = db.IPReservation().UpdateIPReservation(reservation)
-				if err != nil {
-					return fmt.Errorf("unable to upgrade dynamic reservation: %v", err)
-				}
-				continue
-			}
-
-			// we don't have a static or dynamic reservation, and allocation is enabled
-			// allocate an IP and create a reservation
-			newReservation := types.NewStaticIPReservation()
-			newReservation.MAC = iface.NICs[0]
-			newReservation.Metadata["hostname"] = node.Hostname()
-			newReservation.Metadata["nodeid"] = node.ID()
-			newReservation.Metadata["domain"] = network.Domain
-			_, err := db.IPReservation().CreateRandomIPReservation(newReservation, subnet)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	for _, mac := range macsToRemove {
-		reservations, err := db.IPReservation().GetIPReservationsByMac(mac)
-		for _, reservation := range reservations.Static() {
-			err = db.IPReservation().Delete(reservation)
-			if err != nil {
-				return fmt.Errorf("unable to delete reservation: %v", err)
-			}
-		}
-	}
-	return nil
+	return db.write(newNode, false, nil)
 }
 
-func (db *NodeStore) reconcileMacIndex(node *types.Node) error {
+// reconcileMacIndex adds writes to tx that point every mac attached to the
+// node at the node, and remove entries for macs that are no longer attached
+func (db *NodeStore) reconcileMacIndex(tx *transaction, node *types.Node) error {
 	existingMacIndices, err := db.nodeMacIndex().GetMacIndexEntriesByNodeID(node.ID())
 	if err != nil {
 		return fmt.Errorf("unable to lookup existing mac index entries: %v", err)
@@ -182,14 +92,14 @@ func (db *NodeStore) reconcileMacIndex(node *types.Node) error {
 			delete(newMacs, oldMacIndex.Mac.String())
 			continue
 		}
-		err := db.nodeMacIndex().Delete(oldMacIndex)
+		err := tx.delete(oldMacIndex, "", nil, nil)
 		if err != nil {
 			return fmt.Errorf("unable to delete previous mac index entry: %v", err)
 		}
 	}
 
 	for _, mac := range newMacs {
-		err = db.nodeMacIndex().Create(&NodeMacIndexEntry{Mac: mac, LastUpdated: node.LastUpdated, NodeID: node.ID()})
+		err = tx.put(&NodeMacIndexEntry{Mac: mac, LastUpdated: node.LastUpdated, NodeID: node.ID()}, "", nil, nil)
 		if err != nil {
 			return fmt.Errorf("unable to create mac index entry: %v", err)
 		}
@@ -198,17 +108,12 @@ func (db *NodeStore) reconcileMacIndex(node *types.Node) error {
 }
 
 func (db *NodeStore) Update(updatedNode *types.Node) error {
-	err := db.reconcileIPs(updatedNode)
-	if err != nil {
-		return err
-	}
-
-	err = db.reconcileMacIndex(updatedNode)
-	if err != nil {
-		return nil
-	}
+	return db.write(updatedNode, false, nil)
+}
 
-	return db.DynamoDBStore.update(updatedNode)
+// UpdateIfVersion updates the node if the stored copy is at version
+func (db *NodeStore) UpdateIfVersion(node *types.Node, version time.Time) error {
+	return db.write(node, false, &version)
 }
 
 func (db *NodeStore) Exists(node *types.Node) (bool, error) {
@@ -217,16 +122,75 @@ func (db *NodeStore) Exists(node *types.Node) (bool, error) {
 
 func (db *NodeStore) Delete(node *types.Node) error {
 	node.Networks = types.NICInfoMap{}
-	err := db.reconcileIPs(node)
-	if err != nil {
-		return err
-	}
+	return db.write(node, true, nil)
+}
 
-	err = db.reconcileMacIndex(node)
-	if err != nil {
-		return nil
+// DeleteIfVersion deletes the node if the stored copy is at version
+func (db *NodeStore) DeleteIfVersion(node *types.Node, version time.Time) error {
+	node.Networks = types.NICInfoMap{}
+	return db.write(node, true, &version)
+}
+
+// write reconciles the node's static ip reservations and mac index entries,
+// and commits them along with the node record in a single transaction.  The
+// changes are planned against the current state of the tables, so they're
+// planned again if a concurrent write cancels the transaction.  If version
+// isn't nil the node record is only written while the stored copy is at
+// version.  The node's revision, and a history record if the store has an
+// actor, are written in the same transaction.  ErrTooManyChanges is returned
+// if the transaction wouldn't fit in a single dynamodb transaction, ie: the
+// node has too many static ip reservations.
+func (db *NodeStore) write(node *types.Node, deleteNode bool, version *time.Time) error {
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		tx := newTransaction(db.DynamoDBStore)
+
+		before, condition, values, err := db.changeCondition(&types.Node{InventoryID: node.ID()}, version)
+		if err != nil {
+			return err
+		}
+
+		err = inventory.ReconcileNodeIPs(&transactionStore{DynamoDBStore: db.withActor(""), tx: tx}, node)
+		if err != nil {
+			return err
+		}
+
+		err = db.reconcileMacIndex(tx, node)
+		if err != nil {
+			return err
+		}
+
+		revision := types.NewDeletedRevision(types.HistoryObjectNode, node.ID(), time.Now())
+		var after interface{}
+		if deleteNode {
+			err = tx.delete(node, condition, nil, values)
+		} else {
+			revision, err = inventory.NewObjectRevision(types.HistoryObjectNode, node)
+			if err != nil {
+				return err
+			}
+			after = node
+			err = tx.put(node, condition, nil, values)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.put(revision, "", nil, nil)
+		if err != nil {
+			return err
+		}
+
+		err = tx.record(types.HistoryObjectNode, node.ID(), before, after)
+		if err != nil {
+			return err
+		}
+
+		err = tx.commit()
+		if err != errTransactionCanceled {
+			return err
+		}
 	}
-	return db.DynamoDBStore.delete(node)
+	return fmt.Errorf("unable to write node %s: transaction canceled by concurrent writes %d times", node.ID(), maxWriteAttempts)
 }
 
 func (db *NodeStore) ObjDelete(obj interface{}) error {
@@ -260,3 +224,19 @@ func (db *NodeStore) ObjExists(obj interface{}) (bool, error) {
 	}
 	return db.Exists(node)
 }
+
+func (db *NodeStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(node, version)
+}
+
+func (db *NodeStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(node, version)
+}
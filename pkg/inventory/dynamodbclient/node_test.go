@@ -2,14 +2,23 @@ package dynamodbclient
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
 	"testing"
+	"time"
 
 	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/go-test/deep"
 )
 
 func TestNodeCreate(t *testing.T) {
@@ -60,3 +69,162 @@ func TestNodeCreate(t *testing.T) {
 	}
 
 }
+
+func TestListNodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Errorf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Errorf("unable to initialize tables: %v", err)
+	}
+
+	for i, role := range []string{"worker", "master", "worker", "worker", "worker"} {
+		err = inv.Node().Create(&types.Node{InventoryID: fmt.Sprintf("node%04d", i), Role: role})
+		if err != nil {
+			t.Fatalf("unable to create node: %v", err)
+		}
+	}
+
+	// scan order isn't defined, so only check that each worker is listed once
+	opts := inventory.ListOptions{Limit: 2, Filter: inventory.NodeFilter{Role: "worker"}}
+	ids := []string{}
+	for pages := 1; ; pages++ {
+		nodes, next, err := inv.Node().ListNodes(opts)
+		if err != nil {
+			t.Fatalf("unable to list nodes: %v", err)
+		}
+		for _, n := range nodes {
+			ids = append(ids, n.ID())
+		}
+		if next == "" {
+			break
+		}
+		if pages > 3 {
+			t.Fatalf("too many pages returned")
+		}
+		opts.Next = next
+	}
+
+	sort.Strings(ids)
+	if diff := deep.Equal(ids, []string{"node0000", "node0002", "node0003", "node0004"}); diff != nil {
+		t.Errorf("unexpected nodes listed: %v", diff)
+	}
+}
+
+// TestListNodesSparseFilter lists nodes from a stub dynamodb endpoint that
+// returns one item per scan, of which only the first matches the filter
+func TestListNodesSparseFilter(t *testing.T) {
+	tableScans := 5 * maxListScans
+	scans := 0
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "DynamoDB_20120810.Scan" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		role := "worker"
+		if scans == 0 {
+			role = "storage"
+		}
+		id := fmt.Sprintf("node%04d", scans)
+		scans++
+
+		// the table ends after tableScans items
+		lastKey := ""
+		if scans < tableScans {
+			lastKey = fmt.Sprintf(`,"LastEvaluatedKey":{"InventoryID":{"S":"%s"}}`, id)
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		fmt.Fprintf(w, `{"Items":[{"InventoryID":{"S":"%s"},"Role":{"S":"%s"}}]%s,"Count":1,"ScannedCount":1}`, id, role, lastKey)
+	}))
+	defer endpoint.Close()
+
+	db := dynamodb.New(session.New(&aws.Config{
+		Endpoint:    aws.String(endpoint.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+	inv := NewDynamoDBStore(db, nil)
+
+	nodes, next, err := inv.Node().ListNodes(inventory.ListOptions{Limit: 5, Filter: inventory.NodeFilter{Role: "storage"}})
+	if err != nil {
+		t.Fatalf("unable to list nodes: %v", err)
+	}
+
+	if len(nodes) != 1 || nodes[0].ID() != "node0000" {
+		t.Errorf("expected only the matching node to be listed, got %v", nodes)
+	}
+
+	if next == "" {
+		t.Errorf("expected a cursor to the rest of the table with the short page")
+	}
+
+	if scans != maxListScans {
+		t.Errorf("expected the scan to stop after %d requests, made %d", maxListScans, scans)
+	}
+}
+
+func TestNodeVersionedWrites(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Fatalf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Fatalf("unable to initialize tables: %v", err)
+	}
+
+	version := time.Date(2019, 6, 1, 10, 0, 0, 0, time.FixedZone("CDT", -5*3600))
+	err = inv.Node().Create(&types.Node{InventoryID: "test", Role: "worker", LastUpdated: version})
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	err = inv.Node().UpdateIfVersion(&types.Node{InventoryID: "test", Role: "master"}, version.Add(-time.Second))
+	if err != inventory.ErrVersionMismatch {
+		t.Errorf("expected version mismatch updating stale node, got: %v", err)
+	}
+
+	// versions match regardless of the time zone they're expressed in
+	err = inv.Node().UpdateIfVersion(&types.Node{InventoryID: "test", Role: "master", LastUpdated: version.Add(time.Second)}, version.UTC())
+	if err != nil {
+		t.Fatalf("unable to update node at current version: %v", err)
+	}
+
+	err = inv.Node().DeleteIfVersion(&types.Node{InventoryID: "test"}, version)
+	if err != inventory.ErrVersionMismatch {
+		t.Errorf("expected version mismatch deleting stale node, got: %v", err)
+	}
+
+	n, err := inv.Node().GetNodeByID("test")
+	if err != nil || n.Role != "master" {
+		t.Fatalf("node not updated: %v %v", n, err)
+	}
+
+	err = inv.Node().DeleteIfVersion(&types.Node{InventoryID: "test"}, version.Add(time.Second))
+	if err != nil {
+		t.Errorf("unable to delete node at current version: %v", err)
+	}
+
+	_, err = inv.Node().GetNodeByID("test")
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected node to be deleted, got: %v", err)
+	}
+}
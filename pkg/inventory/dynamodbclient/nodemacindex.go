@@ -2,10 +2,21 @@ package dynamodbclient
 
 import (
 	"fmt"
+	"log"
 	"net"
+	"strings"
 	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
+// nodeIDIndexName is the global secondary index used to look up the mac index
+// entries belonging to a node
+const nodeIDIndexName = "nodeid"
+
 type NodeMacIndexEntry struct {
 	Mac         net.HardwareAddr
 	LastUpdated time.Time
@@ -24,11 +35,107 @@ func (i *NodeMacIndexEntry) SetTimestamp(timestamp time.Time) {
 	i.LastUpdated = timestamp
 }
 
+// NodeMacIndexTable is a simple table keyed by mac, with a global secondary
+// index on NodeID
+type NodeMacIndexTable struct {
+	SimpleDynamoDBInventoryTable
+}
+
+func (t *NodeMacIndexTable) GetGlobalSecondaryIndexes() []*dynamodb.GlobalSecondaryIndex {
+	return []*dynamodb.GlobalSecondaryIndex{
+		&dynamodb.GlobalSecondaryIndex{
+			IndexName: aws.String(nodeIDIndexName),
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(1),
+				WriteCapacityUnits: aws.Int64(1),
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{
+					AttributeName: aws.String("NodeID"),
+					KeyType:       aws.String("HASH"),
+				},
+			},
+			Projection: &dynamodb.Projection{
+				ProjectionType: aws.String("ALL"),
+			},
+		},
+	}
+}
+
+func (t *NodeMacIndexTable) GetCreateTableInput() *dynamodb.CreateTableInput {
+	input := t.SimpleDynamoDBInventoryTable.GetCreateTableInput()
+	input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+		AttributeName: aws.String("NodeID"),
+		AttributeType: aws.String("S"),
+	})
+	input.GlobalSecondaryIndexes = t.GetGlobalSecondaryIndexes()
+	return input
+}
+
+// isMissingIndex returns true if err reports that the table doesn't have the
+// index, as happens when querying tables created before the index was added
+func isMissingIndex(err error, index string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "ValidationException" &&
+		strings.Contains(aerr.Message(), "does not have the specified index") &&
+		strings.Contains(aerr.Message(), index)
+}
+
 type nodeMacIndexStore struct {
 	*DynamoDBStore
 }
 
+// GetMacIndexEntriesByNodeID queries the NodeID index for the node's entries.
+// Tables created before the index was added are scanned instead, until they're
+// migrated with MigrateTables.
 func (db *nodeMacIndexStore) GetMacIndexEntriesByNodeID(id string) (map[string]*NodeMacIndexEntry, error) {
+	results := make(map[string]*NodeMacIndexEntry, 0)
+	if id == "" {
+		return results, nil
+	}
+
+	table := db.tableMap.LookupTable(&NodeMacIndexEntry{})
+	if table == nil {
+		return nil, ErrInvalidObjectType
+	}
+
+	nodeID, err := dynamodbattribute.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &dynamodb.QueryInput{
+		TableName:                 aws.String(table.GetName()),
+		IndexName:                 aws.String(nodeIDIndexName),
+		KeyConditionExpression:    aws.String("NodeID=:nodeid"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":nodeid": nodeID},
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = db.db.QueryPages(q, func(out *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, out.Items...)
+		return true
+	})
+	if isMissingIndex(err, nodeIDIndexName) {
+		log.Printf("the %s index of %s is missing, falling back to a table scan until the table is migrated: %v", nodeIDIndexName, table.GetName(), err)
+		return db.scanMacIndexEntriesByNodeID(id)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to query NodeMacIndexEntries: %v", err)
+	}
+
+	entries := make([]*NodeMacIndexEntry, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeMacIndexEntry := range entries {
+		results[nodeMacIndexEntry.Mac.String()] = nodeMacIndexEntry
+	}
+	return results, nil
+}
+
+func (db *nodeMacIndexStore) scanMacIndexEntriesByNodeID(id string) (map[string]*NodeMacIndexEntry, error) {
 	allMacs := make([]*NodeMacIndexEntry, 0, 0)
 	err := db.getAll(&allMacs)
 	if err != nil {
@@ -0,0 +1,98 @@
+package dynamodbclient
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestNodeMacIndexTableDefinition(t *testing.T) {
+	table := &NodeMacIndexTable{SimpleDynamoDBInventoryTable{Name: "inventory_node_mac_lookup"}}
+	input := table.GetCreateTableInput()
+
+	if len(input.GlobalSecondaryIndexes) != 1 || aws.StringValue(input.GlobalSecondaryIndexes[0].IndexName) != nodeIDIndexName {
+		t.Errorf("NodeID index missing from table definition: %v", input.GlobalSecondaryIndexes)
+	}
+
+	definitions := indexAttributeDefinitions(table, input.GlobalSecondaryIndexes[0])
+	if len(definitions) != 1 || aws.StringValue(definitions[0].AttributeName) != "NodeID" || aws.StringValue(definitions[0].AttributeType) != "S" {
+		t.Errorf("unexpected index attribute definitions: %v", definitions)
+	}
+}
+
+func TestIsMissingIndex(t *testing.T) {
+	cases := []struct {
+		Err      error
+		Expected bool
+	}{
+		{awserr.New("ValidationException", "The table does not have the specified index: nodeid", nil), true},
+		{awserr.New("ValidationException", "The table does not have the specified index: mac", nil), false},
+		{awserr.New("ValidationException", "Invalid KeyConditionExpression: Syntax error", nil), false},
+		{awserr.New("ResourceNotFoundException", "Requested resource not found", nil), false},
+		{nil, false},
+	}
+
+	for _, c := range cases {
+		if isMissingIndex(c.Err, nodeIDIndexName) != c.Expected {
+			t.Errorf("expected isMissingIndex to return %t for %v", c.Expected, c.Err)
+		}
+	}
+}
+
+func TestMigrateTables(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Errorf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	// create the mac lookup table as older versions did, without the index
+	err = inv.createTable(&SimpleDynamoDBInventoryTable{Name: "inventory_node_mac_lookup"})
+	if err != nil {
+		t.Fatalf("unable to create old mac lookup table: %v", err)
+	}
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Fatalf("unable to initialize tables: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	err = inv.nodeMacIndex().Create(&NodeMacIndexEntry{Mac: mac, NodeID: "node0001"})
+	if err != nil {
+		t.Fatalf("unable to create mac index entry: %v", err)
+	}
+
+	entries, err := inv.nodeMacIndex().GetMacIndexEntriesByNodeID("node0001")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("lookup without index failed: %v, %v", entries, err)
+	}
+
+	indexPollInterval = 0
+	err = inv.MigrateTables()
+	if err != nil {
+		t.Fatalf("unable to migrate tables: %v", err)
+	}
+
+	description, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("inventory_node_mac_lookup")})
+	if err != nil || !hasIndex(description.Table, nodeIDIndexName) {
+		t.Fatalf("index not created: %v", err)
+	}
+
+	entries, err = inv.nodeMacIndex().GetMacIndexEntriesByNodeID("node0001")
+	if err != nil || len(entries) != 1 || entries[mac.String()] == nil {
+		t.Errorf("lookup using index failed: %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,123 @@
+package dynamodbclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// revisionAppendCondition prevents AppendRevision from replacing a revision
+const revisionAppendCondition = "attribute_not_exists(ObjectKey)"
+
+type RevisionStore struct {
+	*DynamoDBStore
+}
+
+// PutRevision stores the revision, replacing any revision of the object with
+// the same timestamp
+func (db *RevisionStore) PutRevision(r *types.Revision) error {
+	return db.update(r)
+}
+
+// AppendRevision stores the revision, moving its timestamp forward if it
+// collides with an existing revision of the object
+func (db *RevisionStore) AppendRevision(r *types.Revision) error {
+	for attempt := 0; attempt < maxHistoryAttempts; attempt++ {
+		putItem, err := db.putItemInput(r)
+		if err != nil {
+			return err
+		}
+		putItem.SetConditionExpression(revisionAppendCondition)
+
+		_, err = db.db.PutItem(putItem)
+		if !isConditionalCheckFailed(err) {
+			return err
+		}
+		r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+	}
+	return fmt.Errorf("unable to find a free sequence for the revisions of %s", r.Key())
+}
+
+// GetRevisionAt returns the latest revision of the object at or before at
+func (db *RevisionStore) GetRevisionAt(objectType string, objectID string, at time.Time) (*types.Revision, error) {
+	table := db.tableMap.LookupTable(&types.Revision{})
+	if table == nil {
+		return nil, ErrInvalidObjectType
+	}
+
+	values, err := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":partitionkeyval": types.HistoryKey(objectType, objectID),
+		":at":              types.RevisionSequence(at),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := db.db.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String(table.GetName()),
+		KeyConditionExpression:    aws.String("ObjectKey=:partitionkeyval AND #seq <= :at"),
+		ExpressionAttributeNames:  map[string]*string{"#seq": aws.String("Sequence")},
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to query dynamodb table %s: %v", table.GetName(), err)
+	}
+
+	if len(results.Items) == 0 {
+		return nil, ErrObjectNotFound
+	}
+
+	r := &types.Revision{}
+	err = dynamodbattribute.UnmarshalMap(results.Items[0], r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRevisionsSince returns the revisions of the object at or after since,
+// oldest first
+func (db *RevisionStore) GetRevisionsSince(objectType string, objectID string, since time.Time) ([]*types.Revision, error) {
+	table := db.tableMap.LookupTable(&types.Revision{})
+	if table == nil {
+		return nil, ErrInvalidObjectType
+	}
+
+	values, err := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":partitionkeyval": types.HistoryKey(objectType, objectID),
+		":since":           types.RevisionSequence(since),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	in := &dynamodb.QueryInput{
+		TableName:                 aws.String(table.GetName()),
+		KeyConditionExpression:    aws.String("ObjectKey=:partitionkeyval AND #seq >= :since"),
+		ExpressionAttributeNames:  map[string]*string{"#seq": aws.String("Sequence")},
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(true),
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = db.db.QueryPages(in, func(results *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, results.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to query dynamodb table %s: %v", table.GetName(), err)
+	}
+
+	revisions := make([]*types.Revision, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &revisions)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
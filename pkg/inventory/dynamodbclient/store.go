@@ -1,9 +1,15 @@
 package dynamodbclient
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
@@ -13,9 +19,12 @@ type DynamoDBTableLookup interface {
 	Tables() []DynamoDBStoreTable
 }
 
+var _ inventory.Store = &DynamoDBStore{}
+
 type DynamoDBStore struct {
 	tableMap DynamoDBTableLookup
 	db       *dynamodb.DynamoDB
+	actor    string
 }
 
 // NewDynamoDBStore creates a DynamoDBStore
@@ -27,6 +36,17 @@ func NewDynamoDBStore(db *dynamodb.DynamoDB, tableMap DynamoDBTableLookup) *Dyna
 	return obj
 }
 
+// WithActor returns a copy of the store that records the changes it makes in
+// history, attributed to actor.  Each record is written in the same
+// transaction as its change.
+func (db *DynamoDBStore) WithActor(actor string) inventory.Store {
+	return db.withActor(actor)
+}
+
+func (db *DynamoDBStore) withActor(actor string) *DynamoDBStore {
+	return &DynamoDBStore{tableMap: db.tableMap, db: db.db, actor: actor}
+}
+
 func (db *DynamoDBStore) InitializeTables() error {
 	for _, table := range db.tableMap.Tables() {
 		if table == nil {
@@ -44,6 +64,111 @@ func (db *DynamoDBStore) InitializeTables() error {
 	return nil
 }
 
+// secondaryIndexTable is implemented by tables with global secondary indexes
+type secondaryIndexTable interface {
+	GetGlobalSecondaryIndexes() []*dynamodb.GlobalSecondaryIndex
+}
+
+// indexPollInterval is how often MigrateTables checks whether a new index has
+// finished backfilling
+var indexPollInterval = 5 * time.Second
+
+// MigrateTables adds any global secondary indexes missing from existing
+// tables, ie: tables created by InitializeTables before the index was
+// defined.  Dynamodb only allows one index to be created on a table at a
+// time, so MigrateTables waits for each new index to become active, which may
+// take a while on large tables.
+func (db *DynamoDBStore) MigrateTables() error {
+	for _, table := range db.tableMap.Tables() {
+		indexTable, ok := table.(secondaryIndexTable)
+		if !ok {
+			continue
+		}
+
+		for _, index := range indexTable.GetGlobalSecondaryIndexes() {
+			description, err := db.db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(table.GetName())})
+			if err != nil {
+				return fmt.Errorf("unable to describe table %s: %v", table.GetName(), err)
+			}
+
+			if hasIndex(description.Table, aws.StringValue(index.IndexName)) {
+				continue
+			}
+
+			log.Printf("creating index %s on table %s", aws.StringValue(index.IndexName), table.GetName())
+			_, err = db.db.UpdateTable(&dynamodb.UpdateTableInput{
+				TableName:            aws.String(table.GetName()),
+				AttributeDefinitions: indexAttributeDefinitions(table, index),
+				GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+					{Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+						IndexName:             index.IndexName,
+						KeySchema:             index.KeySchema,
+						Projection:            index.Projection,
+						ProvisionedThroughput: index.ProvisionedThroughput,
+					}},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("unable to create index %s on table %s: %v", aws.StringValue(index.IndexName), table.GetName(), err)
+			}
+
+			err = db.waitForIndexes(table.GetName())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasIndex(table *dynamodb.TableDescription, name string) bool {
+	for _, index := range table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// indexAttributeDefinitions returns the definitions of the attributes used in
+// the index's key schema
+func indexAttributeDefinitions(table DynamoDBStoreTable, index *dynamodb.GlobalSecondaryIndex) []*dynamodb.AttributeDefinition {
+	keys := make(map[string]bool)
+	for _, element := range index.KeySchema {
+		keys[aws.StringValue(element.AttributeName)] = true
+	}
+
+	definitions := make([]*dynamodb.AttributeDefinition, 0, len(keys))
+	for _, definition := range table.GetCreateTableInput().AttributeDefinitions {
+		if keys[aws.StringValue(definition.AttributeName)] {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// waitForIndexes waits until every global secondary index on the table is active
+func (db *DynamoDBStore) waitForIndexes(tableName string) error {
+	for {
+		description, err := db.db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			return fmt.Errorf("unable to describe table %s: %v", tableName, err)
+		}
+
+		active := true
+		for _, index := range description.Table.GlobalSecondaryIndexes {
+			if aws.StringValue(index.IndexStatus) != dynamodb.IndexStatusActive {
+				active = false
+			}
+		}
+
+		if active {
+			return nil
+		}
+		time.Sleep(indexPollInterval)
+	}
+}
+
 func (db *DynamoDBStore) createTable(table DynamoDBStoreTable) error {
 	input := table.GetCreateTableInput()
 
@@ -52,9 +177,19 @@ func (db *DynamoDBStore) createTable(table DynamoDBStoreTable) error {
 }
 
 func (db *DynamoDBStore) update(obj interface{}) error {
+	putItem, err := db.putItemInput(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.db.PutItem(putItem)
+	return err
+}
+
+func (db *DynamoDBStore) putItemInput(obj interface{}) (*dynamodb.PutItemInput, error) {
 	table := db.tableMap.LookupTable(obj)
 	if table == nil {
-		return ErrInvalidObjectType
+		return nil, ErrInvalidObjectType
 	}
 
 	putItem := &dynamodb.PutItemInput{}
@@ -62,14 +197,63 @@ func (db *DynamoDBStore) update(obj interface{}) error {
 	putItem.Item, _ = dynamodbattribute.MarshalMap(obj)
 	keyMap, err := table.GetKeyFrom(obj)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for k, v := range keyMap {
 		putItem.Item[k] = v
 	}
+	return putItem, nil
+}
+
+// versioned is implemented by objects whose LastUpdated timestamp identifies
+// their revision
+type versioned interface {
+	Version() time.Time
+}
+
+// versionCondition looks up the stored copy of current, which only needs its
+// key set, and returns ErrVersionMismatch unless it's at version.  The
+// returned condition expression holds only while the item's LastUpdated
+// attribute is unchanged, so writes using it fail if the item is modified
+// after the check.
+func (db *DynamoDBStore) versionCondition(current versioned, version time.Time) (string, map[string]*dynamodb.AttributeValue, error) {
+	err := db.get(current)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !current.Version().Equal(version) {
+		return "", nil, ErrVersionMismatch
+	}
+
+	// the stored copy is compared rather than version, because the same
+	// instant may be stored with a different time zone offset
+	stored, err := dynamodbattribute.Marshal(current.Version())
+	if err != nil {
+		return "", nil, err
+	}
+	return "LastUpdated = :version", map[string]*dynamodb.AttributeValue{":version": stored}, nil
+}
+
+// updateIfVersion writes obj if the stored copy, read into current, is at version
+func (db *DynamoDBStore) updateIfVersion(obj interface{}, current versioned, version time.Time) error {
+	condition, values, err := db.versionCondition(current, version)
+	if err != nil {
+		return err
+	}
+
+	putItem, err := db.putItemInput(obj)
+	if err != nil {
+		return err
+	}
+	putItem.SetConditionExpression(condition)
+	putItem.SetExpressionAttributeValues(values)
 
 	_, err = db.db.PutItem(putItem)
+	if isConditionalCheckFailed(err) {
+		return ErrVersionMismatch
+	}
 	return err
 }
 
@@ -114,6 +298,113 @@ func (db *DynamoDBStore) delete(obj interface{}) error {
 	return err
 }
 
+// deleteIfVersion deletes obj if the stored copy, read into current, is at version
+func (db *DynamoDBStore) deleteIfVersion(obj interface{}, current versioned, version time.Time) error {
+	condition, values, err := db.versionCondition(current, version)
+	if err != nil {
+		return err
+	}
+
+	table := db.tableMap.LookupTable(obj)
+	if table == nil {
+		return fmt.Errorf("No table found for object of type %T", obj)
+	}
+
+	objKey, err := table.GetKeyFrom(obj)
+	if err != nil {
+		return fmt.Errorf("unable to get key from object: %v", err)
+	}
+
+	_, err = db.db.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String(table.GetName()),
+		Key:                       objKey,
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
+// changeCondition reads the stored copy of current, which only needs its key
+// set, and returns it along with a condition that holds only while the item is
+// unchanged, so that a history record of the write describes the object it
+// replaces.  If version isn't nil the stored copy must be at version, as with
+// versionCondition.  Without an actor or a version nothing needs to be read,
+// and before and the condition are empty.
+func (db *DynamoDBStore) changeCondition(current versioned, version *time.Time) (interface{}, string, map[string]*dynamodb.AttributeValue, error) {
+	if version != nil {
+		condition, values, err := db.versionCondition(current, *version)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return current, condition, values, nil
+	}
+
+	if db.actor == "" {
+		return nil, "", nil, nil
+	}
+
+	err := db.get(current)
+	if err == ErrObjectNotFound {
+		return nil, "attribute_not_exists(LastUpdated)", nil, nil
+	} else if err != nil {
+		return nil, "", nil, err
+	}
+
+	stored, err := dynamodbattribute.Marshal(current.Version())
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return current, "LastUpdated = :version", map[string]*dynamodb.AttributeValue{":version": stored}, nil
+}
+
+// writeWithRevision puts obj, or deletes it if deleteObj is set, and stores
+// the revision, and a history record if the store has an actor, in the same
+// transaction, so that the revisions and history of an object match its
+// writes.  If version isn't nil obj is only written while the stored copy,
+// read into current, is at version.
+func (db *DynamoDBStore) writeWithRevision(obj interface{}, revision *types.Revision, deleteObj bool, current versioned, version *time.Time) error {
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		before, condition, values, err := db.changeCondition(current, version)
+		if err != nil {
+			return err
+		}
+
+		tx := newTransaction(db)
+		var after interface{}
+		if deleteObj {
+			err = tx.delete(obj, condition, nil, values)
+		} else {
+			after = obj
+			err = tx.put(obj, condition, nil, values)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.put(revision, "", nil, nil)
+		if err != nil {
+			return err
+		}
+
+		err = tx.record(revision.ObjectType, revision.ObjectID, before, after)
+		if err != nil {
+			return err
+		}
+
+		err = tx.commit()
+		if err == errTransactionCanceled && version != nil {
+			return ErrVersionMismatch
+		}
+		if err != errTransactionCanceled || condition == "" {
+			return err
+		}
+	}
+	return fmt.Errorf("unable to write %s %s: transaction canceled by concurrent writes %d times", revision.ObjectType, revision.ObjectID, maxWriteAttempts)
+}
+
 func (db *DynamoDBStore) getAll(out interface{}) error {
 	table := db.tableMap.LookupTable(out)
 	if table == nil {
@@ -128,7 +419,7 @@ func (db *DynamoDBStore) getAll(out interface{}) error {
 		for _, i := range results.Items {
 			outputElements = append(outputElements, i)
 		}
-		return false
+		return true
 	}
 
 	err := db.db.ScanPages(in, scanFn)
@@ -144,6 +435,95 @@ func (db *DynamoDBStore) getAll(out interface{}) error {
 	return nil
 }
 
+// maxListScans limits how many scan requests listPage makes for a page, so
+// that a filter matching few items doesn't read the whole table in one request
+const maxListScans = 10
+
+// listPage scans the table for out's type, starting at the cursor in opts.
+// Each item is passed to add, which returns false if the item was filtered
+// out.  Once opts.Limit items have been added the scan stops and the cursor
+// for the next page is returned.  If fewer items match than the limit, the
+// scan stops after maxListScans requests and the page is returned short, with
+// the cursor where the scan stopped.  The last page may be empty, because
+// dynamodb can't tell us whether any items remain.
+func (db *DynamoDBStore) listPage(out interface{}, opts inventory.ListOptions, add func(map[string]*dynamodb.AttributeValue) (bool, error)) (string, error) {
+	table := db.tableMap.LookupTable(out)
+	if table == nil {
+		return "", fmt.Errorf("No table found for object of type %T", out)
+	}
+
+	in := &dynamodb.ScanInput{
+		TableName: aws.String(table.GetName()),
+	}
+
+	if opts.Limit > 0 {
+		in.Limit = aws.Int64(int64(opts.Limit))
+	}
+
+	if opts.Next != "" {
+		position, err := inventory.DecodeCursor(opts.Next)
+		if err != nil {
+			return "", err
+		}
+		err = json.Unmarshal(position, &in.ExclusiveStartKey)
+		if err != nil || len(in.ExclusiveStartKey) == 0 {
+			return "", inventory.ErrInvalidCursor
+		}
+	}
+
+	count := 0
+	for scans := 1; ; scans++ {
+		results, err := db.db.Scan(in)
+		if err != nil {
+			return "", fmt.Errorf("unable to scan dynamodb table %s: %v", table.GetName(), err)
+		}
+
+		for i, item := range results.Items {
+			added, err := add(item)
+			if err != nil {
+				return "", err
+			}
+			if added {
+				count++
+			}
+
+			if opts.Limit > 0 && count >= opts.Limit {
+				if i == len(results.Items)-1 && results.LastEvaluatedKey == nil {
+					return "", nil
+				}
+				return encodeKeyCursor(keyFromItem(table, item))
+			}
+		}
+
+		if results.LastEvaluatedKey == nil {
+			return "", nil
+		}
+
+		if opts.Limit > 0 && scans >= maxListScans {
+			return encodeKeyCursor(results.LastEvaluatedKey)
+		}
+		in.ExclusiveStartKey = results.LastEvaluatedKey
+	}
+}
+
+func encodeKeyCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	position, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return inventory.EncodeCursor(position), nil
+}
+
+// keyFromItem returns the key attributes of a raw item
+func keyFromItem(table DynamoDBStoreTable, item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	key := make(map[string]*dynamodb.AttributeValue)
+	for _, element := range table.GetKeySchema() {
+		name := aws.StringValue(element.AttributeName)
+		key[name] = item[name]
+	}
+	return key
+}
+
 func (db *DynamoDBStore) get(obj interface{}) error {
 	table := db.tableMap.LookupTable(obj)
 	if table == nil {
@@ -177,6 +557,13 @@ func (db *DynamoDBStore) get(obj interface{}) error {
 	return nil
 }
 
+// isConditionalCheckFailed returns true if err was caused by a failed condition
+// expression on a write
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
 func (db *DynamoDBStore) exists(obj interface{}) (bool, error) {
 	table := db.tableMap.LookupTable(obj)
 	if table == nil {
@@ -198,19 +585,19 @@ func (db *DynamoDBStore) exists(obj interface{}) (bool, error) {
 	return len(results.Items) != 0, nil
 }
 
-func (db *DynamoDBStore) Node() *NodeStore {
+func (db *DynamoDBStore) Node() inventory.NodeStore {
 	return &NodeStore{DynamoDBStore: db}
 }
 
-func (db *DynamoDBStore) InventoryNode() *InventoryNodeStore {
-	return &InventoryNodeStore{DynamoDBStore: db}
+func (db *DynamoDBStore) InventoryNode() inventory.InventoryNodeStore {
+	return inventory.NewInventoryNodeStore(db)
 }
 
-func (db *DynamoDBStore) Network() *NetworkStore {
+func (db *DynamoDBStore) Network() inventory.NetworkStore {
 	return &NetworkStore{DynamoDBStore: db}
 }
 
-func (db *DynamoDBStore) System() *SystemStore {
+func (db *DynamoDBStore) System() inventory.SystemStore {
 	return &SystemStore{DynamoDBStore: db}
 }
 
@@ -218,6 +605,18 @@ func (db *DynamoDBStore) nodeMacIndex() *nodeMacIndexStore {
 	return &nodeMacIndexStore{DynamoDBStore: db}
 }
 
-func (db *DynamoDBStore) IPReservation() *IPReservationStore {
+func (db *DynamoDBStore) History() inventory.HistoryStore {
+	return &HistoryStore{DynamoDBStore: db}
+}
+
+func (db *DynamoDBStore) Revision() inventory.RevisionStore {
+	return &RevisionStore{DynamoDBStore: db}
+}
+
+func (db *DynamoDBStore) Webhook() inventory.WebhookStore {
+	return &WebhookStore{DynamoDBStore: db}
+}
+
+func (db *DynamoDBStore) IPReservation() inventory.IPReservationStore {
 	return &IPReservationStore{DynamoDBStore: db}
 }
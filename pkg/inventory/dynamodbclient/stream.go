@@ -0,0 +1,204 @@
+package dynamodbclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// streamedKinds lists the kinds of object whose tables are streamed, with a
+// function returning an empty object of each kind
+var streamedKinds = map[string]func() interface{}{
+	types.HistoryObjectNode:          func() interface{} { return &types.Node{} },
+	types.HistoryObjectNetwork:       func() interface{} { return &types.Network{} },
+	types.HistoryObjectSystem:        func() interface{} { return &types.System{} },
+	types.HistoryObjectIPReservation: func() interface{} { return &types.IPReservation{} },
+}
+
+// Stream record event names
+const (
+	streamInsert = "INSERT"
+	streamModify = "MODIFY"
+	streamRemove = "REMOVE"
+)
+
+// StreamDecoder decodes records from the streams of the node, network, system
+// and ip reservation tables into change events
+type StreamDecoder struct {
+	tableMap DynamoDBTableLookup
+}
+
+// NewStreamDecoder creates a StreamDecoder for the tables in tableMap, or the
+// default tables if it's nil
+func NewStreamDecoder(tableMap DynamoDBTableLookup) *StreamDecoder {
+	if tableMap == nil {
+		tableMap = defatultDynamoDBTables
+	}
+	return &StreamDecoder{tableMap: tableMap}
+}
+
+// streamTableName returns the name of the table a stream belongs to, from an
+// arn of the form arn:aws:dynamodb:<region>:<account>:table/<name>/stream/<label>
+func streamTableName(arn string) (string, error) {
+	parts := strings.Split(arn, "/")
+	if len(parts) < 2 || !strings.HasSuffix(parts[0], ":table") {
+		return "", fmt.Errorf("invalid stream arn: %s", arn)
+	}
+	return parts[1], nil
+}
+
+// kind returns the kind of object stored in the table, or "" if the table
+// isn't streamed
+func (d *StreamDecoder) kind(tableName string) string {
+	for kind, newObj := range streamedKinds {
+		if table := d.tableMap.LookupTable(newObj()); table != nil && table.GetName() == tableName {
+			return kind
+		}
+	}
+	return ""
+}
+
+// streamAttributeValue converts an attribute value from a stream record into
+// the equivalent sdk attribute value
+func streamAttributeValue(av events.DynamoDBAttributeValue) *dynamodb.AttributeValue {
+	switch av.DataType() {
+	case events.DataTypeBinary:
+		return &dynamodb.AttributeValue{B: av.Binary()}
+	case events.DataTypeBoolean:
+		return &dynamodb.AttributeValue{BOOL: aws.Bool(av.Boolean())}
+	case events.DataTypeBinarySet:
+		return &dynamodb.AttributeValue{BS: av.BinarySet()}
+	case events.DataTypeList:
+		l := make([]*dynamodb.AttributeValue, 0, len(av.List()))
+		for _, v := range av.List() {
+			l = append(l, streamAttributeValue(v))
+		}
+		return &dynamodb.AttributeValue{L: l}
+	case events.DataTypeMap:
+		return &dynamodb.AttributeValue{M: streamItem(av.Map())}
+	case events.DataTypeNumber:
+		return &dynamodb.AttributeValue{N: aws.String(av.Number())}
+	case events.DataTypeNumberSet:
+		return &dynamodb.AttributeValue{NS: aws.StringSlice(av.NumberSet())}
+	case events.DataTypeString:
+		return &dynamodb.AttributeValue{S: aws.String(av.String())}
+	case events.DataTypeStringSet:
+		return &dynamodb.AttributeValue{SS: aws.StringSlice(av.StringSet())}
+	default:
+		return &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	}
+}
+
+// streamItem converts an image from a stream record into an item
+func streamItem(image map[string]events.DynamoDBAttributeValue) map[string]*dynamodb.AttributeValue {
+	item := make(map[string]*dynamodb.AttributeValue, len(image))
+	for k, v := range image {
+		item[k] = streamAttributeValue(v)
+	}
+	return item
+}
+
+// decodeImage unmarshals a stream image into a new object of the kind, using
+// the same unmarshalling as the store.  It returns nil if the image is empty.
+func decodeImage(kind string, image map[string]events.DynamoDBAttributeValue) (interface{}, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+
+	obj := streamedKinds[kind]()
+	err := dynamodbattribute.UnmarshalMap(streamItem(image), obj)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// streamObjectID returns the id of a decoded object, matching the ids used
+// in history
+func streamObjectID(obj interface{}) string {
+	switch o := obj.(type) {
+	case *types.IPReservation:
+		if o.IP != nil {
+			return o.IP.IP.String()
+		}
+	case interface{ ID() string }:
+		return o.ID()
+	}
+	return ""
+}
+
+// reservationDeleted returns true if obj is a reservation that was deleted
+func reservationDeleted(obj interface{}) bool {
+	r, ok := obj.(*types.IPReservation)
+	return ok && r.Deleted != nil
+}
+
+// ChangeEvent decodes the record into a change event.  It returns nil if the
+// record is from a table that isn't streamed.  Reservations are deleted by
+// marking them, so the update that marks a reservation is decoded as a
+// delete, and the update that replaces a deleted reservation as a create.
+// Removing a reservation that was already deleted, as happens when it's
+// purged or expires from the table, isn't a change.
+func (d *StreamDecoder) ChangeEvent(record events.DynamoDBEventRecord) (*types.ChangeEvent, error) {
+	tableName, err := streamTableName(record.EventSourceArn)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := d.kind(tableName)
+	if kind == "" {
+		return nil, nil
+	}
+
+	before, err := decodeImage(kind, record.Change.OldImage)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode old image of %s record: %v", kind, err)
+	}
+
+	after, err := decodeImage(kind, record.Change.NewImage)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode new image of %s record: %v", kind, err)
+	}
+
+	var eventType string
+	switch record.EventName {
+	case streamInsert:
+		eventType = types.ChangeEventCreated
+	case streamModify:
+		eventType = types.ChangeEventUpdated
+	case streamRemove:
+		eventType = types.ChangeEventDeleted
+	default:
+		return nil, fmt.Errorf("unsupported stream event: %s", record.EventName)
+	}
+
+	switch {
+	case reservationDeleted(before) && (after == nil || reservationDeleted(after)):
+		return nil, nil
+	case reservationDeleted(before):
+		eventType, before = types.ChangeEventCreated, nil
+	case reservationDeleted(after):
+		eventType, after = types.ChangeEventDeleted, nil
+	}
+
+	id := streamObjectID(after)
+	if id == "" {
+		id = streamObjectID(before)
+	}
+
+	e, err := types.NewChangeEvent(eventType, kind, id, before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := record.Change.ApproximateCreationDateTime.Time; !t.IsZero() {
+		e.Timestamp = t.In(time.UTC)
+	}
+	return e, nil
+}
@@ -0,0 +1,197 @@
+package dynamodbclient
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// testStreamAttributeValue converts an sdk attribute value into the form it
+// takes in a stream record
+func testStreamAttributeValue(av *dynamodb.AttributeValue) events.DynamoDBAttributeValue {
+	switch {
+	case av.B != nil:
+		return events.NewBinaryAttribute(av.B)
+	case av.BOOL != nil:
+		return events.NewBooleanAttribute(*av.BOOL)
+	case av.BS != nil:
+		return events.NewBinarySetAttribute(av.BS)
+	case av.L != nil:
+		l := []events.DynamoDBAttributeValue{}
+		for _, v := range av.L {
+			l = append(l, testStreamAttributeValue(v))
+		}
+		return events.NewListAttribute(l)
+	case av.M != nil:
+		m := map[string]events.DynamoDBAttributeValue{}
+		for k, v := range av.M {
+			m[k] = testStreamAttributeValue(v)
+		}
+		return events.NewMapAttribute(m)
+	case av.N != nil:
+		return events.NewNumberAttribute(*av.N)
+	case av.NS != nil:
+		return events.NewNumberSetAttribute(aws.StringValueSlice(av.NS))
+	case av.S != nil:
+		return events.NewStringAttribute(*av.S)
+	case av.SS != nil:
+		return events.NewStringSetAttribute(aws.StringValueSlice(av.SS))
+	default:
+		return events.NewNullAttribute()
+	}
+}
+
+func streamImage(t *testing.T, obj interface{}) map[string]events.DynamoDBAttributeValue {
+	item, err := dynamodbattribute.MarshalMap(obj)
+	if err != nil {
+		t.Fatalf("unable to marshal object: %v", err)
+	}
+
+	image := map[string]events.DynamoDBAttributeValue{}
+	for k, v := range item {
+		image[k] = testStreamAttributeValue(v)
+	}
+	return image
+}
+
+func streamArn(table string) string {
+	return "arn:aws:dynamodb:us-east-2:123456789012:table/" + table + "/stream/2019-06-01T00:00:00.000"
+}
+
+func TestStreamDecoderNode(t *testing.T) {
+	node := &types.Node{InventoryID: "node0001", System: "tst", Role: "worker", Tags: types.Tags{"foo"}}
+	created := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	record := events.DynamoDBEventRecord{
+		EventName:      "INSERT",
+		EventSourceArn: streamArn("inventory_nodes"),
+		Change: events.DynamoDBStreamRecord{
+			ApproximateCreationDateTime: events.SecondsEpochTime{Time: created},
+			NewImage:                    streamImage(t, node),
+		},
+	}
+
+	e, err := NewStreamDecoder(nil).ChangeEvent(record)
+	if err != nil {
+		t.Fatalf("unable to decode record: %v", err)
+	}
+
+	if e.Type != types.ChangeEventCreated || e.Kind != types.HistoryObjectNode || e.ObjectID != "node0001" || !e.Timestamp.Equal(created) {
+		t.Errorf("wrong event decoded: %v", e)
+	}
+
+	if len(e.Before) != 0 {
+		t.Errorf("expected no previous node, got %s", e.Before)
+	}
+
+	decoded := &types.Node{}
+	err = json.Unmarshal(e.After, decoded)
+	if err != nil {
+		t.Fatalf("unable to unmarshal node: %v", err)
+	}
+
+	if decoded.System != "tst" || decoded.Role != "worker" || len(decoded.Tags) != 1 {
+		t.Errorf("node not decoded from new image: %v", decoded)
+	}
+
+	record.EventName = "REMOVE"
+	record.Change.OldImage, record.Change.NewImage = record.Change.NewImage, nil
+	e, err = NewStreamDecoder(nil).ChangeEvent(record)
+	if err != nil {
+		t.Fatalf("unable to decode record: %v", err)
+	}
+
+	if e.Type != types.ChangeEventDeleted || e.ObjectID != "node0001" || len(e.Before) == 0 || len(e.After) != 0 {
+		t.Errorf("wrong event decoded for removed node: %v", e)
+	}
+}
+
+func TestStreamDecoderIPReservation(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	deleted := start.Add(time.Hour)
+
+	active := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, MAC: mac, Start: &start}
+	removed := *active
+	removed.Deleted = &deleted
+
+	cases := map[string]struct {
+		before   *types.IPReservation
+		after    *types.IPReservation
+		expected string
+	}{
+		"deleted":  {active, &removed, types.ChangeEventDeleted},
+		"replaced": {&removed, active, types.ChangeEventCreated},
+		"updated":  {active, active, types.ChangeEventUpdated},
+	}
+
+	for name, c := range cases {
+		record := events.DynamoDBEventRecord{
+			EventName:      "MODIFY",
+			EventSourceArn: streamArn("inventory_ipam_ip"),
+			Change: events.DynamoDBStreamRecord{
+				OldImage: streamImage(t, c.before),
+				NewImage: streamImage(t, c.after),
+			},
+		}
+
+		e, err := NewStreamDecoder(nil).ChangeEvent(record)
+		if err != nil {
+			t.Fatalf("%s: unable to decode record: %v", name, err)
+		}
+
+		if e.Type != c.expected || e.Kind != types.HistoryObjectIPReservation || e.ObjectID != "10.0.0.10" {
+			t.Errorf("%s: wrong event decoded: %v", name, e)
+		}
+
+		if (c.expected == types.ChangeEventCreated) != (len(e.Before) == 0) || (c.expected == types.ChangeEventDeleted) != (len(e.After) == 0) {
+			t.Errorf("%s: wrong images in event: %s %s", name, e.Before, e.After)
+		}
+	}
+}
+
+func TestStreamDecoderPurgedIPReservation(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	deleted := start.Add(time.Hour)
+	removed := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, Start: &start, Deleted: &deleted}
+
+	record := events.DynamoDBEventRecord{
+		EventName:      "REMOVE",
+		EventSourceArn: streamArn("inventory_ipam_ip"),
+		Change: events.DynamoDBStreamRecord{
+			OldImage: streamImage(t, removed),
+		},
+	}
+
+	e, err := NewStreamDecoder(nil).ChangeEvent(record)
+	if err != nil || e != nil {
+		t.Errorf("expected removal of a deleted reservation to be ignored, got %v %v", e, err)
+	}
+}
+
+func TestStreamDecoderOtherTables(t *testing.T) {
+	record := events.DynamoDBEventRecord{
+		EventName:      "INSERT",
+		EventSourceArn: streamArn("inventory_history"),
+	}
+
+	e, err := NewStreamDecoder(nil).ChangeEvent(record)
+	if err != nil || e != nil {
+		t.Errorf("expected records from other tables to be ignored, got %v %v", e, err)
+	}
+
+	record.EventSourceArn = "not an arn"
+	_, err = NewStreamDecoder(nil).ChangeEvent(record)
+	if err == nil {
+		t.Errorf("expected an invalid arn to be rejected")
+	}
+}
@@ -2,8 +2,12 @@ package dynamodbclient
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 type SystemStore struct {
@@ -23,6 +27,24 @@ func (db *SystemStore) GetSystems() (map[string]*types.System, error) {
 	return systems, nil
 }
 
+// ListSystems returns a page of systems
+func (db *SystemStore) ListSystems(opts inventory.ListOptions) ([]*types.System, string, error) {
+	systems := make([]*types.System, 0)
+	next, err := db.listPage(&systems, opts, func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		system := &types.System{}
+		err := dynamodbattribute.UnmarshalMap(item, system)
+		if err != nil {
+			return false, err
+		}
+		systems = append(systems, system)
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing systems: %v", err)
+	}
+	return systems, next, nil
+}
+
 func (db *SystemStore) GetSystemByID(id string) (*types.System, error) {
 	system := &types.System{}
 	system.Name = id
@@ -35,15 +57,39 @@ func (db *SystemStore) Exists(system *types.System) (bool, error) {
 }
 
 func (db *SystemStore) Create(system *types.System) error {
-	return db.DynamoDBStore.create(system)
+	return db.write(system, false, nil)
 }
 
 func (db *SystemStore) Update(system *types.System) error {
-	return db.DynamoDBStore.update(system)
+	return db.write(system, false, nil)
 }
 
 func (db *SystemStore) Delete(system *types.System) error {
-	return db.DynamoDBStore.delete(system)
+	return db.write(system, true, nil)
+}
+
+// UpdateIfVersion updates the system if the stored copy is at version
+func (db *SystemStore) UpdateIfVersion(system *types.System, version time.Time) error {
+	return db.write(system, false, &version)
+}
+
+// DeleteIfVersion deletes the system if the stored copy is at version
+func (db *SystemStore) DeleteIfVersion(system *types.System, version time.Time) error {
+	return db.write(system, true, &version)
+}
+
+// write puts the system, or deletes it, and stores its revision in the same
+// transaction
+func (db *SystemStore) write(system *types.System, deleteSystem bool, version *time.Time) error {
+	revision := types.NewDeletedRevision(types.HistoryObjectSystem, system.ID(), time.Now())
+	if !deleteSystem {
+		var err error
+		revision, err = inventory.NewObjectRevision(types.HistoryObjectSystem, system)
+		if err != nil {
+			return err
+		}
+	}
+	return db.writeWithRevision(system, revision, deleteSystem, &types.System{Name: system.ID()}, version)
 }
 
 func (db *SystemStore) ObjDelete(obj interface{}) error {
@@ -77,3 +123,19 @@ func (db *SystemStore) ObjExists(obj interface{}) (bool, error) {
 	}
 	return db.Exists(system)
 }
+
+func (db *SystemStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(system, version)
+}
+
+func (db *SystemStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	system, ok := obj.(*types.System)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(system, version)
+}
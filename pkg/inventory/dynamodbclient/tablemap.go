@@ -51,7 +51,10 @@ var (
 		reflect.TypeOf(types.Node{}):          &SimpleDynamoDBInventoryTable{Name: "inventory_nodes"},
 		reflect.TypeOf(types.Network{}):       &SimpleDynamoDBInventoryTable{Name: "inventory_networks"},
 		reflect.TypeOf(types.System{}):        &SimpleDynamoDBInventoryTable{Name: "inventory_systems"},
-		reflect.TypeOf(NodeMacIndexEntry{}):   &SimpleDynamoDBInventoryTable{Name: "inventory_node_mac_lookup"},
+		reflect.TypeOf(NodeMacIndexEntry{}):   &NodeMacIndexTable{SimpleDynamoDBInventoryTable{Name: "inventory_node_mac_lookup"}},
 		reflect.TypeOf(types.IPReservation{}): &IPReservationTable{Name: "inventory_ipam_ip"},
+		reflect.TypeOf(types.HistoryRecord{}): &HistoryTable{Name: "inventory_history"},
+		reflect.TypeOf(types.Revision{}):      &HistoryTable{Name: "inventory_revisions"},
+		reflect.TypeOf(types.Webhook{}):       &SimpleDynamoDBInventoryTable{Name: "inventory_webhooks"},
 	}
 )
@@ -0,0 +1,300 @@
+package dynamodbclient
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// maxTransactionItems is the largest number of writes dynamodb accepts in a
+// single TransactWriteItems call
+const maxTransactionItems = 25
+
+// maxWriteAttempts limits how often a write is retried when its transaction
+// is canceled by a concurrent write
+const maxWriteAttempts = 5
+
+// errTransactionCanceled is returned by commit when a condition failed or the
+// transaction conflicted with another write.  The writes should be planned
+// again against the current state of the tables.
+var errTransactionCanceled = errors.New("transaction canceled")
+
+// transaction collects writes so that they can be committed atomically
+type transaction struct {
+	db    *DynamoDBStore
+	items []*dynamodb.TransactWriteItem
+	keys  map[string]bool
+}
+
+func newTransaction(db *DynamoDBStore) *transaction {
+	return &transaction{db: db, keys: make(map[string]bool)}
+}
+
+// key returns the table and key of obj, and fails with ErrAlreadyExists if the
+// transaction already writes to the item.  Dynamodb rejects transactions with
+// more than one write to an item.
+func (tx *transaction) key(obj interface{}) (DynamoDBStoreTable, map[string]*dynamodb.AttributeValue, error) {
+	table := tx.db.tableMap.LookupTable(obj)
+	if table == nil {
+		return nil, nil, ErrInvalidObjectType
+	}
+
+	key, err := table.GetKeyFrom(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encodedKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	itemKey := table.GetName() + string(encodedKey)
+	if tx.keys[itemKey] {
+		return nil, nil, ErrAlreadyExists
+	}
+	tx.keys[itemKey] = true
+	return table, key, nil
+}
+
+// put adds a put of obj to the transaction.  If condition isn't empty the
+// transaction is canceled unless it holds for the existing item.  names and
+// values hold the placeholders used by the condition.
+func (tx *transaction) put(obj interface{}, condition string, names map[string]*string, values map[string]*dynamodb.AttributeValue) error {
+	table, key, err := tx.key(obj)
+	if err != nil {
+		return err
+	}
+
+	item, err := dynamodbattribute.MarshalMap(obj)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range key {
+		item[k] = v
+	}
+
+	put := &dynamodb.Put{
+		TableName: aws.String(table.GetName()),
+		Item:      item,
+	}
+	if condition != "" {
+		put.ConditionExpression = aws.String(condition)
+		put.ExpressionAttributeNames = names
+		put.ExpressionAttributeValues = values
+	}
+
+	tx.items = append(tx.items, &dynamodb.TransactWriteItem{Put: put})
+	return nil
+}
+
+// delete adds a delete of obj to the transaction.  If condition isn't empty
+// the transaction is canceled unless it holds for the existing item.
+func (tx *transaction) delete(obj interface{}, condition string, names map[string]*string, values map[string]*dynamodb.AttributeValue) error {
+	table, key, err := tx.key(obj)
+	if err != nil {
+		return err
+	}
+
+	del := &dynamodb.Delete{
+		TableName: aws.String(table.GetName()),
+		Key:       key,
+	}
+	if condition != "" {
+		del.ConditionExpression = aws.String(condition)
+		del.ExpressionAttributeNames = names
+		del.ExpressionAttributeValues = values
+	}
+
+	tx.items = append(tx.items, &dynamodb.TransactWriteItem{Delete: del})
+	return nil
+}
+
+// record adds a history record of a change by the store's actor to the
+// transaction.  As with AppendHistory, the timestamp is moved forward if the
+// transaction already records a change to the object at that time, and the
+// transaction is canceled if a record was stored earlier.
+func (tx *transaction) record(objectType string, objectID string, before interface{}, after interface{}) error {
+	record, err := inventory.NewChangeRecord(tx.db.actor, objectType, objectID, before, after)
+	if err != nil || record == nil {
+		return err
+	}
+
+	for {
+		err = tx.put(record, "attribute_not_exists(ObjectKey)", nil, nil)
+		if err != ErrAlreadyExists {
+			return err
+		}
+		record.Timestamp = record.Timestamp.Add(time.Nanosecond)
+	}
+}
+
+// commit applies all writes in the transaction, or none of them.  It returns
+// ErrTooManyChanges without writing anything if the transaction holds more
+// than maxTransactionItems writes.
+func (tx *transaction) commit() error {
+	if len(tx.items) == 0 {
+		return nil
+	}
+
+	if len(tx.items) > maxTransactionItems {
+		return ErrTooManyChanges
+	}
+
+	_, err := tx.db.db.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: tx.items})
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case dynamodb.ErrCodeTransactionCanceledException, dynamodb.ErrCodeTransactionConflictException:
+			return errTransactionCanceled
+		}
+	}
+	return err
+}
+
+// transactionStore reads from dynamodb, but adds ip reservation writes to a
+// transaction so that inventory.ReconcileNodeIPs can be committed along with
+// the node
+type transactionStore struct {
+	*DynamoDBStore
+	tx *transaction
+}
+
+// WithActor keeps the transaction.  Reservation writes in the transaction
+// aren't recorded on their own, they're part of the node's history record.
+func (db *transactionStore) WithActor(actor string) inventory.Store {
+	return &transactionStore{DynamoDBStore: db.DynamoDBStore.withActor(actor), tx: db.tx}
+}
+
+func (db *transactionStore) IPReservation() inventory.IPReservationStore {
+	return &transactionIPReservationStore{IPReservationStore: &IPReservationStore{DynamoDBStore: db.DynamoDBStore}, tx: db.tx}
+}
+
+type transactionIPReservationStore struct {
+	*IPReservationStore
+	tx *transaction
+}
+
+func (db *transactionIPReservationStore) CreateRandomIPReservation(r *types.IPReservation, subnet *types.Subnet) (*types.IPReservation, error) {
+	return inventory.CreateRandomIPReservation(db, r, subnet)
+}
+
+// CreateIPReservation adds the reservation to the transaction if there's no
+// current reservation for the address.  A reservation that has ended is
+// replaced, and archived in the same transaction.  The conditions cancel the
+// transaction if the stored reservation changes before it's committed.
+func (db *transactionIPReservationStore) CreateIPReservation(r *types.IPReservation) error {
+	existing, err := db.storedReservation(r.IP)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		return db.tx.put(r, reservationCreateCondition, nil, nil)
+	}
+
+	now := time.Now()
+	if !existing.Ended(now) {
+		return ErrAlreadyExists
+	}
+
+	values, err := reservationConditionValues(r, now)
+	if err != nil {
+		return err
+	}
+
+	err = db.tx.put(r, reservationReplaceCondition, reservationConditionNames, map[string]*dynamodb.AttributeValue{":now": values[":now"]})
+	if err != nil {
+		return err
+	}
+	return db.archive(existing)
+}
+
+// archive adds a copy of the reservation, which has ended, to the archive in
+// the transaction.  As with AppendRevision, the timestamp is moved forward if
+// the transaction already archives a reservation for the mac at that time, and
+// the transaction is canceled if one was stored earlier.
+func (db *transactionIPReservationStore) archive(r *types.IPReservation) error {
+	if len(r.MAC) == 0 {
+		return nil
+	}
+
+	revision, err := types.NewArchivedIPReservationRevision(r, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = db.tx.put(revision, revisionAppendCondition, nil, nil)
+		if err != ErrAlreadyExists {
+			return err
+		}
+		revision.Timestamp = revision.Timestamp.Add(time.Nanosecond)
+	}
+}
+
+func (db *transactionIPReservationStore) UpdateIPReservation(r *types.IPReservation) error {
+	values, err := reservationConditionValues(r, time.Now())
+	if err != nil {
+		return err
+	}
+	return db.tx.put(r, reservationUpdateCondition, reservationConditionNames, values)
+}
+
+func (db *transactionIPReservationStore) CreateOrUpdateIPReservation(r *types.IPReservation) error {
+	exists, err := db.Exists(r)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return db.UpdateIPReservation(r)
+	}
+	return db.CreateIPReservation(r)
+}
+
+// Delete marks the current reservation for the address as deleted, if there
+// is one
+func (db *transactionIPReservationStore) Delete(r *types.IPReservation) error {
+	existing, err := db.GetIPReservation(r.IP)
+	if err == ErrObjectNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	existing.Deleted = &now
+	return db.UpdateIPReservation(existing)
+}
+
+func (db *transactionIPReservationStore) ObjCreate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.CreateIPReservation(r)
+}
+
+func (db *transactionIPReservationStore) ObjUpdate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.UpdateIPReservation(r)
+}
+
+func (db *transactionIPReservationStore) ObjDelete(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.Delete(r)
+}
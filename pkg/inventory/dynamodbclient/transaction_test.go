@@ -0,0 +1,130 @@
+package dynamodbclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dynamodbtest "github.com/PolarGeospatialCenter/dockertest/pkg/dynamodb"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestTransactionDuplicateWrites(t *testing.T) {
+	tx := newTransaction(NewDynamoDBStore(nil, nil))
+
+	err := tx.put(&types.Node{InventoryID: "node0001"}, "", nil, nil)
+	if err != nil {
+		t.Fatalf("unable to add put: %v", err)
+	}
+
+	err = tx.delete(&types.Node{InventoryID: "node0001"}, "", nil, nil)
+	if err != ErrAlreadyExists {
+		t.Errorf("expected second write to the same item to fail, got: %v", err)
+	}
+
+	err = tx.put(&types.System{Name: "node0001"}, "", nil, nil)
+	if err != nil {
+		t.Errorf("items with the same key in different tables should be allowed: %v", err)
+	}
+
+	if len(tx.items) != 2 {
+		t.Errorf("unexpected number of items in transaction: %d", len(tx.items))
+	}
+}
+
+func TestTransactionItemLimit(t *testing.T) {
+	requests := 0
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer endpoint.Close()
+
+	db := dynamodb.New(session.New(&aws.Config{
+		Endpoint:    aws.String(endpoint.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+	inv := NewDynamoDBStore(db, nil)
+
+	for _, c := range []struct {
+		items    int
+		expected error
+		requests int
+	}{
+		{maxTransactionItems, nil, 1},
+		{maxTransactionItems + 1, ErrTooManyChanges, 0},
+	} {
+		requests = 0
+		tx := newTransaction(inv)
+		for i := 0; i < c.items; i++ {
+			err := tx.put(&types.Node{InventoryID: fmt.Sprintf("node%04d", i)}, "", nil, nil)
+			if err != nil {
+				t.Fatalf("unable to add put: %v", err)
+			}
+		}
+
+		err := tx.commit()
+		if err != c.expected || requests != c.requests {
+			t.Errorf("committing %d items: expected %v after %d requests, got %v after %d", c.items, c.expected, c.requests, err, requests)
+		}
+	}
+}
+
+func TestNodeWriteIsAtomic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbInstance, err := dynamodbtest.Run(ctx)
+	if err != nil {
+		t.Errorf("unable to start dynamodb: %v", err)
+	}
+	defer dbInstance.Stop(ctx)
+
+	db := dynamodb.New(session.New(dbInstance.Config()))
+	inv := NewDynamoDBStore(db, nil)
+
+	err = inv.InitializeTables()
+	if err != nil {
+		t.Errorf("unable to initialize tables: %v", err)
+	}
+
+	err = inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	// one mac index entry per nic, plus the node, is more than a transaction can hold
+	nics := []net.HardwareAddr{}
+	for i := 0; i < maxTransactionItems; i++ {
+		mac, _ := net.ParseMAC(fmt.Sprintf("00:01:02:03:04:%02x", i))
+		nics = append(nics, mac)
+	}
+
+	node := &types.Node{
+		InventoryID: "node0001",
+		Networks:    types.NICInfoMap{"testnet": &types.NetworkInterface{NICs: nics}},
+	}
+	err = inv.Node().Create(node)
+	if err == nil {
+		t.Fatalf("expected create to fail")
+	}
+
+	exists, err := inv.Node().Exists(node)
+	if err != nil || exists {
+		t.Errorf("node should not exist after failed create: %v", err)
+	}
+
+	entries, err := inv.nodeMacIndex().GetMacIndexEntriesByNodeID(node.ID())
+	if err != nil || len(entries) != 0 {
+		t.Errorf("mac index entries should not exist after failed create: %v, %v", entries, err)
+	}
+}
@@ -1,8 +1,7 @@
 package dynamodbclient
 
 import (
-	"errors"
-
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
@@ -17,9 +16,13 @@ type DynamoDBStoreTable interface {
 	GetItemQueryInputFrom(interface{}) (*dynamodb.QueryInput, error)
 }
 
+// Errors are shared with the other inventory backends so that callers can
+// compare against either package
 var (
-	ErrObjectNotFound    = errors.New("Object not found")
-	ErrUpdateConflict    = errors.New("Unable to update object due to conflict")
-	ErrAlreadyExists     = errors.New("Unable to create. Object already exists")
-	ErrInvalidObjectType = errors.New("Unsupported object type")
+	ErrObjectNotFound    = inventory.ErrObjectNotFound
+	ErrUpdateConflict    = inventory.ErrUpdateConflict
+	ErrAlreadyExists     = inventory.ErrAlreadyExists
+	ErrInvalidObjectType = inventory.ErrInvalidObjectType
+	ErrVersionMismatch   = inventory.ErrVersionMismatch
+	ErrTooManyChanges    = inventory.ErrTooManyChanges
 )
@@ -0,0 +1,128 @@
+package dynamodbclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// WebhookStore manages webhook subscriptions
+type WebhookStore struct {
+	*DynamoDBStore
+}
+
+func (db *WebhookStore) GetWebhooks() (map[string]*types.Webhook, error) {
+	hookList := make([]*types.Webhook, 0, 0)
+	err := db.getAll(&hookList)
+	if err != nil {
+		return nil, fmt.Errorf("error getting all webhooks: %v", err)
+	}
+	hooks := make(map[string]*types.Webhook)
+	for _, s := range hookList {
+		hooks[s.ID()] = s
+	}
+	return hooks, nil
+}
+
+// ListWebhooks returns a page of webhooks
+func (db *WebhookStore) ListWebhooks(opts inventory.ListOptions) ([]*types.Webhook, string, error) {
+	hooks := make([]*types.Webhook, 0)
+	next, err := db.listPage(&hooks, opts, func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		hook := &types.Webhook{}
+		err := dynamodbattribute.UnmarshalMap(item, hook)
+		if err != nil {
+			return false, err
+		}
+		hooks = append(hooks, hook)
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing webhooks: %v", err)
+	}
+	return hooks, next, nil
+}
+
+func (db *WebhookStore) GetWebhookByID(id string) (*types.Webhook, error) {
+	hook := &types.Webhook{}
+	hook.Name = id
+	err := db.DynamoDBStore.get(hook)
+	return hook, err
+}
+
+func (db *WebhookStore) Exists(hook *types.Webhook) (bool, error) {
+	return db.DynamoDBStore.exists(hook)
+}
+
+func (db *WebhookStore) Create(hook *types.Webhook) error {
+	return db.DynamoDBStore.create(hook)
+}
+
+func (db *WebhookStore) Update(hook *types.Webhook) error {
+	return db.DynamoDBStore.update(hook)
+}
+
+func (db *WebhookStore) Delete(hook *types.Webhook) error {
+	return db.DynamoDBStore.delete(hook)
+}
+
+// UpdateIfVersion updates the webhook if the stored copy is at version
+func (db *WebhookStore) UpdateIfVersion(hook *types.Webhook, version time.Time) error {
+	return db.DynamoDBStore.updateIfVersion(hook, &types.Webhook{Name: hook.ID()}, version)
+}
+
+// DeleteIfVersion deletes the webhook if the stored copy is at version
+func (db *WebhookStore) DeleteIfVersion(hook *types.Webhook, version time.Time) error {
+	return db.DynamoDBStore.deleteIfVersion(hook, &types.Webhook{Name: hook.ID()}, version)
+}
+
+func (db *WebhookStore) ObjDelete(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.Delete(hook)
+}
+
+func (db *WebhookStore) ObjCreate(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.Create(hook)
+}
+
+func (db *WebhookStore) ObjUpdate(obj interface{}) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.Update(hook)
+}
+
+func (db *WebhookStore) ObjExists(obj interface{}) (bool, error) {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return false, ErrInvalidObjectType
+	}
+	return db.Exists(hook)
+}
+
+func (db *WebhookStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(hook, version)
+}
+
+func (db *WebhookStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	hook, ok := obj.(*types.Webhook)
+	if !ok {
+		return ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(hook, version)
+}
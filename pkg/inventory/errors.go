@@ -0,0 +1,20 @@
+package inventory
+
+import "errors"
+
+var (
+	ErrObjectNotFound    = errors.New("Object not found")
+	ErrUpdateConflict    = errors.New("Unable to update object due to conflict")
+	ErrAlreadyExists     = errors.New("Unable to create. Object already exists")
+	ErrInvalidObjectType = errors.New("Unsupported object type")
+	ErrInvalidCursor     = errors.New("Invalid cursor")
+	ErrVersionMismatch   = errors.New("Object has been modified since the requested version")
+
+	// ErrTooManyChanges is returned when a write would change more records
+	// than the backend can change atomically, ie: a node with more static
+	// ip reservations than fit in one dynamodb transaction.  Nothing is
+	// written, and the change has to be split into smaller ones.
+	ErrTooManyChanges = errors.New("Too many records would be changed by a single write")
+
+	ErrUnknownAllocationMethod = errors.New("Unknown allocation method")
+)
@@ -0,0 +1,191 @@
+// Package gitstore provides a read-only inventory source backed by a git
+// repository containing yaml files for nodes, networks and systems.
+package gitstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ inventory.Source = &GitStore{}
+
+const (
+	nodeDir    = "node"
+	networkDir = "network"
+	systemDir  = "system"
+)
+
+// GitStore reads inventory objects from a branch of a git repository.  Each
+// object is stored in its own yaml file under the node, network or system
+// directory.
+type GitStore struct {
+	repo         *git.Repository
+	fetchOptions *git.FetchOptions
+	branch       string
+	tree         *object.Tree
+}
+
+// NewGitStore creates a GitStore that reads from the named branch of repo.
+// Refresh must be called before any objects can be read.
+func NewGitStore(repo *git.Repository, fetchOptions *git.FetchOptions, branch string) *GitStore {
+	if fetchOptions == nil {
+		fetchOptions = &git.FetchOptions{}
+	}
+	return &GitStore{repo: repo, fetchOptions: fetchOptions, branch: branch}
+}
+
+// Refresh fetches from the remote, if one is configured, and loads the tree at
+// the tip of the branch.
+func (s *GitStore) Refresh() error {
+	err := s.repo.Fetch(s.fetchOptions)
+	if err != nil && err != git.NoErrAlreadyUpToDate && err != git.ErrRemoteNotFound {
+		return fmt.Errorf("unable to fetch: %v", err)
+	}
+
+	ref, err := s.branchReference()
+	if err != nil {
+		return err
+	}
+
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("unable to get commit for branch %s: %v", s.branch, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("unable to get tree for commit %s: %v", commit.Hash, err)
+	}
+	s.tree = tree
+	return nil
+}
+
+// branchReference prefers the remote tracking branch, falling back to the
+// local branch for repositories without a remote.
+func (s *GitStore) branchReference() (*plumbing.Reference, error) {
+	remoteName := s.fetchOptions.RemoteName
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+
+	ref, err := s.repo.Reference(plumbing.NewRemoteReferenceName(remoteName, s.branch), true)
+	if err == nil {
+		return ref, nil
+	}
+
+	ref, err = s.repo.Reference(plumbing.NewBranchReferenceName(s.branch), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find branch %s: %v", s.branch, err)
+	}
+	return ref, nil
+}
+
+// unmarshalFiles calls newObj for each yaml file in dir and unmarshals the
+// file into the returned object.
+func (s *GitStore) unmarshalFiles(dir string, newObj func() interface{}) error {
+	if s.tree == nil {
+		return fmt.Errorf("git store has not been refreshed")
+	}
+
+	return s.tree.Files().ForEach(func(f *object.File) error {
+		if path.Dir(f.Name) != dir {
+			return nil
+		}
+
+		ext := path.Ext(f.Name)
+		if ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.Unmarshal(data, newObj())
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %v", f.Name, err)
+		}
+		return nil
+	})
+}
+
+// GetNodes returns all nodes in the repository
+func (s *GitStore) GetNodes() (map[string]*types.Node, error) {
+	nodes := make([]*types.Node, 0)
+	err := s.unmarshalFiles(nodeDir, func() interface{} {
+		node := types.NewNode()
+		nodes = append(nodes, node)
+		return node
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.Node, len(nodes))
+	for _, node := range nodes {
+		if _, ok := result[node.ID()]; ok {
+			return nil, fmt.Errorf("duplicate node id: %s", node.ID())
+		}
+		result[node.ID()] = node
+	}
+	return result, nil
+}
+
+// GetNetworks returns all networks in the repository
+func (s *GitStore) GetNetworks() (map[string]*types.Network, error) {
+	networks := make([]*types.Network, 0)
+	err := s.unmarshalFiles(networkDir, func() interface{} {
+		network := types.NewNetwork()
+		networks = append(networks, network)
+		return network
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.Network, len(networks))
+	for _, network := range networks {
+		if _, ok := result[network.ID()]; ok {
+			return nil, fmt.Errorf("duplicate network id: %s", network.ID())
+		}
+		result[network.ID()] = network
+	}
+	return result, nil
+}
+
+// GetSystems returns all systems in the repository
+func (s *GitStore) GetSystems() (map[string]*types.System, error) {
+	systems := make([]*types.System, 0)
+	err := s.unmarshalFiles(systemDir, func() interface{} {
+		system := types.NewSystem()
+		systems = append(systems, system)
+		return system
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.System, len(systems))
+	for _, system := range systems {
+		if _, ok := result[system.ID()]; ok {
+			return nil, fmt.Errorf("duplicate system id: %s", system.ID())
+		}
+		result[system.ID()] = system
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package gitstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+func TestGitStoreLocal(t *testing.T) {
+	repo, err := git.PlainOpen("../../../test/data/gitstore_1")
+	if err != nil {
+		t.Fatalf("Unable to open test repo: %v", err)
+	}
+
+	store := NewGitStore(repo, nil, "master")
+	err = store.Refresh()
+	if err != nil {
+		t.Fatalf("Unable to refresh git store: %v", err)
+	}
+
+	nodes, err := store.GetNodes()
+	if err != nil {
+		t.Fatalf("Unable to get nodes: %v", err)
+	}
+	if len(nodes) != 3 {
+		t.Errorf("Expected 3 nodes, got %d", len(nodes))
+	}
+
+	node, ok := nodes["node0003"]
+	if !ok {
+		t.Fatalf("node0003 not found: %v", nodes)
+	}
+	if mac := node.Networks["phy_provision"].NICs[0].String(); mac != "00:25:90:7e:a2:f4" {
+		t.Errorf("Wrong mac for node0003 on phy_provision: %s", mac)
+	}
+	if len(node.Tags) != 3 {
+		t.Errorf("Wrong tags for node0003: %v", node.Tags)
+	}
+
+	networks, err := store.GetNetworks()
+	if err != nil {
+		t.Fatalf("Unable to get networks: %v", err)
+	}
+	if len(networks) != 5 {
+		t.Errorf("Expected 5 networks, got %d", len(networks))
+	}
+	if _, ok := networks["phy_provision"]; !ok {
+		t.Errorf("phy_provision network not found: %v", networks)
+	}
+
+	systems, err := store.GetSystems()
+	if err != nil {
+		t.Fatalf("Unable to get systems: %v", err)
+	}
+	if sys, ok := systems["foosys"]; !ok || len(sys.Environments) != 2 {
+		t.Errorf("foosys system not loaded correctly: %v", systems)
+	}
+}
+
+func TestGitStoreRefreshFromRemote(t *testing.T) {
+	remotePath, err := filepath.Abs("../../../test/data/gitstore_2")
+	if err != nil {
+		t.Fatalf("Unable to find remote repo: %v", err)
+	}
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatalf("Unable to init repo: %v", err)
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remotePath}})
+	if err != nil {
+		t.Fatalf("Unable to create remote: %v", err)
+	}
+
+	store := NewGitStore(repo, &git.FetchOptions{}, "master")
+	err = store.Refresh()
+	if err != nil {
+		t.Fatalf("Unable to refresh git store: %v", err)
+	}
+
+	nodes, err := store.GetNodes()
+	if err != nil {
+		t.Fatalf("Unable to get nodes: %v", err)
+	}
+
+	if mac := nodes["node0003"].Networks["phy_provision"].NICs[0].String(); mac != "00:25:90:7e:a2:f5" {
+		t.Errorf("Expected fixed mac for node0003, got %s", mac)
+	}
+}
+
+func TestGitStoreNotRefreshed(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatalf("Unable to init repo: %v", err)
+	}
+
+	_, err = NewGitStore(repo, nil, "master").GetNodes()
+	if err == nil {
+		t.Errorf("Expected an error reading from a store that hasn't been refreshed")
+	}
+}
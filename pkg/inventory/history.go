@@ -0,0 +1,24 @@
+package inventory
+
+import (
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// NewChangeRecord creates the history record of a change to an object made by
+// actor, for stores that write the record along with the change.  before is
+// nil for creates and after is nil for deletes.  No record is created for
+// changes without an actor, or deletes of objects that didn't exist.
+func NewChangeRecord(actor string, objectType string, objectID string, before interface{}, after interface{}) (*types.HistoryRecord, error) {
+	if actor == "" || (before == nil && after == nil) {
+		return nil, nil
+	}
+
+	action := types.HistoryActionUpdate
+	switch {
+	case before == nil:
+		action = types.HistoryActionCreate
+	case after == nil:
+		action = types.HistoryActionDelete
+	}
+	return types.NewHistoryRecord(objectType, objectID, action, actor, before, after)
+}
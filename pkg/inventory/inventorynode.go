@@ -0,0 +1,200 @@
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// NewInventoryNodeStore returns an InventoryNodeStore that compiles
+// InventoryNodes from the records held in store
+func NewInventoryNodeStore(store Store) InventoryNodeStore {
+	return &inventoryNodeStore{Store: store}
+}
+
+type inventoryNodeStore struct {
+	Store
+}
+
+func (db *inventoryNodeStore) GetInventoryNodes() (map[string]*types.InventoryNode, error) {
+	nodes, err := db.Node().GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to lookup nodes: %v", err)
+	}
+
+	networks, err := db.Network().GetNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("unable to lookup networks: %v", err)
+	}
+
+	systems, err := db.System().GetSystems()
+	if err != nil {
+		return nil, fmt.Errorf("unable to lookup systems: %v", err)
+	}
+
+	ipResrvations, err := db.IPReservation().GetAllIPReservations()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get all ip reservations: %v", err)
+	}
+
+	ipReservationMap := make(types.IPReservationMap, len(ipResrvations))
+	for _, r := range ipResrvations {
+		ipReservationMap.Add(r)
+	}
+
+	out := make(map[string]*types.InventoryNode)
+	for _, n := range nodes {
+		iNode, err := types.NewInventoryNode(n, types.NetworkMap(networks), types.SystemMap(systems), ipReservationMap)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile inventory node: %v", err)
+		}
+		out[n.ID()] = iNode
+	}
+	return out, nil
+}
+
+// ListInventoryNodes compiles a page of nodes.  Networks and systems are
+// loaded once, while reservations are looked up for each node on the page.
+func (db *inventoryNodeStore) ListInventoryNodes(opts ListOptions) ([]*types.InventoryNode, string, error) {
+	nodes, next, err := db.Node().ListNodes(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	networks, err := db.Network().GetNetworks()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to lookup networks: %v", err)
+	}
+
+	systems, err := db.System().GetSystems()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to lookup systems: %v", err)
+	}
+
+	out := make([]*types.InventoryNode, 0, len(nodes))
+	for _, n := range nodes {
+		iNode, err := types.NewInventoryNode(n, types.NetworkMap(networks), types.SystemMap(systems), db.IPReservation())
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to compile inventory node: %v", err)
+		}
+		out = append(out, iNode)
+	}
+	return out, next, nil
+}
+
+func (db *inventoryNodeStore) GetInventoryNodeByID(id string) (*types.InventoryNode, error) {
+	node, err := db.Node().GetNodeByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewInventoryNode(node, db.Network(), db.System(), db.IPReservation())
+}
+
+func (db *inventoryNodeStore) GetInventoryNodeByMAC(mac net.HardwareAddr) (*types.InventoryNode, error) {
+	node, err := db.Node().GetNodeByMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewInventoryNode(node, db.Network(), db.System(), db.IPReservation())
+}
+
+// GetInventoryNodeByIDAt compiles the node as it would have been compiled at a
+// past time, from the revisions of the node, its system and networks at that
+// time and the reservations that were valid at that time.
+func (db *inventoryNodeStore) GetInventoryNodeByIDAt(id string, at time.Time) (*types.InventoryNode, error) {
+	view := &pointInTimeView{store: db.Store, at: at}
+	node, err := view.GetNodeByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewInventoryNode(node, view, view, view)
+}
+
+// pointInTimeView looks up nodes, networks, systems and reservations as they
+// were at a past time
+type pointInTimeView struct {
+	store Store
+	at    time.Time
+}
+
+// revisionAt unmarshals the revision of an object at v.at into out, returning
+// false if the object has no revisions that old.  Objects that were deleted
+// at v.at aren't found.
+func (v *pointInTimeView) revisionAt(objectType string, id string, out interface{}) (bool, error) {
+	r, err := v.store.Revision().GetRevisionAt(objectType, id, v.at)
+	switch {
+	case err == ErrObjectNotFound:
+		return false, nil
+	case err != nil:
+		return false, err
+	case r.Deleted:
+		return false, ErrObjectNotFound
+	}
+	return true, r.Unmarshal(out)
+}
+
+// existedAt checks that the current copy of an object without revisions,
+// which was stored before revisions were kept, was already stored at v.at
+func (v *pointInTimeView) existedAt(obj versioned, err error) error {
+	if err == nil && obj.Version().After(v.at) {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func (v *pointInTimeView) GetNodeByID(id string) (*types.Node, error) {
+	node := &types.Node{}
+	found, err := v.revisionAt(types.HistoryObjectNode, id, node)
+	if err != nil {
+		return nil, err
+	} else if found {
+		return node, nil
+	}
+
+	node, err = v.store.Node().GetNodeByID(id)
+	if err = v.existedAt(node, err); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (v *pointInTimeView) GetNetworkByID(id string) (*types.Network, error) {
+	network := &types.Network{}
+	found, err := v.revisionAt(types.HistoryObjectNetwork, id, network)
+	if err != nil {
+		return nil, err
+	} else if found {
+		return network, nil
+	}
+
+	network, err = v.store.Network().GetNetworkByID(id)
+	if err = v.existedAt(network, err); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+func (v *pointInTimeView) GetSystemByID(id string) (*types.System, error) {
+	system := &types.System{}
+	found, err := v.revisionAt(types.HistoryObjectSystem, id, system)
+	if err != nil {
+		return nil, err
+	} else if found {
+		return system, nil
+	}
+
+	system, err = v.store.System().GetSystemByID(id)
+	if err = v.existedAt(system, err); err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+func (v *pointInTimeView) GetIPReservationsByMac(mac net.HardwareAddr) (types.IPReservationList, error) {
+	return v.store.IPReservation().GetIPReservationsByMacAt(mac, v.at)
+}
@@ -0,0 +1,87 @@
+package inventory_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestGetInventoryNodeByIDAt(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	created := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	updated := created.Add(time.Hour)
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	network := &types.Network{Name: "provisioning", Subnets: []*types.Subnet{{Cidr: subnet}}, LastUpdated: created}
+	system := &types.System{
+		Name:         "test",
+		ShortName:    "tst",
+		Roles:        []string{"worker"},
+		Environments: map[string]*types.Environment{"prod": {IPXEUrl: "http://old/ipxe", Networks: map[string]string{"provisioning": "provisioning"}}},
+		LastUpdated:  created,
+	}
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := &types.Node{
+		InventoryID: "node0001",
+		System:      "tst",
+		Role:        "worker",
+		Environment: "prod",
+		Networks:    types.NICInfoMap{"provisioning": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}},
+		LastUpdated: created,
+	}
+
+	for _, err := range []error{inv.Network().Create(network), inv.System().Create(system), inv.Node().Create(node)} {
+		if err != nil {
+			t.Fatalf("unable to create test records: %v", err)
+		}
+	}
+
+	reservation := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, MAC: mac, Start: &created}
+	err := inv.IPReservation().CreateIPReservation(reservation)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	system.Environments["prod"].IPXEUrl = "http://new/ipxe"
+	system.LastUpdated = updated
+	err = inv.System().Update(system)
+	if err != nil {
+		t.Fatalf("unable to update system: %v", err)
+	}
+
+	err = inv.IPReservation().Delete(reservation)
+	if err != nil {
+		t.Fatalf("unable to delete reservation: %v", err)
+	}
+
+	_, err = inv.InventoryNode().GetInventoryNodeByIDAt("node0001", created.Add(-time.Minute))
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected the node not to exist before it was created, got: %v", err)
+	}
+
+	past, err := inv.InventoryNode().GetInventoryNodeByIDAt("node0001", created.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("unable to compile node as it was: %v", err)
+	}
+
+	if past.Environment.IPXEUrl != "http://old/ipxe" {
+		t.Errorf("expected the environment as it was before the system was updated, got %s", past.Environment.IPXEUrl)
+	}
+
+	if ips := past.Networks["provisioning"].Config.IP; len(ips) != 1 || ips[0] != "10.0.0.10/24" {
+		t.Errorf("expected the ip reserved at the time, got %v", ips)
+	}
+
+	current, err := inv.InventoryNode().GetInventoryNodeByIDAt("node0001", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unable to compile current node: %v", err)
+	}
+
+	if current.Environment.IPXEUrl != "http://new/ipxe" || len(current.Networks["provisioning"].Config.IP) != 0 {
+		t.Errorf("expected the current configuration, got %s %v", current.Environment.IPXEUrl, current.Networks["provisioning"].Config.IP)
+	}
+}
@@ -0,0 +1,97 @@
+package inventory
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
+)
+
+// nonEmptyPools returns the pools of the subnet that contain at least one
+// address
+func nonEmptyPools(subnet *types.Subnet) []ipam.IPRange {
+	pools := make([]ipam.IPRange, 0, len(subnet.Pools()))
+	for _, pool := range subnet.Pools() {
+		if ipam.CompareIP(pool.Start, pool.Last()) <= 0 {
+			pools = append(pools, pool)
+		}
+	}
+	return pools
+}
+
+// lowestFreeIP returns the lowest address in the subnet's pools that isn't
+// excluded or reserved
+func lowestFreeIP(subnet *types.Subnet, existing types.IPReservationList) (net.IP, error) {
+	free := subnet.FreeIPs(existing, 1)
+	if len(free) == 0 {
+		return nil, fmt.Errorf("this subnet is full, cannot allocate an address")
+	}
+	return free[0], nil
+}
+
+// maxRandomAttempts is the number of random addresses tried before falling
+// back to the lowest free address in the subnet
+const maxRandomAttempts = 1000
+
+// CreateRandomIPReservation reserves a random free address in the subnet's
+// pools for the reservation.  It relies on CreateIPReservation failing with
+// ErrAlreadyExists to detect addresses that were reserved concurrently.
+func CreateRandomIPReservation(store IPReservationStore, r *types.IPReservation, subnet *types.Subnet) (*types.IPReservation, error) {
+	maxCount := 10
+	reservation := *r
+	for count := 0; count < maxCount; count++ {
+		existingReservations, err := store.GetIPReservations(subnet.Cidr)
+		if err != nil {
+			return nil, err
+		}
+
+		startOffset, ipLength := subnet.Cidr.Mask.Size()
+		if subnet.Cidr.IP.To4() != nil && len(existingReservations) >= (1<<uint(ipLength-startOffset)-2) {
+			return nil, fmt.Errorf("this subnet is full, cannot allocate an address")
+		}
+
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+		pools := nonEmptyPools(subnet)
+		if len(pools) == 0 {
+			return nil, fmt.Errorf("this subnet has no addresses that can be allocated")
+		}
+
+		reservation.IP = &net.IPNet{Mask: subnet.Cidr.Mask}
+		if reservation.Start == nil {
+			start := time.Now()
+			reservation.Start = &start
+		}
+
+		// generate random IP in one of the subnet's pools
+		// Check to see if reservation list contains a reservation for it, or it's excluded
+		// if it's in the list of reserved addresses, try again
+		// if it's not in the list of addresses, try to reserve it
+		// if we haven't found a free address after a while, fall back to the lowest free address
+		for attempt := 0; ; attempt++ {
+			var candidateIP net.IP
+			if attempt < maxRandomAttempts {
+				candidateIP = pools[rnd.Intn(len(pools))].RandomIP(rnd)
+			} else {
+				candidateIP, err = lowestFreeIP(subnet, existingReservations)
+				if err != nil {
+					return nil, err
+				}
+			}
+			reservation.IP.IP = candidateIP
+
+			if !reservation.Validate() || !subnet.Allocatable(candidateIP) || existingReservations.Contains(candidateIP) {
+				continue
+			}
+
+			err = store.CreateIPReservation(&reservation)
+			if err != nil && err == ErrAlreadyExists {
+				break
+			}
+			return &reservation, err
+		}
+	}
+	return nil, fmt.Errorf("retry limit exceeded: giving up on reserving an ip for %v", r)
+}
@@ -0,0 +1,129 @@
+package inventory
+
+import (
+	"encoding/base64"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ListOptions selects a page of objects from a list.  Next is the opaque
+// cursor returned with the previous page.  A Limit of 0 returns all remaining
+// objects.  A page may hold fewer than Limit objects while more remain, so
+// callers should keep listing until no cursor is returned.
+type ListOptions struct {
+	Limit  int
+	Next   string
+	Filter NodeFilter
+}
+
+// NodeFilter selects nodes by attribute.  Empty fields match every node.
+type NodeFilter struct {
+	System      string
+	Role        string
+	Environment string
+	Tag         string
+	Rack        string
+}
+
+// IsEmpty returns true if the filter matches every node
+func (f NodeFilter) IsEmpty() bool {
+	return f == NodeFilter{}
+}
+
+// Match returns true if the node matches all fields set in the filter
+func (f NodeFilter) Match(n *types.Node) bool {
+	switch {
+	case f.System != "" && n.System != f.System:
+		return false
+	case f.Role != "" && n.Role != f.Role:
+		return false
+	case f.Environment != "" && n.Environment != f.Environment:
+		return false
+	case f.Rack != "" && (n.ChassisLocation == nil || n.Rack != f.Rack):
+		return false
+	}
+
+	if f.Tag == "" {
+		return true
+	}
+	for _, tag := range n.Tags {
+		if tag == f.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeCursor makes an opaque cursor from a backend specific position
+func EncodeCursor(position []byte) string {
+	return base64.RawURLEncoding.EncodeToString(position)
+}
+
+// DecodeCursor returns the position encoded in cursor
+func DecodeCursor(cursor string) ([]byte, error) {
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return position, nil
+}
+
+// Pager selects a page of objects for backends that iterate over objects in
+// key order.  Objects are offered to the pager in order with Add until Done
+// returns true.
+type Pager struct {
+	limit int
+	after string
+	count int
+	last  string
+	next  string
+	done  bool
+}
+
+// NewPager creates a Pager for the page described by opts
+func NewPager(opts ListOptions) (*Pager, error) {
+	p := &Pager{limit: opts.Limit}
+	if opts.Next != "" {
+		after, err := DecodeCursor(opts.Next)
+		if err != nil {
+			return nil, err
+		}
+		p.after = string(after)
+	}
+	return p, nil
+}
+
+// After returns the key of the last object on the previous page
+func (p *Pager) After() string {
+	return p.after
+}
+
+// Add returns true if the object with key belongs on the page.  Offering an
+// object once the page is full marks the pager as done and sets the cursor
+// for the next page.
+func (p *Pager) Add(key string) bool {
+	if p.done || (p.after != "" && key <= p.after) {
+		return false
+	}
+
+	if p.limit > 0 && p.count >= p.limit {
+		p.next = EncodeCursor([]byte(p.last))
+		p.done = true
+		return false
+	}
+
+	p.count++
+	p.last = key
+	return true
+}
+
+// Done returns true once the page is full and another object was offered
+func (p *Pager) Done() bool {
+	return p.done
+}
+
+// Next returns the cursor for the next page, or an empty string if this is
+// the last page
+func (p *Pager) Next() string {
+	return p.next
+}
@@ -0,0 +1,86 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestNodeFilterMatch(t *testing.T) {
+	node := &types.Node{
+		InventoryID:     "node0001",
+		System:          "foosys",
+		Role:            "worker",
+		Environment:     "prod",
+		Tags:            types.Tags{"gpu", "ib"},
+		ChassisLocation: &types.ChassisLocation{Rack: "gh21"},
+	}
+
+	cases := map[inventory.NodeFilter]bool{
+		inventory.NodeFilter{}:                                  true,
+		inventory.NodeFilter{System: "foosys", Role: "worker"}:  true,
+		inventory.NodeFilter{Environment: "prod", Tag: "ib"}:    true,
+		inventory.NodeFilter{Rack: "gh21"}:                      true,
+		inventory.NodeFilter{System: "foosys", Role: "master"}:  false,
+		inventory.NodeFilter{Tag: "fpga"}:                       false,
+		inventory.NodeFilter{Rack: "gh22"}:                      false,
+		inventory.NodeFilter{Environment: "dev", System: "bar"}: false,
+	}
+
+	for filter, expected := range cases {
+		if filter.Match(node) != expected {
+			t.Errorf("expected match to return %t for filter %+v", expected, filter)
+		}
+	}
+
+	if (inventory.NodeFilter{Rack: "gh21"}).Match(&types.Node{}) {
+		t.Errorf("node without a chassis location shouldn't match a rack filter")
+	}
+}
+
+func TestPager(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	page := func(opts inventory.ListOptions) ([]string, string) {
+		pager, err := inventory.NewPager(opts)
+		if err != nil {
+			t.Fatalf("unable to create pager: %v", err)
+		}
+		selected := []string{}
+		for _, key := range keys {
+			if pager.Add(key) {
+				selected = append(selected, key)
+			}
+		}
+		return selected, pager.Next()
+	}
+
+	all := []string{}
+	opts := inventory.ListOptions{Limit: 2}
+	pages := 0
+	for {
+		selected, next := page(opts)
+		all = append(all, selected...)
+		pages++
+		if next == "" {
+			break
+		}
+		opts.Next = next
+	}
+
+	if pages != 3 || len(all) != len(keys) {
+		t.Errorf("unexpected pages: %d pages, %v", pages, all)
+	}
+
+	// an exactly full last page shouldn't return a cursor
+	selected, next := page(inventory.ListOptions{Limit: 5})
+	if len(selected) != 5 || next != "" {
+		t.Errorf("unexpected full page: %v, next: '%s'", selected, next)
+	}
+
+	_, err := inventory.NewPager(inventory.ListOptions{Next: "not a cursor!"})
+	if err != inventory.ErrInvalidCursor {
+		t.Errorf("expected invalid cursor error, got: %v", err)
+	}
+}
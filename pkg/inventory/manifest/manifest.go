@@ -0,0 +1,185 @@
+// Package manifest loads the desired state of the inventory from yaml files.
+//
+// Each file may contain several yaml documents.  A document's kind is taken
+// from its "kind" key, one of node, network or system, and otherwise from the
+// name of the directory containing the file, so the layout used by the git
+// store can be loaded as well.
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ inventory.Source = &Manifest{}
+
+// Manifest holds the objects loaded from a set of yaml files
+type Manifest struct {
+	nodes    map[string]*types.Node
+	networks map[string]*types.Network
+	systems  map[string]*types.System
+}
+
+// NewManifest creates an empty Manifest
+func NewManifest() *Manifest {
+	return &Manifest{
+		nodes:    make(map[string]*types.Node),
+		networks: make(map[string]*types.Network),
+		systems:  make(map[string]*types.System),
+	}
+}
+
+// Load reads the manifests at the paths.  Directories are searched
+// recursively for files ending in .yml or .yaml.
+func Load(paths ...string) (*Manifest, error) {
+	m := NewManifest()
+	for _, path := range paths {
+		err := filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			ext := filepath.Ext(filename)
+			if filename != path && ext != ".yml" && ext != ".yaml" {
+				return nil
+			}
+
+			return m.LoadFile(filename)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// LoadFile adds the objects in filename to the manifest
+func (m *Manifest) LoadFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	defaultKind := kindFromDir(filepath.Base(filepath.Dir(filename)))
+	err = m.Add(bytes.NewReader(data), defaultKind)
+	if err != nil {
+		return fmt.Errorf("unable to load %s: %v", filename, err)
+	}
+	return nil
+}
+
+// Add decodes all yaml documents in r and adds them to the manifest.
+// defaultKind is used for documents without a kind key.
+func (m *Manifest) Add(r io.Reader, defaultKind string) error {
+	decoder := yaml.NewDecoder(r)
+	for {
+		doc := map[interface{}]interface{}{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if len(doc) == 0 {
+			continue
+		}
+
+		kind := defaultKind
+		if k, ok := doc["kind"]; ok {
+			kind = fmt.Sprintf("%v", k)
+			delete(doc, "kind")
+		}
+
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		err = m.addObject(kind, data)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Manifest) addObject(kind string, data []byte) error {
+	switch kind {
+	case "node":
+		node := types.NewNode()
+		err := yaml.Unmarshal(data, node)
+		if err != nil {
+			return err
+		}
+		if _, ok := m.nodes[node.ID()]; ok || node.ID() == "" {
+			return fmt.Errorf("missing or duplicate node id: '%s'", node.ID())
+		}
+		m.nodes[node.ID()] = node
+
+	case "network":
+		network := types.NewNetwork()
+		err := yaml.Unmarshal(data, network)
+		if err != nil {
+			return err
+		}
+		if _, ok := m.networks[network.ID()]; ok || network.ID() == "" {
+			return fmt.Errorf("missing or duplicate network id: '%s'", network.ID())
+		}
+		m.networks[network.ID()] = network
+
+	case "system":
+		system := types.NewSystem()
+		err := yaml.Unmarshal(data, system)
+		if err != nil {
+			return err
+		}
+		if _, ok := m.systems[system.ID()]; ok || system.ID() == "" {
+			return fmt.Errorf("missing or duplicate system id: '%s'", system.ID())
+		}
+		m.systems[system.ID()] = system
+
+	case "":
+		return fmt.Errorf("document has no kind")
+
+	default:
+		return fmt.Errorf("unknown kind: %s", kind)
+	}
+	return nil
+}
+
+// kindFromDir maps the directory names used by the git store to kinds
+func kindFromDir(dir string) string {
+	switch strings.TrimSuffix(dir, "s") {
+	case "node", "network", "system":
+		return strings.TrimSuffix(dir, "s")
+	}
+	return ""
+}
+
+// GetNodes returns all nodes in the manifest
+func (m *Manifest) GetNodes() (map[string]*types.Node, error) {
+	return m.nodes, nil
+}
+
+// GetNetworks returns all networks in the manifest
+func (m *Manifest) GetNetworks() (map[string]*types.Network, error) {
+	return m.networks, nil
+}
+
+// GetSystems returns all systems in the manifest
+func (m *Manifest) GetSystems() (map[string]*types.System, error) {
+	return m.systems, nil
+}
@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddMultipleDocuments(t *testing.T) {
+	m := NewManifest()
+	err := m.Add(strings.NewReader(`---
+kind: system
+name: foosys
+roles:
+  - worker
+---
+kind: network
+name: provision
+subnets:
+  - cidr: 10.0.0.0/24
+    gateway: 10.0.0.1
+---
+kind: node
+inventoryid: node0001
+system: foosys
+networks:
+  provision:
+    mac: 00:25:90:7e:a2:f3
+`), "")
+	if err != nil {
+		t.Fatalf("unable to load manifest: %v", err)
+	}
+
+	nodes, _ := m.GetNodes()
+	if node, ok := nodes["node0001"]; !ok || node.Networks["provision"].NICs[0].String() != "00:25:90:7e:a2:f3" {
+		t.Errorf("node not loaded correctly: %v", nodes)
+	}
+
+	networks, _ := m.GetNetworks()
+	if network, ok := networks["provision"]; !ok || network.Subnets[0].Cidr.String() != "10.0.0.0/24" {
+		t.Errorf("network not loaded correctly: %v", networks)
+	}
+
+	systems, _ := m.GetSystems()
+	if _, ok := systems["foosys"]; !ok {
+		t.Errorf("system not loaded: %v", systems)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing kind": "name: foo\n",
+		"unknown kind": "kind: rack\nname: foo\n",
+		"duplicate id": "kind: system\nname: foo\n---\nkind: system\nname: foo\n",
+		"missing id":   "kind: node\nrole: worker\n",
+	}
+
+	for name, doc := range cases {
+		err := NewManifest().Add(strings.NewReader(doc), "")
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "node"), 0755)
+	os.MkdirAll(filepath.Join(dir, "networks"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "node", "node0001.yml"), []byte("inventoryid: node0001\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "networks", "provision.yaml"), []byte("name: provision\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "networks", "README.md"), []byte("not yaml"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "systems.yml"), []byte("kind: system\nname: foosys\n"), 0644)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unable to load directory: %v", err)
+	}
+
+	if len(m.nodes) != 1 || len(m.networks) != 1 || len(m.systems) != 1 {
+		t.Errorf("unexpected objects loaded: %v %v %v", m.nodes, m.networks, m.systems)
+	}
+}
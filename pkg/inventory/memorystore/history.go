@@ -0,0 +1,78 @@
+package memorystore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type HistoryStore struct {
+	*MemoryStore
+}
+
+func historyKey(r *types.HistoryRecord) string {
+	return fmt.Sprintf("%s/%020d", r.Key(), r.Sequence())
+}
+
+// AppendHistory stores the record, moving its timestamp forward until it
+// doesn't collide with an existing record for the object
+func (db *HistoryStore) AppendHistory(r *types.HistoryRecord) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for {
+		if _, ok := db.history[historyKey(r)]; !ok {
+			break
+		}
+		r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+	}
+
+	av, err := dynamodbattribute.Marshal(r)
+	if err != nil {
+		return err
+	}
+	db.history[historyKey(r)] = av
+	return nil
+}
+
+// GetHistory returns a page of the changes to the object, oldest first
+func (db *HistoryStore) GetHistory(objectType string, objectID string, opts inventory.ListOptions) ([]*types.HistoryRecord, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	prefix := types.HistoryKey(objectType, objectID) + "/"
+	sequences := make([]string, 0)
+	for k := range db.history {
+		sequence := strings.TrimPrefix(k, prefix)
+		if strings.HasPrefix(k, prefix) && !strings.Contains(sequence, "/") {
+			sequences = append(sequences, sequence)
+		}
+	}
+	sort.Strings(sequences)
+
+	records := make([]*types.HistoryRecord, 0)
+	for _, sequence := range sequences {
+		if pager.Done() {
+			break
+		}
+		if !pager.Add(sequence) {
+			continue
+		}
+
+		r := &types.HistoryRecord{}
+		err := dynamodbattribute.Unmarshal(db.history[prefix+sequence], r)
+		if err != nil {
+			return nil, "", err
+		}
+		records = append(records, r)
+	}
+	return records, pager.Next(), nil
+}
@@ -0,0 +1,153 @@
+package memorystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/go-test/deep"
+)
+
+func TestHistory(t *testing.T) {
+	inv := NewMemoryStore()
+
+	timestamp := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i, role := range []string{"worker", "master", "storage"} {
+		r, err := types.NewHistoryRecord(types.HistoryObjectNode, "test", types.HistoryActionUpdate, "tester", &types.Node{InventoryID: "test", Role: fmt.Sprintf("role%d", i)}, &types.Node{InventoryID: "test", Role: role})
+		if err != nil {
+			t.Fatalf("unable to create history record: %v", err)
+		}
+		r.Timestamp = timestamp
+
+		err = inv.History().AppendHistory(r)
+		if err != nil {
+			t.Fatalf("unable to append history record: %v", err)
+		}
+	}
+
+	other, _ := types.NewHistoryRecord(types.HistoryObjectNode, "test2", types.HistoryActionCreate, "tester", nil, &types.Node{InventoryID: "test2"})
+	err := inv.History().AppendHistory(other)
+	if err != nil {
+		t.Fatalf("unable to append history record: %v", err)
+	}
+
+	records, next, err := inv.History().GetHistory(types.HistoryObjectNode, "test", inventory.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("unable to get history: %v", err)
+	}
+
+	if len(records) != 2 || next == "" {
+		t.Fatalf("expected the first two records and a cursor, got %d records, cursor '%s'", len(records), next)
+	}
+
+	rest, next, err := inv.History().GetHistory(types.HistoryObjectNode, "test", inventory.ListOptions{Limit: 2, Next: next})
+	if err != nil {
+		t.Fatalf("unable to get next page of history: %v", err)
+	}
+
+	if len(rest) != 1 || next != "" {
+		t.Fatalf("expected the last record and no cursor, got %d records, cursor '%s'", len(rest), next)
+	}
+	records = append(records, rest...)
+
+	for i, role := range []string{"worker", "master", "storage"} {
+		if !records[i].Timestamp.Equal(timestamp.Add(time.Duration(i) * time.Nanosecond)) {
+			t.Errorf("record %d has the wrong timestamp: %s", i, records[i].Timestamp)
+		}
+
+		node := &types.Node{}
+		err := json.Unmarshal(records[i].After, node)
+		if err != nil {
+			t.Errorf("unable to unmarshal record %d: %v", i, err)
+		}
+
+		if node.Role != role || records[i].Actor != "tester" || records[i].Action != types.HistoryActionUpdate {
+			t.Errorf("record %d is wrong: %v", i, records[i])
+		}
+	}
+}
+
+func TestChangeHistory(t *testing.T) {
+	inv := NewMemoryStore()
+	tx := inv.WithActor("tester")
+
+	version := time.Now()
+	err := tx.Node().Create(&types.Node{InventoryID: "test", Role: "worker", LastUpdated: version})
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	err = tx.Node().UpdateIfVersion(&types.Node{InventoryID: "test", Role: "storage"}, version.Add(-time.Second))
+	if err != inventory.ErrVersionMismatch {
+		t.Fatalf("expected version mismatch, got: %v", err)
+	}
+
+	err = tx.Node().Delete(&types.Node{InventoryID: "test"})
+	if err != nil {
+		t.Fatalf("unable to delete node: %v", err)
+	}
+
+	err = inv.System().Create(&types.System{Name: "untracked"})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	records, _, err := inv.History().GetHistory(types.HistoryObjectNode, "test", inventory.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to get history: %v", err)
+	}
+
+	if len(records) != 2 || records[0].Action != types.HistoryActionCreate || records[1].Action != types.HistoryActionDelete {
+		t.Fatalf("expected a create and a delete to be recorded, got %v", records)
+	}
+
+	before := &types.Node{}
+	err = json.Unmarshal(records[1].Before, before)
+	if err != nil || before.Role != "worker" || records[1].Actor != "tester" || len(records[1].After) != 0 {
+		t.Errorf("expected the deleted node to be recorded, got %v: %v", records[1], err)
+	}
+
+	records, _, err = inv.History().GetHistory(types.HistoryObjectSystem, "untracked", inventory.ListOptions{})
+	if err != nil || len(records) != 0 {
+		t.Errorf("expected no history for changes without an actor, got %v: %v", records, err)
+	}
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	r := types.NewStaticIPReservation()
+	r.IP = &net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: cidr.Mask}
+	r.MAC = mac
+	err = tx.IPReservation().CreateIPReservation(r)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	updated := *r
+	updated.HostInformation = "updated"
+	err = tx.IPReservation().UpdateIPReservation(&updated)
+	if err != nil {
+		t.Fatalf("unable to update reservation: %v", err)
+	}
+
+	err = tx.IPReservation().Delete(r)
+	if err != nil {
+		t.Fatalf("unable to delete reservation: %v", err)
+	}
+
+	records, _, err = inv.History().GetHistory(types.HistoryObjectIPReservation, "10.0.0.5", inventory.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to get history: %v", err)
+	}
+
+	actions := []string{}
+	for _, record := range records {
+		actions = append(actions, record.Action)
+	}
+	if diff := deep.Equal(actions, []string{types.HistoryActionCreate, types.HistoryActionUpdate, types.HistoryActionDelete}); len(diff) > 0 {
+		t.Errorf("reservation history is wrong: %v", diff)
+	}
+}
@@ -0,0 +1,379 @@
+package memorystore
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// reservationKey builds the equivalent of the (net, ip) primary key used by
+// the dynamodb ip reservation table
+func reservationKey(ipNet *net.IPNet) (string, error) {
+	if ipNet == nil {
+		return "", types.ErrKeyNotSet
+	}
+	return fmt.Sprintf("%s %s", ipNet.IP.Mask(ipNet.Mask), ipNet.IP), nil
+}
+
+type IPReservationStore struct {
+	*MemoryStore
+}
+
+func (db *IPReservationStore) GetIPReservation(ipNet *net.IPNet) (*types.IPReservation, error) {
+	key, err := reservationKey(ipNet)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &types.IPReservation{}
+	err = db.get(db.ipReservations, key, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Ended(time.Now()) {
+		return nil, inventory.ErrObjectNotFound
+	}
+	return r, nil
+}
+
+// all returns every stored reservation, including those that have ended
+func (db *IPReservationStore) all() (types.IPReservationList, error) {
+	reservations := make(types.IPReservationList, 0)
+	err := db.getAll(db.ipReservations, &reservations)
+	if err != nil {
+		return nil, fmt.Errorf("error getting all ip reservations: %v", err)
+	}
+	return reservations, nil
+}
+
+func (db *IPReservationStore) GetAllIPReservations() (types.IPReservationList, error) {
+	reservations, err := db.all()
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// filter returns all reservations for which the match function returns true
+func (db *IPReservationStore) filter(match func(*types.IPReservation) bool) (types.IPReservationList, error) {
+	reservations, err := db.GetAllIPReservations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := types.IPReservationList{}
+	for _, r := range reservations {
+		if match(r) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// inSubnet returns true if the reservation is in the subnet, comparing only the
+// network address in the same way the dynamodb partition key does
+func inSubnet(r *types.IPReservation, subnet *net.IPNet) bool {
+	return r.IP != nil && r.IP.IP.Mask(r.IP.Mask).Equal(subnet.IP.Mask(subnet.Mask))
+}
+
+// hasMAC returns true if the reservation would appear in the mac index with
+// the specified mac.  Reservations without a mac aren't indexed.
+func hasMAC(r *types.IPReservation, mac net.HardwareAddr) bool {
+	return len(r.MAC) > 0 && r.MAC.String() == mac.String()
+}
+
+func (db *IPReservationStore) GetIPReservationsByMac(mac net.HardwareAddr) (types.IPReservationList, error) {
+	if len(mac) == 0 {
+		return types.IPReservationList{}, nil
+	}
+
+	return db.filter(func(r *types.IPReservation) bool {
+		return hasMAC(r, mac)
+	})
+}
+
+// GetIPReservationsByMacAt returns the reservations for the mac that were valid
+// at the time specified, including those that have since been deleted or
+// replaced
+func (db *IPReservationStore) GetIPReservationsByMacAt(mac net.HardwareAddr, at time.Time) (types.IPReservationList, error) {
+	result := types.IPReservationList{}
+	if len(mac) == 0 {
+		return result, nil
+	}
+
+	reservations, err := db.all()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reservations {
+		if hasMAC(r, mac) {
+			result = append(result, r)
+		}
+	}
+	return inventory.AddArchivedIPReservations(db.Revision(), result, mac, at)
+}
+
+// GetIPReservations returns all current reservations in the specified subnet
+func (db *IPReservationStore) GetIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
+	reservations, err := db.GetStoredIPReservations(ipNet)
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Active(time.Now()), nil
+}
+
+// GetStoredIPReservations returns every reservation stored in the specified
+// subnet, including those that have ended
+func (db *IPReservationStore) GetStoredIPReservations(ipNet *net.IPNet) (types.IPReservationList, error) {
+	if ipNet == nil {
+		return nil, fmt.Errorf("specified network is nil")
+	}
+
+	reservations, err := db.all()
+	if err != nil {
+		return nil, err
+	}
+
+	result := types.IPReservationList{}
+	for _, r := range reservations {
+		if inSubnet(r, ipNet) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (db *IPReservationStore) GetExistingIPReservationInSubnet(subnetCidr *net.IPNet, mac net.HardwareAddr) (*types.IPReservation, error) {
+	if len(mac) == 0 {
+		return nil, nil
+	}
+
+	results, err := db.filter(func(r *types.IPReservation) bool {
+		return hasMAC(r, mac) && inSubnet(r, subnetCidr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, inventory.ErrObjectNotFound
+	}
+
+	if len(results) > 1 {
+		return nil, fmt.Errorf("unable to lookup exactly one item: found %d matching", len(results))
+	}
+
+	return results[0], nil
+}
+
+func (db *IPReservationStore) CreateRandomIPReservation(r *types.IPReservation, subnet *types.Subnet) (*types.IPReservation, error) {
+	return inventory.CreateRandomIPReservation(db, r, subnet)
+}
+
+func (db *IPReservationStore) CreateIPReservation(r *types.IPReservation) error {
+	key, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	av, err := dynamodbattribute.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		txdb := &IPReservationStore{MemoryStore: tx}
+		existing, err := txdb.storedReservation(key)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			if !existing.Ended(time.Now()) {
+				return inventory.ErrAlreadyExists
+			}
+
+			err = inventory.ArchiveIPReservation(tx.Revision(), existing)
+			if err != nil {
+				return err
+			}
+		}
+		tx.ipReservations[key] = av
+		return tx.recordChange(types.HistoryObjectIPReservation, r.IP.IP.String(), nil, r)
+	})
+}
+
+// storedReservation returns the reservation stored under key, whether or not it
+// has ended, or nil if there isn't one.  The caller must hold the lock, or be
+// working on a copy of the store in a transaction.
+func (db *IPReservationStore) storedReservation(key string) (*types.IPReservation, error) {
+	av, ok := db.ipReservations[key]
+	if !ok {
+		return nil, nil
+	}
+
+	r := &types.IPReservation{}
+	err := dynamodbattribute.Unmarshal(av, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (db *IPReservationStore) UpdateIPReservation(r *types.IPReservation) error {
+	key, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	av, err := dynamodbattribute.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		existing, err := (&IPReservationStore{MemoryStore: tx}).storedReservation(key)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil || existing.Ended(time.Now()) || !hasMAC(existing, r.MAC) {
+			return inventory.ErrUpdateConflict
+		}
+
+		tx.ipReservations[key] = av
+		return tx.recordChange(types.HistoryObjectIPReservation, r.IP.IP.String(), existing, r)
+	})
+}
+
+func (db *IPReservationStore) CreateOrUpdateIPReservation(r *types.IPReservation) error {
+	err := db.UpdateIPReservation(r)
+	if err != inventory.ErrUpdateConflict {
+		return err
+	}
+
+	return db.CreateIPReservation(r)
+}
+
+func (db *IPReservationStore) Exists(r *types.IPReservation) (bool, error) {
+	_, err := db.GetIPReservation(r.IP)
+	switch err {
+	case nil:
+		return true, nil
+	case inventory.ErrObjectNotFound:
+		return false, nil
+	}
+	return false, err
+}
+
+// Delete marks the reservation as deleted, keeping it so that it can still be
+// looked up as it was in the past
+func (db *IPReservationStore) Delete(r *types.IPReservation) error {
+	key, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		existing, err := (&IPReservationStore{MemoryStore: tx}).storedReservation(key)
+		if err != nil || existing == nil {
+			return err
+		}
+
+		now := time.Now()
+		if existing.Ended(now) {
+			return nil
+		}
+
+		deleted := *existing
+		deleted.Deleted = &now
+		av, err := dynamodbattribute.Marshal(&deleted)
+		if err != nil {
+			return err
+		}
+		tx.ipReservations[key] = av
+		return tx.recordChange(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
+	})
+}
+
+// GetExpiredIPReservations returns the reservations that expired before the
+// time specified, including those that were deleted
+func (db *IPReservationStore) GetExpiredIPReservations(at time.Time) (types.IPReservationList, error) {
+	reservations, err := db.all()
+	if err != nil {
+		return nil, err
+	}
+	return reservations.Expired(at), nil
+}
+
+// PurgeIPReservation removes the reservation if it has expired, archiving it so
+// that GetIPReservationsByMacAt can still find it, and records its removal in
+// history unless it was deleted
+func (db *IPReservationStore) PurgeIPReservation(r *types.IPReservation) error {
+	key, err := reservationKey(r.IP)
+	if err != nil {
+		return err
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		txdb := &IPReservationStore{MemoryStore: tx}
+		existing, err := txdb.storedReservation(key)
+		if err != nil || existing == nil {
+			return err
+		}
+
+		if !existing.Expired(time.Now()) {
+			return inventory.ErrUpdateConflict
+		}
+
+		err = inventory.ArchiveIPReservation(tx.Revision(), existing)
+		if err != nil {
+			return err
+		}
+		delete(tx.ipReservations, key)
+
+		// reservations that were deleted already have a delete in their history
+		if existing.Deleted != nil {
+			return nil
+		}
+		return tx.recordChange(types.HistoryObjectIPReservation, existing.IP.IP.String(), existing, nil)
+	})
+}
+
+func (db *IPReservationStore) ObjExists(obj interface{}) (bool, error) {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(r)
+}
+
+func (db *IPReservationStore) ObjCreate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.CreateIPReservation(r)
+}
+
+func (db *IPReservationStore) ObjUpdate(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIPReservation(r)
+}
+
+func (db *IPReservationStore) ObjDelete(obj interface{}) error {
+	r, ok := obj.(*types.IPReservation)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(r)
+}
@@ -0,0 +1,147 @@
+package memorystore
+
+import (
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+type NetworkStore struct {
+	*MemoryStore
+}
+
+func (db *NetworkStore) GetNetworks() (map[string]*types.Network, error) {
+	networkList := make([]*types.Network, 0, 0)
+	err := db.getAll(db.networks, &networkList)
+	if err != nil {
+		return nil, err
+	}
+	networks := make(map[string]*types.Network)
+	for _, n := range networkList {
+		networks[n.ID()] = n
+	}
+	return networks, nil
+}
+
+// ListNetworks returns a page of networks
+func (db *NetworkStore) ListNetworks(opts inventory.ListOptions) ([]*types.Network, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	networkList := make([]*types.Network, 0, 0)
+	err = db.getAll(db.networks, &networkList)
+	if err != nil {
+		return nil, "", err
+	}
+
+	networks := make([]*types.Network, 0)
+	for _, obj := range networkList {
+		if pager.Add(obj.ID()) {
+			networks = append(networks, obj)
+		}
+	}
+	return networks, pager.Next(), nil
+}
+
+func (db *NetworkStore) GetNetworkByID(id string) (*types.Network, error) {
+	if id == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	network := &types.Network{}
+	err := db.get(db.networks, id, network)
+	if err != nil {
+		return nil, err
+	}
+
+	if network.Subnets == nil {
+		network.Subnets = make([]*types.Subnet, 0)
+	}
+	return network, nil
+}
+
+func (db *NetworkStore) Exists(network *types.Network) (bool, error) {
+	if network.ID() == "" {
+		return false, types.ErrKeyNotSet
+	}
+	return db.exists(db.networks, network.ID()), nil
+}
+
+func (db *NetworkStore) Create(network *types.Network) error {
+	return db.Update(network)
+}
+
+func (db *NetworkStore) Update(network *types.Network) error {
+	return db.write(network, false, nil)
+}
+
+func (db *NetworkStore) Delete(network *types.Network) error {
+	return db.write(network, true, nil)
+}
+
+// UpdateIfVersion updates the network if the stored copy is at version
+func (db *NetworkStore) UpdateIfVersion(network *types.Network, version time.Time) error {
+	return db.write(network, false, &version)
+}
+
+// DeleteIfVersion deletes the network if the stored copy is at version
+func (db *NetworkStore) DeleteIfVersion(network *types.Network, version time.Time) error {
+	return db.write(network, true, &version)
+}
+
+// write stores the network, or deletes it, along with its revision
+func (db *NetworkStore) write(network *types.Network, deleteNetwork bool, version *time.Time) error {
+	networks := func(tx *MemoryStore) table { return tx.networks }
+	return db.MemoryStore.write(networks, types.HistoryObjectNetwork, network, deleteNetwork, &types.Network{}, version)
+}
+
+func (db *NetworkStore) ObjDelete(obj interface{}) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(network)
+}
+
+func (db *NetworkStore) ObjCreate(obj interface{}) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Create(network)
+}
+
+func (db *NetworkStore) ObjUpdate(obj interface{}) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Update(network)
+}
+
+func (db *NetworkStore) ObjExists(obj interface{}) (bool, error) {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(network)
+}
+
+func (db *NetworkStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(network, version)
+}
+
+func (db *NetworkStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	network, ok := obj.(*types.Network)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(network, version)
+}
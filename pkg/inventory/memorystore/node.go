@@ -0,0 +1,218 @@
+package memorystore
+
+import (
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+type NodeStore struct {
+	*MemoryStore
+}
+
+func (db *NodeStore) GetNodes() (map[string]*types.Node, error) {
+	nodeList := make([]*types.Node, 0, 0)
+	err := db.getAll(db.nodes, &nodeList)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*types.Node)
+	for _, n := range nodeList {
+		nodes[n.ID()] = n
+	}
+	return nodes, nil
+}
+
+// ListNodes returns a page of the nodes matching the filter in opts
+func (db *NodeStore) ListNodes(opts inventory.ListOptions) ([]*types.Node, string, error) {
+	pager, err := inventory.NewPager(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodeList := make([]*types.Node, 0, 0)
+	err = db.getAll(db.nodes, &nodeList)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodes := make([]*types.Node, 0)
+	for _, n := range nodeList {
+		if opts.Filter.Match(n) && pager.Add(n.ID()) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, pager.Next(), nil
+}
+
+func (db *NodeStore) GetNodeByID(id string) (*types.Node, error) {
+	if id == "" {
+		return nil, types.ErrKeyNotSet
+	}
+
+	node := &types.Node{}
+	err := db.get(db.nodes, id, node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (db *NodeStore) GetNodeByMAC(mac net.HardwareAddr) (*types.Node, error) {
+	db.mu.Lock()
+	nodeID, ok := db.nodeMacIndex[mac.String()]
+	db.mu.Unlock()
+	if !ok {
+		return nil, inventory.ErrObjectNotFound
+	}
+
+	return db.GetNodeByID(nodeID)
+}
+
+// reconcileMacIndex points every mac attached to the node at the node, and
+// removes entries for macs that are no longer attached
+func (db *NodeStore) reconcileMacIndex(node *types.Node) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for mac, nodeID := range db.nodeMacIndex {
+		if nodeID == node.ID() {
+			delete(db.nodeMacIndex, mac)
+		}
+	}
+
+	for _, iface := range node.Networks {
+		for _, mac := range iface.NICs {
+			db.nodeMacIndex[mac.String()] = node.ID()
+		}
+	}
+}
+
+func (db *NodeStore) Create(newNode *types.Node) error {
+	return db.Update(newNode)
+}
+
+func (db *NodeStore) Update(updatedNode *types.Node) error {
+	return db.write(updatedNode, false, nil)
+}
+
+// UpdateIfVersion updates the node if the stored copy is at version
+func (db *NodeStore) UpdateIfVersion(node *types.Node, version time.Time) error {
+	return db.write(node, false, &version)
+}
+
+func (db *NodeStore) Exists(node *types.Node) (bool, error) {
+	if node.ID() == "" {
+		return false, types.ErrKeyNotSet
+	}
+	return db.exists(db.nodes, node.ID()), nil
+}
+
+func (db *NodeStore) Delete(node *types.Node) error {
+	return db.write(node, true, nil)
+}
+
+// DeleteIfVersion deletes the node if the stored copy is at version
+func (db *NodeStore) DeleteIfVersion(node *types.Node, version time.Time) error {
+	return db.write(node, true, &version)
+}
+
+// write reconciles the node's ip reservations, updates the mac index, writes
+// or deletes the node and records a revision of it, and the change in history
+// if the store has an actor, in a single transaction, so none of the changes
+// are kept if any of them fail.  If version isn't nil,
+// the stored copy of the node must be at version.
+func (db *NodeStore) write(node *types.Node, deleteNode bool, version *time.Time) error {
+	if node.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		before, err := tx.previous(tx.nodes, node.ID(), &types.Node{}, version)
+		if err != nil {
+			return err
+		}
+
+		if deleteNode {
+			node.Networks = types.NICInfoMap{}
+		}
+
+		// reservation changes are part of the node's history record
+		err = inventory.ReconcileNodeIPs(tx.WithActor(""), node)
+		if err != nil {
+			return err
+		}
+
+		txNodes := &NodeStore{MemoryStore: tx}
+		txNodes.reconcileMacIndex(node)
+		if deleteNode {
+			tx.delete(tx.nodes, node.ID())
+			err = inventory.PutDeletedRevision(tx.Revision(), types.HistoryObjectNode, node.ID())
+			if err != nil {
+				return err
+			}
+			return tx.recordChange(types.HistoryObjectNode, node.ID(), before, nil)
+		}
+
+		err = tx.put(tx.nodes, node.ID(), node)
+		if err != nil {
+			return err
+		}
+
+		err = inventory.PutRevision(tx.Revision(), types.HistoryObjectNode, node)
+		if err != nil {
+			return err
+		}
+		return tx.recordChange(types.HistoryObjectNode, node.ID(), before, node)
+	})
+}
+
+func (db *NodeStore) ObjDelete(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Delete(node)
+}
+
+func (db *NodeStore) ObjCreate(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Create(node)
+}
+
+func (db *NodeStore) ObjUpdate(obj interface{}) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.Update(node)
+}
+
+func (db *NodeStore) ObjExists(obj interface{}) (bool, error) {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return false, inventory.ErrInvalidObjectType
+	}
+	return db.Exists(node)
+}
+
+func (db *NodeStore) ObjUpdateIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.UpdateIfVersion(node, version)
+}
+
+func (db *NodeStore) ObjDeleteIfVersion(obj interface{}, version time.Time) error {
+	node, ok := obj.(*types.Node)
+	if !ok {
+		return inventory.ErrInvalidObjectType
+	}
+	return db.DeleteIfVersion(node, version)
+}
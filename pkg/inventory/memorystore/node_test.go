@@ -0,0 +1,297 @@
+package memorystore
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/go-test/deep"
+)
+
+func TestNodeCreate(t *testing.T) {
+	inv := NewMemoryStore()
+
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00-01-02-03-04-05")
+	err = inv.Node().Create(
+		&types.Node{
+			InventoryID: "test",
+			Networks: types.NICInfoMap{
+				"testnet": &types.NetworkInterface{
+					NICs: []net.HardwareAddr{mac},
+				},
+			},
+		})
+
+	if err != nil {
+		t.Errorf("Unable to create very simple node: %v", err)
+	}
+
+	n, err := inv.Node().GetNodeByID("test")
+	if err != nil {
+		t.Fatalf("unable to get newly created node: %v", err)
+	}
+
+	if iface, ok := n.Networks["testnet"]; !ok || len(iface.NICs) != 1 || iface.NICs[0].String() != "00:01:02:03:04:05" {
+		t.Log(iface)
+		t.Errorf("network not stored properly with node")
+	}
+
+	n, err = inv.Node().GetNodeByMAC(mac)
+	if err != nil {
+		t.Fatalf("unable to lookup node by mac: %v", err)
+	}
+
+	if n.ID() != "test" {
+		t.Errorf("wrong node returned by mac lookup: %s", n.ID())
+	}
+}
+
+func TestNodeMacIndex(t *testing.T) {
+	inv := NewMemoryStore()
+
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	oldMac, _ := net.ParseMAC("00:01:02:03:04:05")
+	newMac, _ := net.ParseMAC("00:01:02:03:04:06")
+	node := &types.Node{
+		InventoryID: "test",
+		Networks: types.NICInfoMap{
+			"testnet": &types.NetworkInterface{NICs: []net.HardwareAddr{oldMac}},
+		},
+	}
+
+	err = inv.Node().Create(node)
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	node.Networks["testnet"].NICs = []net.HardwareAddr{newMac}
+	err = inv.Node().Update(node)
+	if err != nil {
+		t.Fatalf("unable to update node: %v", err)
+	}
+
+	_, err = inv.Node().GetNodeByMAC(oldMac)
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected removed mac to be removed from index, got: %v", err)
+	}
+
+	_, err = inv.Node().GetNodeByMAC(newMac)
+	if err != nil {
+		t.Errorf("unable to lookup node by new mac: %v", err)
+	}
+
+	err = inv.Node().Delete(node)
+	if err != nil {
+		t.Fatalf("unable to delete node: %v", err)
+	}
+
+	_, err = inv.Node().GetNodeByMAC(newMac)
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected mac of deleted node to be removed from index, got: %v", err)
+	}
+}
+
+func TestNodeStaticAllocation(t *testing.T) {
+	inv := NewMemoryStore()
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	err := inv.Network().Create(&types.Network{Name: "testnet", Subnets: types.SubnetList{
+		&types.Subnet{Cidr: cidr, StaticAllocationMethod: "random"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := &types.Node{
+		InventoryID: "test",
+		Networks: types.NICInfoMap{
+			"testnet": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}},
+		},
+	}
+
+	err = inv.Node().Create(node)
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	reservations, err := inv.IPReservation().GetIPReservationsByMac(mac)
+	if err != nil {
+		t.Fatalf("unable to lookup reservations: %v", err)
+	}
+
+	if len(reservations.Static()) != 1 || !cidr.Contains(reservations[0].IP.IP) {
+		t.Errorf("expected exactly one static reservation in %s, got: %v", cidr, reservations)
+	}
+
+	err = inv.Node().Delete(node)
+	if err != nil {
+		t.Fatalf("unable to delete node: %v", err)
+	}
+
+	reservations, err = inv.IPReservation().GetIPReservationsByMac(mac)
+	if err != nil {
+		t.Fatalf("unable to lookup reservations: %v", err)
+	}
+
+	if len(reservations) != 0 {
+		t.Errorf("static reservations not removed with node: %v", reservations)
+	}
+}
+
+func TestListNodes(t *testing.T) {
+	inv := NewMemoryStore()
+
+	for i, role := range []string{"worker", "master", "worker", "worker", "worker"} {
+		err := inv.Node().Create(&types.Node{InventoryID: fmt.Sprintf("node%04d", i), Role: role})
+		if err != nil {
+			t.Fatalf("unable to create node: %v", err)
+		}
+	}
+
+	opts := inventory.ListOptions{Limit: 2, Filter: inventory.NodeFilter{Role: "worker"}}
+	ids := []string{}
+	for pages := 1; ; pages++ {
+		nodes, next, err := inv.Node().ListNodes(opts)
+		if err != nil {
+			t.Fatalf("unable to list nodes: %v", err)
+		}
+		for _, n := range nodes {
+			ids = append(ids, n.ID())
+		}
+		if next == "" {
+			break
+		}
+		if pages > 2 {
+			t.Fatalf("too many pages returned")
+		}
+		opts.Next = next
+	}
+
+	if diff := deep.Equal(ids, []string{"node0000", "node0002", "node0003", "node0004"}); diff != nil {
+		t.Errorf("unexpected nodes listed: %v", diff)
+	}
+
+	_, _, err := inv.Node().ListNodes(inventory.ListOptions{Next: "%"})
+	if err != inventory.ErrInvalidCursor {
+		t.Errorf("expected invalid cursor error, got: %v", err)
+	}
+}
+
+func TestNodeVersionedWrites(t *testing.T) {
+	inv := NewMemoryStore()
+
+	err := inv.Network().Create(&types.Network{Name: "testnet"})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	version := time.Date(2019, 6, 1, 10, 0, 0, 0, time.FixedZone("CDT", -5*3600))
+	err = inv.Node().Create(&types.Node{InventoryID: "test", Role: "worker", LastUpdated: version})
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	err = inv.Node().UpdateIfVersion(&types.Node{InventoryID: "test", Role: "master"}, version.Add(-time.Second))
+	if err != inventory.ErrVersionMismatch {
+		t.Errorf("expected version mismatch updating stale node, got: %v", err)
+	}
+
+	// versions match regardless of the time zone they're expressed in
+	err = inv.Node().UpdateIfVersion(&types.Node{InventoryID: "test", Role: "master", LastUpdated: version.Add(time.Second)}, version.UTC())
+	if err != nil {
+		t.Fatalf("unable to update node at current version: %v", err)
+	}
+
+	err = inv.Node().DeleteIfVersion(&types.Node{InventoryID: "test"}, version)
+	if err != inventory.ErrVersionMismatch {
+		t.Errorf("expected version mismatch deleting stale node, got: %v", err)
+	}
+
+	n, err := inv.Node().GetNodeByID("test")
+	if err != nil || n.Role != "master" {
+		t.Fatalf("node not updated: %v %v", n, err)
+	}
+
+	err = inv.Node().DeleteIfVersion(&types.Node{InventoryID: "test"}, version.Add(time.Second))
+	if err != nil {
+		t.Errorf("unable to delete node at current version: %v", err)
+	}
+
+	_, err = inv.Node().GetNodeByID("test")
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("expected node to be deleted, got: %v", err)
+	}
+}
+
+func TestNodeWriteRollback(t *testing.T) {
+	inv := NewMemoryStore()
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	_, otherCidr, _ := net.ParseCIDR("10.0.1.0/24")
+	err := inv.Network().Create(&types.Network{Name: "testnet", Subnets: types.SubnetList{
+		&types.Subnet{Cidr: cidr, StaticAllocationMethod: "random"},
+		&types.Subnet{Cidr: otherCidr, StaticAllocationMethod: "unknown"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := &types.Node{
+		InventoryID: "test",
+		Networks: types.NICInfoMap{
+			"testnet": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}},
+		},
+	}
+
+	// the first subnet is allocated before allocation fails in the second
+	err = inv.Node().Create(node)
+	if err == nil {
+		t.Fatalf("expected node write to fail")
+	}
+
+	reservations, err := inv.IPReservation().GetIPReservations(cidr)
+	if err != nil || len(reservations) != 0 {
+		t.Errorf("expected reservations made by the failed write to be rolled back, got %v: %v", reservations, err)
+	}
+
+	if _, err := inv.Node().GetNodeByMAC(mac); err != inventory.ErrObjectNotFound {
+		t.Errorf("expected mac index to be unchanged, got: %v", err)
+	}
+
+	version := time.Now()
+	err = inv.Node().Create(&types.Node{InventoryID: "test", LastUpdated: version})
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	err = inv.Network().Update(&types.Network{Name: "testnet", Subnets: types.SubnetList{
+		&types.Subnet{Cidr: cidr, StaticAllocationMethod: "random"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to update network: %v", err)
+	}
+
+	err = inv.Node().UpdateIfVersion(node, version.Add(-time.Second))
+	if err != inventory.ErrVersionMismatch {
+		t.Fatalf("expected version mismatch, got: %v", err)
+	}
+
+	reservations, err = inv.IPReservation().GetIPReservations(cidr)
+	if err != nil || len(reservations) != 0 {
+		t.Errorf("expected no reservations after a version mismatch, got %v: %v", reservations, err)
+	}
+}
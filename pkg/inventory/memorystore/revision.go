@@ -0,0 +1,108 @@
+package memorystore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type RevisionStore struct {
+	*MemoryStore
+}
+
+func revisionSequence(sequence int64) string {
+	return fmt.Sprintf("%020d", sequence)
+}
+
+// PutRevision stores the revision, replacing any revision of the object with
+// the same timestamp
+func (db *RevisionStore) PutRevision(r *types.Revision) error {
+	return db.put(db.revisions, r.Key()+"/"+revisionSequence(r.Sequence()), r)
+}
+
+// AppendRevision stores the revision, moving its timestamp forward until it
+// doesn't collide with an existing revision of the object
+func (db *RevisionStore) AppendRevision(r *types.Revision) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for {
+		if _, ok := db.revisions[r.Key()+"/"+revisionSequence(r.Sequence())]; !ok {
+			break
+		}
+		r.Timestamp = r.Timestamp.Add(time.Nanosecond)
+	}
+
+	av, err := dynamodbattribute.Marshal(r)
+	if err != nil {
+		return err
+	}
+	db.revisions[r.Key()+"/"+revisionSequence(r.Sequence())] = av
+	return nil
+}
+
+// GetRevisionAt returns the latest revision of the object at or before at
+func (db *RevisionStore) GetRevisionAt(objectType string, objectID string, at time.Time) (*types.Revision, error) {
+	prefix := types.HistoryKey(objectType, objectID) + "/"
+	last := revisionSequence(types.RevisionSequence(at))
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	found := ""
+	for k := range db.revisions {
+		sequence := strings.TrimPrefix(k, prefix)
+		if !strings.HasPrefix(k, prefix) || strings.Contains(sequence, "/") {
+			continue
+		}
+		if sequence <= last && sequence > found {
+			found = sequence
+		}
+	}
+
+	if found == "" {
+		return nil, inventory.ErrObjectNotFound
+	}
+
+	r := &types.Revision{}
+	err := dynamodbattribute.Unmarshal(db.revisions[prefix+found], r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRevisionsSince returns the revisions of the object at or after since,
+// oldest first
+func (db *RevisionStore) GetRevisionsSince(objectType string, objectID string, since time.Time) ([]*types.Revision, error) {
+	prefix := types.HistoryKey(objectType, objectID) + "/"
+	first := revisionSequence(types.RevisionSequence(since))
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	keys := []string{}
+	for k := range db.revisions {
+		sequence := strings.TrimPrefix(k, prefix)
+		if !strings.HasPrefix(k, prefix) || strings.Contains(sequence, "/") {
+			continue
+		}
+		if sequence >= first {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	revisions := make([]*types.Revision, 0, len(keys))
+	for _, k := range keys {
+		r := &types.Revision{}
+		err := dynamodbattribute.Unmarshal(db.revisions[k], r)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
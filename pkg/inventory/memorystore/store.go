@@ -0,0 +1,323 @@
+// Package memorystore provides an inventory backend that keeps all records in
+// memory.  Objects are stored using their dynamodb attribute value encoding so
+// that the results returned match those returned by the dynamodb backend.
+package memorystore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+var _ inventory.Store = &MemoryStore{}
+
+type table map[string]*dynamodb.AttributeValue
+
+// MemoryStore is an inventory store that doesn't persist any data.  It's
+// intended for use in tests and local development.  Changes are recorded in
+// history if the store has an actor.
+type MemoryStore struct {
+	*records
+	actor string
+}
+
+// records holds the tables of a MemoryStore, which are shared with the stores
+// returned by WithActor
+type records struct {
+	mu             sync.Mutex
+	nodes          table
+	networks       table
+	systems        table
+	ipReservations table
+	history        table
+	revisions      table
+	webhooks       table
+	nodeMacIndex   map[string]string
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: &records{
+		nodes:          make(table),
+		networks:       make(table),
+		systems:        make(table),
+		ipReservations: make(table),
+		history:        make(table),
+		revisions:      make(table),
+		webhooks:       make(table),
+		nodeMacIndex:   make(map[string]string),
+	}}
+}
+
+func copyTable(t table) table {
+	c := make(table, len(t))
+	for k, v := range t {
+		c[k] = v
+	}
+	return c
+}
+
+// clone returns a store holding a copy of the store's records.  db.mu must be
+// held.
+func (db *MemoryStore) clone() *MemoryStore {
+	c := &MemoryStore{records: &records{
+		nodes:          copyTable(db.nodes),
+		networks:       copyTable(db.networks),
+		systems:        copyTable(db.systems),
+		ipReservations: copyTable(db.ipReservations),
+		history:        copyTable(db.history),
+		revisions:      copyTable(db.revisions),
+		webhooks:       copyTable(db.webhooks),
+		nodeMacIndex:   make(map[string]string, len(db.nodeMacIndex)),
+	}, actor: db.actor}
+	for k, v := range db.nodeMacIndex {
+		c.nodeMacIndex[k] = v
+	}
+	return c
+}
+
+// inTransaction calls fn with a copy of the store, and replaces the store's
+// records with those of the copy if fn succeeds.  The store is locked until
+// fn returns, so the changes made by fn are applied atomically, or not at
+// all.
+func (db *MemoryStore) inTransaction(fn func(*MemoryStore) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := db.clone()
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	db.nodes, db.networks, db.systems = tx.nodes, tx.networks, tx.systems
+	db.ipReservations, db.history, db.revisions = tx.ipReservations, tx.history, tx.revisions
+	db.webhooks, db.nodeMacIndex = tx.webhooks, tx.nodeMacIndex
+	return nil
+}
+
+func (db *MemoryStore) put(t table, key string, obj interface{}) error {
+	av, err := dynamodbattribute.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	t[key] = av
+	return nil
+}
+
+// versioned is implemented by objects whose LastUpdated timestamp identifies
+// their revision
+type versioned interface {
+	Version() time.Time
+}
+
+// checkVersion returns ErrVersionMismatch unless the object stored at key is at
+// version.  The stored object is unmarshaled into current.  db.mu must be held.
+func checkVersion(t table, key string, current versioned, version time.Time) error {
+	av, ok := t[key]
+	if !ok {
+		return inventory.ErrObjectNotFound
+	}
+
+	err := dynamodbattribute.Unmarshal(av, current)
+	if err != nil {
+		return err
+	}
+
+	if !current.Version().Equal(version) {
+		return inventory.ErrVersionMismatch
+	}
+	return nil
+}
+
+// putIfVersion stores obj if the object stored at key is at version
+func (db *MemoryStore) putIfVersion(t table, key string, obj interface{}, current versioned, version time.Time) error {
+	av, err := dynamodbattribute.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err = checkVersion(t, key, current, version)
+	if err != nil {
+		return err
+	}
+	t[key] = av
+	return nil
+}
+
+// deleteIfVersion deletes the object stored at key if it's at version
+func (db *MemoryStore) deleteIfVersion(t table, key string, current versioned, version time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err := checkVersion(t, key, current, version)
+	if err != nil {
+		return err
+	}
+	delete(t, key)
+	return nil
+}
+
+// revisioned is implemented by objects that have revisions
+type revisioned interface {
+	versioned
+	ID() string
+}
+
+// write stores obj, or deletes it if deleteObj is set, along with a revision of
+// it and, if the store has an actor, a record of the change in history, all in
+// a single transaction.  t selects the object's table from the transaction's
+// copy of the store.  The stored copy is read into current, and if version
+// isn't nil it must be at version.
+func (db *MemoryStore) write(t func(*MemoryStore) table, objectType string, obj revisioned, deleteObj bool, current versioned, version *time.Time) error {
+	if obj.ID() == "" {
+		return types.ErrKeyNotSet
+	}
+
+	return db.inTransaction(func(tx *MemoryStore) error {
+		before, err := tx.previous(t(tx), obj.ID(), current, version)
+		if err != nil {
+			return err
+		}
+
+		if deleteObj {
+			tx.delete(t(tx), obj.ID())
+			err = inventory.PutDeletedRevision(tx.Revision(), objectType, obj.ID())
+			if err != nil {
+				return err
+			}
+			return tx.recordChange(objectType, obj.ID(), before, nil)
+		}
+
+		err = tx.put(t(tx), obj.ID(), obj)
+		if err != nil {
+			return err
+		}
+
+		err = inventory.PutRevision(tx.Revision(), objectType, obj)
+		if err != nil {
+			return err
+		}
+		return tx.recordChange(objectType, obj.ID(), before, obj)
+	})
+}
+
+// previous reads the object stored at key into current, returning nil if
+// there isn't one.  If version isn't nil the object must be stored, and be at
+// version.
+func (db *MemoryStore) previous(t table, key string, current versioned, version *time.Time) (interface{}, error) {
+	if version != nil {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		err := checkVersion(t, key, current, *version)
+		if err != nil {
+			return nil, err
+		}
+		return current, nil
+	}
+
+	err := db.get(t, key, current)
+	if err == inventory.ErrObjectNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// recordChange appends a record of a change made by the store's actor to
+// history.  It's called on a transaction, so the record is only kept along
+// with the change.
+func (db *MemoryStore) recordChange(objectType string, objectID string, before interface{}, after interface{}) error {
+	record, err := inventory.NewChangeRecord(db.actor, objectType, objectID, before, after)
+	if err != nil || record == nil {
+		return err
+	}
+	return db.History().AppendHistory(record)
+}
+
+func (db *MemoryStore) get(t table, key string, out interface{}) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	av, ok := t[key]
+	if !ok {
+		return inventory.ErrObjectNotFound
+	}
+	return dynamodbattribute.Unmarshal(av, out)
+}
+
+func (db *MemoryStore) getAll(t table, out interface{}) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]*dynamodb.AttributeValue, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, t[k])
+	}
+	return dynamodbattribute.Unmarshal(&dynamodb.AttributeValue{L: items}, out)
+}
+
+func (db *MemoryStore) exists(t table, key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	_, ok := t[key]
+	return ok
+}
+
+func (db *MemoryStore) delete(t table, key string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(t, key)
+}
+
+func (db *MemoryStore) Node() inventory.NodeStore {
+	return &NodeStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) InventoryNode() inventory.InventoryNodeStore {
+	return inventory.NewInventoryNodeStore(db)
+}
+
+func (db *MemoryStore) Network() inventory.NetworkStore {
+	return &NetworkStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) System() inventory.SystemStore {
+	return &SystemStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) IPReservation() inventory.IPReservationStore {
+	return &IPReservationStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) History() inventory.HistoryStore {
+	return &HistoryStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) Revision() inventory.RevisionStore {
+	return &RevisionStore{MemoryStore: db}
+}
+
+func (db *MemoryStore) Webhook() inventory.WebhookStore {
+	return &WebhookStore{MemoryStore: db}
+}
+
+// WithActor returns a store for the same records that records the changes made
+// through it in history as changes made by actor
+func (db *MemoryStore) WithActor(actor string) inventory.Store {
+	return &MemoryStore{records: db.records, actor: actor}
+}
@@ -0,0 +1,33 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ReapExpiredIPReservations removes the reservations that expired before the
+// time specified, recording each removal in history as a change made by actor.
+// Reservations renewed since they were listed are skipped.  Expired
+// reservations are already ignored by the allocators, so reaping only keeps
+// them from accumulating in the subnets.  Reaped reservations are archived, so
+// GetInventoryNodeByIDAt still reports them for the times they were valid.
+func ReapExpiredIPReservations(store Store, at time.Time, actor string) (types.IPReservationList, error) {
+	expired, err := store.IPReservation().GetExpiredIPReservations(at)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := store.WithActor(actor).IPReservation()
+	reaped := make(types.IPReservationList, 0, len(expired))
+	for _, r := range expired {
+		err = reservations.PurgeIPReservation(r)
+		if err == ErrUpdateConflict {
+			continue
+		} else if err != nil {
+			return reaped, err
+		}
+		reaped = append(reaped, r)
+	}
+	return reaped, nil
+}
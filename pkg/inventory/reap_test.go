@@ -0,0 +1,114 @@
+package inventory_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestReapExpiredIPReservations(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	mask := net.IPv4Mask(0xff, 0xff, 0xff, 0)
+	start := time.Now().Add(-2 * time.Hour)
+	ended := time.Now().Add(-time.Hour)
+	later := time.Now().Add(time.Hour)
+
+	expired := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: mask}, Start: &start, End: &ended}
+	current := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.11"), Mask: mask}, Start: &start, End: &later}
+	static := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.12"), Mask: mask}, Start: &start}
+	for _, r := range []*types.IPReservation{expired, current, static} {
+		err := inv.IPReservation().CreateIPReservation(r)
+		if err != nil {
+			t.Fatalf("unable to create reservation: %v", err)
+		}
+	}
+
+	err := inv.IPReservation().PurgeIPReservation(current)
+	if err != inventory.ErrUpdateConflict {
+		t.Errorf("expected purge of a current reservation to conflict, got: %v", err)
+	}
+
+	reaped, err := inventory.ReapExpiredIPReservations(inv, time.Now(), "test")
+	if err != nil {
+		t.Fatalf("unable to reap reservations: %v", err)
+	}
+
+	if len(reaped) != 1 || !reaped[0].IP.IP.Equal(expired.IP.IP) {
+		t.Errorf("expected only the expired reservation to be reaped, got: %v", reaped)
+	}
+
+	remaining, err := inv.IPReservation().GetExpiredIPReservations(time.Now())
+	if err != nil || len(remaining) != 0 {
+		t.Errorf("expected no expired reservations after reaping, got %v %v", remaining, err)
+	}
+
+	all, err := inv.IPReservation().GetAllIPReservations()
+	if err != nil || len(all) != 2 {
+		t.Errorf("expected current reservations to be kept, got %v %v", all, err)
+	}
+
+	history, _, err := inv.History().GetHistory(types.HistoryObjectIPReservation, "10.0.0.10", inventory.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to get history: %v", err)
+	}
+
+	if len(history) != 1 || history[0].Action != types.HistoryActionDelete || history[0].Actor != "test" {
+		t.Errorf("expected the reap to be recorded in history, got: %v", history)
+	}
+}
+
+func TestReapExpiredIPReservationsKeepsNodeHistory(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	created := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	ended := created.Add(time.Hour)
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	network := &types.Network{Name: "provisioning", Subnets: []*types.Subnet{{Cidr: subnet}}, LastUpdated: created}
+	system := &types.System{
+		Name:         "test",
+		ShortName:    "tst",
+		Roles:        []string{"worker"},
+		Environments: map[string]*types.Environment{"prod": {Networks: map[string]string{"provisioning": "provisioning"}}},
+		LastUpdated:  created,
+	}
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := &types.Node{
+		InventoryID: "node0001",
+		System:      "tst",
+		Role:        "worker",
+		Environment: "prod",
+		Networks:    types.NICInfoMap{"provisioning": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}},
+		LastUpdated: created,
+	}
+
+	for _, err := range []error{inv.Network().Create(network), inv.System().Create(system), inv.Node().Create(node)} {
+		if err != nil {
+			t.Fatalf("unable to create test records: %v", err)
+		}
+	}
+
+	reservation := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, MAC: mac, Start: &created, End: &ended}
+	err := inv.IPReservation().CreateIPReservation(reservation)
+	if err != nil {
+		t.Fatalf("unable to create reservation: %v", err)
+	}
+
+	reaped, err := inventory.ReapExpiredIPReservations(inv, time.Now(), "test")
+	if err != nil || len(reaped) != 1 {
+		t.Fatalf("expected the reservation to be reaped, got %v: %v", reaped, err)
+	}
+
+	past, err := inv.InventoryNode().GetInventoryNodeByIDAt("node0001", created.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("unable to compile node as it was: %v", err)
+	}
+
+	// only static reservations are part of the nic config
+	if ips := past.IPs(); len(ips) != 1 || !ips[0].Equal(reservation.IP.IP) {
+		t.Errorf("expected the ip reserved at the time, got %v", ips)
+	}
+}
@@ -0,0 +1,119 @@
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ReconcileNodeIPs makes sure that the list of IPs on each interface of the
+// node is valid and fully populated, and removes static reservations for NICs
+// that are no longer attached to the node.
+func ReconcileNodeIPs(store Store, node *types.Node) error {
+	existingNode, err := store.Node().GetNodeByID(node.ID())
+	if err != nil && err != ErrObjectNotFound {
+		return fmt.Errorf("a node with this id exists already, but we can't get it for comparison: %v", err)
+	}
+
+	macsToRemove := make(map[string]net.HardwareAddr)
+
+	if existingNode != nil {
+		for _, iface := range existingNode.Networks {
+			for _, mac := range iface.NICs {
+				macsToRemove[mac.String()] = mac
+			}
+		}
+	}
+
+	for netname, iface := range node.Networks {
+		// TODO: do we support static IPs without macs?
+		if iface.NICs == nil || len(iface.NICs) == 0 {
+			// if we don't have a mac, we can't reserve any IPs
+			continue
+		}
+
+		// Get network
+		network, err := store.Network().GetNetworkByID(netname)
+		if err != nil {
+			return fmt.Errorf("unable to get network named '%s': %v", netname, err)
+		}
+
+		// For each subnet with an allocation strategy, make sure theres a valid static IP reservation
+		for _, subnet := range network.Subnets {
+			for _, mac := range iface.NICs {
+				// this NIC still exists, we don't need to remove
+				if _, ok := macsToRemove[mac.String()]; ok {
+					delete(macsToRemove, mac.String())
+				}
+			}
+
+			if !subnet.StaticAllocationEnabled() {
+				continue
+			}
+
+			existingReservations := types.IPReservationList{}
+			for _, mac := range iface.NICs {
+				reservation, err := store.IPReservation().GetExistingIPReservationInSubnet(subnet.Cidr, mac)
+				if err != nil && err != ErrObjectNotFound {
+					return fmt.Errorf("unable to get reservation for nic: %v", err)
+				}
+				if reservation != nil {
+					existingReservations = append(existingReservations, reservation)
+				}
+			}
+			// If allocation is enabled for this subnet, then we should have one static reservation.
+			// If allocation is disabled, a static reservation may exist, but we will not create one.
+			// Check for existing reservations
+			// If static reservation exists for any mac on this iface, continue to next interface
+			// If we find a valid dynamic reservation for any mac on the iface make it static
+			// otherwise have the allocator choose an address and create a static reservation
+			existingStatic := existingReservations.Static().ValidAt(time.Now())
+			if len(existingStatic) > 0 {
+				continue
+			}
+
+			// try to upgrade a dynamic reservation
+			existingDynamic := existingReservations.Dynamic().ValidAt(time.Now())
+			if len(existingDynamic) > 0 {
+				reservation := existingDynamic[0]
+				reservation.End = nil
+				reservation.Metadata["hostname"] = node.Hostname()
+				reservation.Metadata["nodeid"] = node.ID()
+				reservation.Metadata["domain"] = network.Domain
+				err = store.IPReservation().UpdateIPReservation(reservation)
+				if err != nil {
+					return fmt.Errorf("unable to upgrade dynamic reservation: %v", err)
+				}
+				continue
+			}
+
+			// we don't have a static or dynamic reservation, and allocation is enabled
+			// allocate an IP and create a reservation
+			newReservation := types.NewStaticIPReservation()
+			newReservation.MAC = iface.NICs[0]
+			newReservation.Metadata["hostname"] = node.Hostname()
+			newReservation.Metadata["nodeid"] = node.ID()
+			newReservation.Metadata["domain"] = network.Domain
+			_, err := AllocateIPReservation(store.IPReservation(), subnet.StaticAllocationMethod, newReservation, subnet, node)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, mac := range macsToRemove {
+		reservations, err := store.IPReservation().GetIPReservationsByMac(mac)
+		if err != nil {
+			return fmt.Errorf("unable to lookup reservations for removed nic: %v", err)
+		}
+		for _, reservation := range reservations.Static() {
+			err = store.IPReservation().Delete(reservation)
+			if err != nil {
+				return fmt.Errorf("unable to delete reservation: %v", err)
+			}
+		}
+	}
+	return nil
+}
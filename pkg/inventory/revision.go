@@ -0,0 +1,96 @@
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+type versioned interface {
+	Version() time.Time
+}
+
+type revisioned interface {
+	versioned
+	ID() string
+}
+
+// NewObjectRevision creates the revision of obj at its LastUpdated timestamp,
+// for stores that write the revision along with the object
+func NewObjectRevision(objectType string, obj revisioned) (*types.Revision, error) {
+	return types.NewRevision(objectType, obj.ID(), obj.Version(), obj)
+}
+
+// PutRevision records a copy of obj, which was just written, as the revision
+// of the object at its LastUpdated timestamp
+func PutRevision(store RevisionStore, objectType string, obj revisioned) error {
+	r, err := NewObjectRevision(objectType, obj)
+	if err != nil {
+		return err
+	}
+	return store.PutRevision(r)
+}
+
+// PutDeletedRevision records that the object was just deleted
+func PutDeletedRevision(store RevisionStore, objectType string, id string) error {
+	return store.PutRevision(types.NewDeletedRevision(objectType, id, time.Now()))
+}
+
+// ArchiveIPReservation keeps a copy of a reservation that has ended and is
+// about to be replaced or purged, so that GetIPReservationsByMacAt can still
+// find it.  Reservations without a mac can't be looked up that way, so they
+// aren't kept.
+func ArchiveIPReservation(store RevisionStore, r *types.IPReservation) error {
+	if len(r.MAC) == 0 {
+		return nil
+	}
+
+	revision, err := types.NewArchivedIPReservationRevision(r, time.Now())
+	if err != nil {
+		return err
+	}
+	return store.AppendRevision(revision)
+}
+
+// AddArchivedIPReservations adds the archived reservations for the mac to
+// reservations, which were read from the store beforehand, and returns those
+// that were valid at the time specified.  Reservations are archived after they
+// end, so only those archived since then are read.  A reservation archived
+// after reservations were read appears in both, and is only returned once.
+func AddArchivedIPReservations(store RevisionStore, reservations types.IPReservationList, mac net.HardwareAddr, at time.Time) (types.IPReservationList, error) {
+	revisions, err := store.GetRevisionsSince(types.ArchivedIPReservationObject, mac.String(), at)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append(types.IPReservationList{}, reservations...)
+	for _, revision := range revisions {
+		r := &types.IPReservation{}
+		err = revision.Unmarshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read archived reservation for %s: %v", mac, err)
+		}
+
+		if !containsReservation(result, r) {
+			result = append(result, r)
+		}
+	}
+	return result.ValidAt(at), nil
+}
+
+// containsReservation returns true if reservations has a reservation for the
+// same address that started at the same time as r
+func containsReservation(reservations types.IPReservationList, r *types.IPReservation) bool {
+	for _, existing := range reservations {
+		if existing.IP.String() != r.IP.String() {
+			continue
+		}
+
+		if (existing.Start == nil && r.Start == nil) || (existing.Start != nil && r.Start != nil && existing.Start.Unix() == r.Start.Unix()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package inventory
+
+import (
+	"net"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ObjectStore defines the type-agnostic operations used by the generic api
+// methods to manipulate inventory objects
+type ObjectStore interface {
+	ObjExists(interface{}) (bool, error)
+	ObjCreate(interface{}) error
+	ObjUpdate(interface{}) error
+	ObjDelete(interface{}) error
+}
+
+// VersionedObjectStore writes objects only if the stored copy is still at the
+// version the caller last read, so that concurrent edits aren't lost.  The
+// version of an object is its LastUpdated timestamp.  The write fails with
+// ErrVersionMismatch if the stored copy has a different version, or with
+// ErrObjectNotFound if there is no stored copy.
+type VersionedObjectStore interface {
+	ObjectStore
+	ObjUpdateIfVersion(obj interface{}, version time.Time) error
+	ObjDeleteIfVersion(obj interface{}, version time.Time) error
+}
+
+// NodeStore manages node records and the reservations and mac index entries
+// that depend on them.  The List methods of each store return a page of
+// objects and the cursor for the next page, which is empty on the last page.
+type NodeStore interface {
+	VersionedObjectStore
+	GetNodes() (map[string]*types.Node, error)
+	ListNodes(ListOptions) ([]*types.Node, string, error)
+	GetNodeByID(string) (*types.Node, error)
+	GetNodeByMAC(net.HardwareAddr) (*types.Node, error)
+	Exists(*types.Node) (bool, error)
+	Create(*types.Node) error
+	Update(*types.Node) error
+	Delete(*types.Node) error
+	UpdateIfVersion(*types.Node, time.Time) error
+	DeleteIfVersion(*types.Node, time.Time) error
+}
+
+// NetworkStore manages network records
+type NetworkStore interface {
+	VersionedObjectStore
+	GetNetworks() (map[string]*types.Network, error)
+	ListNetworks(ListOptions) ([]*types.Network, string, error)
+	GetNetworkByID(string) (*types.Network, error)
+	Exists(*types.Network) (bool, error)
+	Create(*types.Network) error
+	Update(*types.Network) error
+	Delete(*types.Network) error
+	UpdateIfVersion(*types.Network, time.Time) error
+	DeleteIfVersion(*types.Network, time.Time) error
+}
+
+// SystemStore manages system records
+type SystemStore interface {
+	VersionedObjectStore
+	GetSystems() (map[string]*types.System, error)
+	ListSystems(ListOptions) ([]*types.System, string, error)
+	GetSystemByID(string) (*types.System, error)
+	Exists(*types.System) (bool, error)
+	Create(*types.System) error
+	Update(*types.System) error
+	Delete(*types.System) error
+	UpdateIfVersion(*types.System, time.Time) error
+	DeleteIfVersion(*types.System, time.Time) error
+}
+
+// IPReservationStore manages ip reservations.  CreateIPReservation must fail
+// with ErrAlreadyExists if a reservation for the address exists, and
+// UpdateIPReservation must fail with ErrUpdateConflict if the existing
+// reservation doesn't exist or belongs to a different mac.
+//
+// Delete doesn't remove reservations, it sets Deleted to the current time so
+// that GetIPReservationsByMacAt can still find them.  GetStoredIPReservations
+// returns every reservation stored in a subnet, but all other methods ignore
+// reservations that have been deleted or have expired, and such a reservation
+// may be replaced by a new reservation for the same address.  A reservation
+// that is replaced is archived with ArchiveIPReservation in the same write, so
+// GetIPReservationsByMacAt can still find it too.
+//
+// GetExpiredIPReservations returns the reservations that expired before the
+// time specified, whether or not they were deleted.  PurgeIPReservation
+// removes a reservation that has expired, archiving it in the same write, and
+// must fail with ErrUpdateConflict if the stored reservation for the address
+// hasn't expired.
+type IPReservationStore interface {
+	ObjectStore
+	GetIPReservation(*net.IPNet) (*types.IPReservation, error)
+	GetAllIPReservations() (types.IPReservationList, error)
+	GetIPReservationsByMac(net.HardwareAddr) (types.IPReservationList, error)
+	GetIPReservationsByMacAt(net.HardwareAddr, time.Time) (types.IPReservationList, error)
+	GetIPReservations(*net.IPNet) (types.IPReservationList, error)
+	GetStoredIPReservations(*net.IPNet) (types.IPReservationList, error)
+	GetExistingIPReservationInSubnet(*net.IPNet, net.HardwareAddr) (*types.IPReservation, error)
+	CreateRandomIPReservation(*types.IPReservation, *types.Subnet) (*types.IPReservation, error)
+	CreateIPReservation(*types.IPReservation) error
+	UpdateIPReservation(*types.IPReservation) error
+	CreateOrUpdateIPReservation(*types.IPReservation) error
+	Exists(*types.IPReservation) (bool, error)
+	Delete(*types.IPReservation) error
+	GetExpiredIPReservations(time.Time) (types.IPReservationList, error)
+	PurgeIPReservation(*types.IPReservation) error
+}
+
+// WebhookStore manages webhook subscriptions to inventory change events
+type WebhookStore interface {
+	VersionedObjectStore
+	GetWebhooks() (map[string]*types.Webhook, error)
+	ListWebhooks(ListOptions) ([]*types.Webhook, string, error)
+	GetWebhookByID(string) (*types.Webhook, error)
+	Exists(*types.Webhook) (bool, error)
+	Create(*types.Webhook) error
+	Update(*types.Webhook) error
+	Delete(*types.Webhook) error
+	UpdateIfVersion(*types.Webhook, time.Time) error
+	DeleteIfVersion(*types.Webhook, time.Time) error
+}
+
+// InventoryNodeStore compiles InventoryNodes from the underlying node, network,
+// system and ip reservation records
+type InventoryNodeStore interface {
+	GetInventoryNodes() (map[string]*types.InventoryNode, error)
+	ListInventoryNodes(ListOptions) ([]*types.InventoryNode, string, error)
+	GetInventoryNodeByID(string) (*types.InventoryNode, error)
+	GetInventoryNodeByMAC(net.HardwareAddr) (*types.InventoryNode, error)
+	GetInventoryNodeByIDAt(string, time.Time) (*types.InventoryNode, error)
+}
+
+// HistoryStore keeps an append-only log of changes to inventory objects.
+// GetHistory returns a page of the changes to an object, oldest first.
+// AppendHistory moves the record's timestamp forward if the object already
+// has a record at the same instant, so that no record is overwritten.
+type HistoryStore interface {
+	AppendHistory(*types.HistoryRecord) error
+	GetHistory(objectType string, objectID string, opts ListOptions) ([]*types.HistoryRecord, string, error)
+}
+
+// RevisionStore keeps a copy of every version of nodes, networks and systems,
+// keyed by their LastUpdated timestamp, so that they can be looked up as they
+// were at a past time.  The node, network and system stores add a revision
+// for each write, and a deleted revision when an object is deleted.
+// GetRevisionAt returns the latest revision at or before the time specified,
+// or ErrObjectNotFound if there is none.  AppendRevision stores a revision
+// without replacing any other, moving its timestamp forward on a collision,
+// and GetRevisionsSince returns the revisions at or after the time specified,
+// oldest first.
+type RevisionStore interface {
+	PutRevision(*types.Revision) error
+	AppendRevision(*types.Revision) error
+	GetRevisionAt(objectType string, objectID string, at time.Time) (*types.Revision, error)
+	GetRevisionsSince(objectType string, objectID string, since time.Time) ([]*types.Revision, error)
+}
+
+// Store is implemented by every inventory backend.  WithActor returns a store
+// for the same records that records every create, update and delete of a
+// node, network, system or ip reservation made through it in history, as a
+// change made by actor.  The record is written along with the change, so a
+// change is never kept without its record.  Reservations changed by a node
+// write are part of the node's record.
+type Store interface {
+	Node() NodeStore
+	Network() NetworkStore
+	System() SystemStore
+	IPReservation() IPReservationStore
+	InventoryNode() InventoryNodeStore
+	History() HistoryStore
+	Revision() RevisionStore
+	Webhook() WebhookStore
+	WithActor(actor string) Store
+}
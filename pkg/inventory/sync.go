@@ -0,0 +1,208 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// Source provides a read-only view of inventory objects, for example a git
+// repository of yaml files or the contents of a Store.
+type Source interface {
+	GetNodes() (map[string]*types.Node, error)
+	GetNetworks() (map[string]*types.Network, error)
+	GetSystems() (map[string]*types.System, error)
+}
+
+// Action is the operation required to bring an object in line with a Source
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change describes a single create, update or delete of an inventory object.
+// Existing holds the current version of the object for updates and deletes.
+type Change struct {
+	Action   Action
+	Kind     string
+	ID       string
+	Object   interface{}
+	Existing interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.ID)
+}
+
+// ChangeList is an ordered list of changes
+type ChangeList []Change
+
+// storeSource reads the current state of a Store
+type storeSource struct {
+	Store
+}
+
+// NewStoreSource returns a Source for the contents of a Store
+func NewStoreSource(store Store) Source {
+	return &storeSource{store}
+}
+
+func (s *storeSource) GetNodes() (map[string]*types.Node, error) {
+	return s.Node().GetNodes()
+}
+
+func (s *storeSource) GetNetworks() (map[string]*types.Network, error) {
+	return s.Network().GetNetworks()
+}
+
+func (s *storeSource) GetSystems() (map[string]*types.System, error) {
+	return s.System().GetSystems()
+}
+
+// Diff compares the current state of the inventory with the desired state and
+// returns the changes needed to make them match.  Objects missing from desired
+// are only deleted if prune is true.  Changes are ordered so that systems and
+// networks exist before the nodes that reference them are written, and are
+// deleted only after those nodes are gone.
+func Diff(current Source, desired Source, prune bool) (ChangeList, error) {
+	srcSystems, err := desired.GetSystems()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get desired systems: %v", err)
+	}
+	dstSystems, err := current.GetSystems()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get systems: %v", err)
+	}
+
+	srcNetworks, err := desired.GetNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get desired networks: %v", err)
+	}
+	dstNetworks, err := current.GetNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get networks: %v", err)
+	}
+
+	srcNodes, err := desired.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get desired nodes: %v", err)
+	}
+	dstNodes, err := current.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get nodes: %v", err)
+	}
+
+	systemWrites, systemDeletes := ChangeList{}, ChangeList{}
+	for _, id := range sortedKeys(srcSystems) {
+		existing, ok := dstSystems[id]
+		if !ok {
+			systemWrites = append(systemWrites, Change{Action: ActionCreate, Kind: "system", ID: id, Object: srcSystems[id]})
+		} else if !existing.Equal(srcSystems[id]) {
+			systemWrites = append(systemWrites, Change{Action: ActionUpdate, Kind: "system", ID: id, Object: srcSystems[id], Existing: existing})
+		}
+	}
+	for _, id := range sortedKeys(dstSystems) {
+		if _, ok := srcSystems[id]; !ok && prune {
+			systemDeletes = append(systemDeletes, Change{Action: ActionDelete, Kind: "system", ID: id, Object: dstSystems[id], Existing: dstSystems[id]})
+		}
+	}
+
+	networkWrites, networkDeletes := ChangeList{}, ChangeList{}
+	for _, id := range sortedKeys(srcNetworks) {
+		existing, ok := dstNetworks[id]
+		if !ok {
+			networkWrites = append(networkWrites, Change{Action: ActionCreate, Kind: "network", ID: id, Object: srcNetworks[id]})
+		} else if !existing.Equal(srcNetworks[id]) {
+			networkWrites = append(networkWrites, Change{Action: ActionUpdate, Kind: "network", ID: id, Object: srcNetworks[id], Existing: existing})
+		}
+	}
+	for _, id := range sortedKeys(dstNetworks) {
+		if _, ok := srcNetworks[id]; !ok && prune {
+			networkDeletes = append(networkDeletes, Change{Action: ActionDelete, Kind: "network", ID: id, Object: dstNetworks[id], Existing: dstNetworks[id]})
+		}
+	}
+
+	nodeWrites, nodeDeletes := ChangeList{}, ChangeList{}
+	for _, id := range sortedKeys(srcNodes) {
+		existing, ok := dstNodes[id]
+		if !ok {
+			nodeWrites = append(nodeWrites, Change{Action: ActionCreate, Kind: "node", ID: id, Object: srcNodes[id]})
+		} else if !existing.Equal(srcNodes[id]) {
+			nodeWrites = append(nodeWrites, Change{Action: ActionUpdate, Kind: "node", ID: id, Object: srcNodes[id], Existing: existing})
+		}
+	}
+	for _, id := range sortedKeys(dstNodes) {
+		if _, ok := srcNodes[id]; !ok && prune {
+			nodeDeletes = append(nodeDeletes, Change{Action: ActionDelete, Kind: "node", ID: id, Object: dstNodes[id], Existing: dstNodes[id]})
+		}
+	}
+
+	changes := ChangeList{}
+	changes = append(changes, systemWrites...)
+	changes = append(changes, networkWrites...)
+	changes = append(changes, nodeDeletes...)
+	changes = append(changes, nodeWrites...)
+	changes = append(changes, networkDeletes...)
+	changes = append(changes, systemDeletes...)
+	return changes, nil
+}
+
+// Apply writes each change to the store in order, stopping at the first error.
+func (l ChangeList) Apply(dst Store) error {
+	for _, change := range l {
+		var objStore ObjectStore
+		switch change.Object.(type) {
+		case *types.Node:
+			objStore = dst.Node()
+		case *types.Network:
+			objStore = dst.Network()
+		case *types.System:
+			objStore = dst.System()
+		default:
+			return ErrInvalidObjectType
+		}
+
+		if ts, ok := change.Object.(interface{ SetTimestamp(time.Time) }); ok && change.Action != ActionDelete {
+			ts.SetTimestamp(time.Now())
+		}
+
+		var err error
+		switch change.Action {
+		case ActionCreate:
+			err = objStore.ObjCreate(change.Object)
+		case ActionUpdate:
+			err = objStore.ObjUpdate(change.Object)
+		case ActionDelete:
+			err = objStore.ObjDelete(change.Object)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %s: %v", change, err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch v := m.(type) {
+	case map[string]*types.Node:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*types.Network:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*types.System:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
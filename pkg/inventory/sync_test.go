@@ -0,0 +1,136 @@
+package inventory_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+type testSource struct {
+	nodes    map[string]*types.Node
+	networks map[string]*types.Network
+	systems  map[string]*types.System
+}
+
+func (s *testSource) GetNodes() (map[string]*types.Node, error)       { return s.nodes, nil }
+func (s *testSource) GetNetworks() (map[string]*types.Network, error) { return s.networks, nil }
+func (s *testSource) GetSystems() (map[string]*types.System, error)   { return s.systems, nil }
+
+func TestDiffAndApply(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	src := &testSource{
+		nodes: map[string]*types.Node{
+			"node0001": &types.Node{InventoryID: "node0001", System: "sys", Networks: types.NICInfoMap{"net": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}}},
+		},
+		networks: map[string]*types.Network{"net": &types.Network{Name: "net"}},
+		systems:  map[string]*types.System{"sys": &types.System{Name: "sys"}},
+	}
+
+	changes, err := inventory.Diff(inventory.NewStoreSource(inv), src, true)
+	if err != nil {
+		t.Fatalf("unable to diff: %v", err)
+	}
+
+	expected := []string{"create system sys", "create network net", "create node node0001"}
+	if len(changes) != len(expected) {
+		t.Fatalf("wrong changes returned: %v", changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("unexpected change at %d: got '%s', expected '%s'", i, change, expected[i])
+		}
+	}
+
+	err = changes.Apply(inv)
+	if err != nil {
+		t.Fatalf("unable to apply changes: %v", err)
+	}
+
+	changes, err = inventory.Diff(inventory.NewStoreSource(inv), src, true)
+	if err != nil {
+		t.Fatalf("unable to diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply: %v", changes)
+	}
+
+	src.nodes["node0001"].Role = "worker"
+	src.networks = map[string]*types.Network{"net": src.networks["net"]}
+	src.systems = map[string]*types.System{}
+	src.nodes["node0001"].System = ""
+	changes, err = inventory.Diff(inventory.NewStoreSource(inv), src, true)
+	if err != nil {
+		t.Fatalf("unable to diff: %v", err)
+	}
+
+	expected = []string{"update node node0001", "delete system sys"}
+	if len(changes) != len(expected) {
+		t.Fatalf("wrong changes returned: %v", changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("unexpected change at %d: got '%s', expected '%s'", i, change, expected[i])
+		}
+	}
+
+	err = changes.Apply(inv)
+	if err != nil {
+		t.Fatalf("unable to apply changes: %v", err)
+	}
+
+	node, err := inv.Node().GetNodeByID("node0001")
+	if err != nil {
+		t.Fatalf("unable to get node: %v", err)
+	}
+	if node.Role != "worker" {
+		t.Errorf("node was not updated: %v", node)
+	}
+
+	_, err = inv.System().GetSystemByID("sys")
+	if err != inventory.ErrObjectNotFound {
+		t.Errorf("system was not deleted: %v", err)
+	}
+}
+
+func TestDiffWithoutPrune(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+	err := inv.System().Create(&types.System{Name: "sys"})
+	if err != nil {
+		t.Fatalf("unable to create system: %v", err)
+	}
+
+	src := &testSource{systems: map[string]*types.System{"other": &types.System{Name: "other"}}}
+	changes, err := inventory.Diff(inventory.NewStoreSource(inv), src, false)
+	if err != nil {
+		t.Fatalf("unable to diff: %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].String() != "create system other" {
+		t.Errorf("unexpected changes without prune: %v", changes)
+	}
+}
+
+func TestChangeDiff(t *testing.T) {
+	existing := &types.System{Name: "sys", Roles: []string{"worker"}}
+	updated := &types.System{Name: "sys", Roles: []string{"master"}}
+	change := inventory.Change{Action: inventory.ActionUpdate, Kind: "system", ID: "sys", Object: updated, Existing: existing}
+
+	diff, err := change.Diff()
+	if err != nil {
+		t.Fatalf("unable to render diff: %v", err)
+	}
+
+	if !strings.Contains(diff, "\n-- worker\n+- master\n") || !strings.HasPrefix(diff, " name: sys\n") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	if strings.Contains(diff, "lastupdated") {
+		t.Errorf("diff should not include lastupdated:\n%s", diff)
+	}
+}
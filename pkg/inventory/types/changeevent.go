@@ -0,0 +1,52 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of change events
+const (
+	ChangeEventCreated = "created"
+	ChangeEventUpdated = "updated"
+	ChangeEventDeleted = "deleted"
+)
+
+// ChangeEvent is published after an inventory object has been changed.  Kind
+// is one of the object types recorded in history.  Before is empty for
+// created objects and After is empty for deleted objects.
+type ChangeEvent struct {
+	Type      string
+	Kind      string
+	ObjectID  string
+	Timestamp time.Time
+	Before    json.RawMessage `json:",omitempty"`
+	After     json.RawMessage `json:",omitempty"`
+}
+
+// NewChangeEvent creates an event for a change to an object made at the
+// current time.  before and after are stored as json.
+func NewChangeEvent(eventType string, kind string, objectID string, before interface{}, after interface{}) (*ChangeEvent, error) {
+	e := &ChangeEvent{
+		Type:      eventType,
+		Kind:      kind,
+		ObjectID:  objectID,
+		Timestamp: time.Now(),
+	}
+
+	var err error
+	if before != nil {
+		e.Before, err = json.Marshal(before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if after != nil {
+		e.After, err = json.Marshal(after)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
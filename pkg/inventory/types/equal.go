@@ -0,0 +1,105 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Equal returns true if both nodes describe the same node.  LastUpdated is
+// ignored, as are empty and unset attributes.
+func (n *Node) Equal(other *Node) bool {
+	if n == nil || other == nil {
+		return n == other
+	}
+	return equalIgnoringTimestamp(n, other)
+}
+
+// Equal returns true if both networks describe the same network.  LastUpdated
+// is ignored, as are empty and unset attributes.
+func (n *Network) Equal(other *Network) bool {
+	if n == nil || other == nil {
+		return n == other
+	}
+	return equalIgnoringTimestamp(n, other)
+}
+
+// Equal returns true if both systems describe the same system.  LastUpdated is
+// ignored, as are empty and unset attributes.
+func (s *System) Equal(other *System) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return equalIgnoringTimestamp(s, other)
+}
+
+// equalIgnoringTimestamp compares the json representations of a and b so that
+// nil and empty values are treated the same.
+func equalIgnoringTimestamp(a, b interface{}) bool {
+	aMap, err := comparableMap(a)
+	if err != nil {
+		return false
+	}
+
+	bMap, err := comparableMap(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aMap, bMap)
+}
+
+func comparableMap(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	delete(m, "LastUpdated")
+	return pruneEmpty(m), nil
+}
+
+// pruneEmpty recursively removes zero values from maps, returning nil if
+// nothing is left.
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			pruned := pruneEmpty(val)
+			if pruned == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = pruned
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, val := range v {
+			v[i] = pruneEmpty(val)
+		}
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Object types recorded in history and revisions
+const (
+	HistoryObjectNode          = "node"
+	HistoryObjectNetwork       = "network"
+	HistoryObjectSystem        = "system"
+	HistoryObjectIPReservation = "ipreservation"
+)
+
+// Actions recorded in history
+const (
+	HistoryActionCreate = "create"
+	HistoryActionUpdate = "update"
+	HistoryActionDelete = "delete"
+)
+
+// HistoryRecord describes a single change to an inventory object.  Before is
+// empty for creates and After is empty for deletes.  Records are never
+// modified once they've been written.
+type HistoryRecord struct {
+	ObjectType string
+	ObjectID   string
+	Timestamp  time.Time
+	Action     string
+	Actor      string
+	Before     json.RawMessage `json:",omitempty"`
+	After      json.RawMessage `json:",omitempty"`
+}
+
+// NewHistoryRecord creates a record of a change to an object made by actor at
+// the current time.  before and after are stored as json.
+func NewHistoryRecord(objectType string, objectID string, action string, actor string, before interface{}, after interface{}) (*HistoryRecord, error) {
+	r := &HistoryRecord{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Timestamp:  time.Now(),
+		Action:     action,
+		Actor:      actor,
+	}
+
+	var err error
+	if before != nil {
+		r.Before, err = json.Marshal(before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if after != nil {
+		r.After, err = json.Marshal(after)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// HistoryKey returns the key the history of the object is stored under
+func HistoryKey(objectType string, objectID string) string {
+	return objectType + "/" + objectID
+}
+
+// Key returns the key the history of the changed object is stored under
+func (r *HistoryRecord) Key() string {
+	return HistoryKey(r.ObjectType, r.ObjectID)
+}
+
+// Sequence orders the records of an object's history
+func (r *HistoryRecord) Sequence() int64 {
+	return r.Timestamp.UnixNano()
+}
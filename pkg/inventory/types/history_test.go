@@ -0,0 +1,24 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestNewHistoryRecord(t *testing.T) {
+	r, err := NewHistoryRecord(HistoryObjectNode, "test", HistoryActionCreate, "tester", nil, &Node{InventoryID: "test"})
+	if err != nil {
+		t.Fatalf("unable to create history record: %v", err)
+	}
+
+	if r.Key() != "node/test" {
+		t.Errorf("unexpected history key: %s", r.Key())
+	}
+
+	if r.Before != nil {
+		t.Errorf("expected no previous version for a create: %s", string(r.Before))
+	}
+
+	if len(r.After) == 0 || r.Timestamp.IsZero() || r.Sequence() != r.Timestamp.UnixNano() {
+		t.Errorf("record is incomplete: %v", r)
+	}
+}
@@ -20,6 +20,7 @@ type IPReservation struct {
 	DNS             []net.IP         `json:"dns"`
 	Start           *time.Time       `json:"start"`
 	End             *time.Time       `json:"end"`
+	Deleted         *time.Time       `json:"deleted,omitempty"`
 	Metadata        Metadata         `json:"metadata"`
 }
 
@@ -80,13 +81,26 @@ func (r *IPReservation) ValidAt(t time.Time) bool {
 		return false
 	}
 
-	if r.End != nil && r.End.Before(t) {
+	if r.Ended(t) {
 		return false
 	}
 
 	return true
 }
 
+// Ended returns true if the reservation expired or was deleted before the
+// time specified.  Deleted reservations are kept, with Deleted set to the time
+// they were deleted, so that they can be looked up as they were in the past.
+func (r *IPReservation) Ended(t time.Time) bool {
+	return (r.End != nil && r.End.Before(t)) || (r.Deleted != nil && r.Deleted.Before(t))
+}
+
+// Expired returns true if the reservation's End is before the time specified.
+// Deleting a reservation doesn't make it expire.
+func (r *IPReservation) Expired(t time.Time) bool {
+	return r.End != nil && r.End.Before(t)
+}
+
 func (r *IPReservation) Static() bool {
 	return r.End == nil
 }
@@ -177,6 +191,40 @@ func (r *IPReservation) UnmarshalJSON(data []byte) error {
 	}
 	return err
 }
+
+// MarshalYAML renders the ip and mac as strings
+func (r *IPReservation) MarshalYAML() (interface{}, error) {
+	v := &struct {
+		HostInformation string     `yaml:"hostinformation,omitempty"`
+		IP              string     `yaml:"ip"`
+		MAC             string     `yaml:"mac"`
+		Gateway         string     `yaml:"gateway,omitempty"`
+		DNS             []string   `yaml:"dns,omitempty"`
+		Start           *time.Time `yaml:"start,omitempty"`
+		End             *time.Time `yaml:"end,omitempty"`
+		Deleted         *time.Time `yaml:"deleted,omitempty"`
+		Metadata        Metadata   `yaml:"metadata"`
+	}{
+		HostInformation: r.HostInformation,
+		MAC:             r.MAC.String(),
+		Start:           r.Start,
+		End:             r.End,
+		Deleted:         r.Deleted,
+		Metadata:        r.Metadata,
+	}
+
+	if r.IP != nil {
+		v.IP = r.IP.String()
+	}
+	if r.Gateway != nil {
+		v.Gateway = r.Gateway.String()
+	}
+	for _, dns := range r.DNS {
+		v.DNS = append(v.DNS, dns.String())
+	}
+	return v, nil
+}
+
 func (r *IPReservation) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
 	av.M = make(map[string]*dynamodb.AttributeValue, 0)
 
@@ -218,6 +266,14 @@ func (r *IPReservation) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValu
 		av.M["End"] = e
 	}
 
+	if r.Deleted != nil {
+		d, err := dynamodbattribute.Marshal(r.Deleted.Unix())
+		if err != nil {
+			return err
+		}
+		av.M["Deleted"] = d
+	}
+
 	metadataAv, err := dynamodbattribute.Marshal(r.Metadata)
 	if err != nil {
 		return err
@@ -268,6 +324,16 @@ func (r *IPReservation) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeVa
 		r.End = &e
 	}
 
+	if v, ok := av.M["Deleted"]; ok && v.NULL == nil {
+		dEpoch := new(int64)
+		err := dynamodbattribute.Unmarshal(v, dEpoch)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal deleted time: %v", err)
+		}
+		d := time.Unix(*dEpoch, 0)
+		r.Deleted = &d
+	}
+
 	if v, ok := av.M["Metadata"]; ok && v.NULL == nil {
 		metadata := make(Metadata)
 		err := dynamodbattribute.Unmarshal(v, &metadata)
@@ -314,6 +380,28 @@ func (l IPReservationList) ValidAt(t time.Time) IPReservationList {
 	return result
 }
 
+// Active returns the reservations that haven't ended by the time specified
+func (l IPReservationList) Active(t time.Time) IPReservationList {
+	result := IPReservationList{}
+	for _, r := range l {
+		if !r.Ended(t) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Expired returns the reservations that expired before the time specified
+func (l IPReservationList) Expired(t time.Time) IPReservationList {
+	result := IPReservationList{}
+	for _, r := range l {
+		if r.Expired(t) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 func (l IPReservationList) Contains(ip net.IP) bool {
 	for _, r := range l {
 		if r.IP.IP.Equal(ip) {
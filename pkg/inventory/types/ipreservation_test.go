@@ -46,6 +46,18 @@ func TestIPReservationValidAt(t *testing.T) {
 			t:     time.Date(2019, 05, 23, 05, 22, 35, 0, time.Local),
 			valid: false,
 		},
+		{
+			name:  "Valid Before Deleted",
+			r:     IPReservation{Start: &startTimeLocal, Deleted: &endTimeLocal},
+			t:     time.Date(2019, 05, 23, 05, 23, 35, 0, time.Local),
+			valid: true,
+		},
+		{
+			name:  "Invalid After Deleted",
+			r:     IPReservation{Start: &startTimeLocal, Deleted: &endTimeLocal},
+			t:     time.Date(2019, 05, 23, 06, 1, 35, 0, time.Local),
+			valid: false,
+		},
 	}
 
 	for _, c := range cases {
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
@@ -19,6 +21,39 @@ func (m *Metadata) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue)
 	return nil
 }
 
+// UnmarshalYAML converts any nested maps to map[string]interface{} so that the
+// metadata can be marshaled to json.
+func (m *Metadata) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	md := map[string]interface{}{}
+	err := unmarshal(&md)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range md {
+		md[k] = stringKeys(v)
+	}
+	*m = md
+	return nil
+}
+
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprintf("%v", key)] = stringKeys(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = stringKeys(val)
+		}
+		return v
+	}
+	return value
+}
+
 // GetString attempts to get a string value with the provided key.
 func (m Metadata) GetString(key string) (string, bool) {
 	if iVal, ok := m[key]; ok {
@@ -30,6 +30,12 @@ func (n *Network) SetTimestamp(timestamp time.Time) {
 	n.LastUpdated = timestamp
 }
 
+// Version returns the LastUpdated timestamp, which identifies the revision of
+// the network that was read
+func (n *Network) Version() time.Time {
+	return n.LastUpdated
+}
+
 func (n *Network) GetSubnetContainingIP(ip net.IP) *Subnet {
 	for _, subnet := range n.Subnets {
 		if subnet.Cidr.Contains(ip) {
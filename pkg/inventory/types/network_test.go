@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/go-test/deep"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func getTestNetwork() (*Network, string) {
@@ -48,6 +49,51 @@ func TestNetworkUnmarshalJSON(t *testing.T) {
 	testUnmarshalJSON(t, net, expected, jsonString)
 }
 
+func TestNetworkUnmarshalYAML(t *testing.T) {
+	expected, _ := getTestNetwork()
+	yamlString := `name: test_phys
+mtu: 9000
+domain: test.local
+subnets:
+  - name: testsubnet
+    cidr: 10.0.0.0/24
+    gateway: 10.0.0.254
+    dns:
+      - 10.53.53.53
+lastupdated: 1973-11-29T21:33:09Z
+metadata:
+  foo: test
+  bar: 34.1
+`
+	net := &Network{}
+	testUnmarshalYAML(t, net, expected, yamlString)
+}
+
+func TestNetworkMarshalYAMLRoundTrip(t *testing.T) {
+	expected, _ := getTestNetwork()
+	data, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Unable to marshal: %v", err)
+	}
+
+	net := &Network{}
+	testUnmarshalYAML(t, net, expected, string(data))
+}
+
+func TestNetworkEqual(t *testing.T) {
+	a, _ := getTestNetwork()
+	b, _ := getTestNetwork()
+	b.LastUpdated = time.Now()
+	if !a.Equal(b) {
+		t.Errorf("networks differing only in LastUpdated should be equal")
+	}
+
+	b.MTU = 1500
+	if a.Equal(b) {
+		t.Errorf("networks with different MTUs should not be equal")
+	}
+}
+
 func TestNetworkSetTimestamp(t *testing.T) {
 	n := &Network{}
 	ts := time.Now()
@@ -155,6 +155,61 @@ func (n *NetworkInterface) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalYAML renders the nics as strings
+func (n *NetworkInterface) MarshalYAML() (interface{}, error) {
+	nics := make([]string, 0, len(n.NICs))
+	for _, mac := range n.NICs {
+		nics = append(nics, mac.String())
+	}
+
+	metadata := n.Metadata
+	if metadata == nil {
+		metadata = make(Metadata)
+	}
+
+	return &struct {
+		NICs     []string `yaml:"nics"`
+		Metadata Metadata `yaml:"metadata"`
+	}{NICs: nics, Metadata: metadata}, nil
+}
+
+// UnmarshalYAML unmarshals a NetworkInterface, accepting either a list of nics
+// or the legacy NICInfo form with a single mac.
+func (n *NetworkInterface) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	v := &struct {
+		NICs     []string `yaml:"nics"`
+		Metadata Metadata `yaml:"metadata"`
+	}{}
+	err := unmarshal(v)
+	if err != nil {
+		return err
+	}
+
+	n.Metadata = v.Metadata
+	if n.Metadata == nil {
+		n.Metadata = make(Metadata)
+	}
+
+	n.NICs = make([]net.HardwareAddr, 0, len(v.NICs))
+	for _, macString := range v.NICs {
+		mac, err := net.ParseMAC(macString)
+		if err != nil {
+			return fmt.Errorf("unable to parse mac '%s': %v", macString, err)
+		}
+		n.NICs = append(n.NICs, mac)
+	}
+
+	legacy := &NICInfo{}
+	err = unmarshal(legacy)
+	if err != nil {
+		return err
+	}
+	if len(legacy.MAC) > 0 {
+		n.NICs = append(n.NICs, legacy.MAC)
+	}
+	return nil
+}
+
 func (n *NetworkInterface) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
 	type Alias NetworkInterface
 	iface := &Alias{}
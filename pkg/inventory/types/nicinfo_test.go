@@ -43,6 +43,18 @@ func TestNICInfoUnmarshalJSON(t *testing.T) {
 	testUnmarshalJSON(t, info, expected, testText)
 }
 
+func TestNICInfoUnmarshalYAML(t *testing.T) {
+	expected, _ := getTestNICInfo()
+	info := &NetworkInterface{}
+	testUnmarshalYAML(t, info, expected, "nics:\n  - 00:02:03:04:05:06\n")
+}
+
+func TestLegacyNICInfoUnmarshalYAML(t *testing.T) {
+	expected, _ := getTestNICInfo()
+	info := &NetworkInterface{}
+	testUnmarshalYAML(t, info, expected, "mac: 00:02:03:04:05:06\n")
+}
+
 func TestLegacyNICInfoDynamoDBUnmarshal(t *testing.T) {
 	mac, _ := net.ParseMAC("00:01:02:03:04:05")
 	ni := &NICInfo{MAC: mac}
@@ -37,6 +37,12 @@ func (n *Node) SetTimestamp(timestamp time.Time) {
 	n.LastUpdated = timestamp
 }
 
+// Version returns the LastUpdated timestamp, which identifies the revision of
+// the node that was read
+func (n *Node) Version() time.Time {
+	return n.LastUpdated
+}
+
 func (n *Node) Location() string {
 	if n.ChassisLocation != nil && n.Rack != "" && n.ChassisSubIndex != "" {
 		return fmt.Sprintf("%s-%0.2d-%s", n.Rack, n.BottomU, n.ChassisSubIndex)
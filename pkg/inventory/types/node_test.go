@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"testing"
 	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 func getTestNode() (*Node, string) {
@@ -46,6 +48,64 @@ func TestNodeUnmarshalJSON(t *testing.T) {
 	testUnmarshalJSON(t, node, expected, jsonString)
 }
 
+func TestNodeUnmarshalYAML(t *testing.T) {
+	expected, _ := getTestNode()
+	yamlString := `inventoryid: sample0002
+chassislocation:
+  building: 123 Fake St
+  room: "305"
+  rack: te12
+  bottomu: 4
+chassissubindex: a
+tags:
+  - foo
+  - bar
+  - baz
+networks:
+  test_phys:
+    mac: 00:02:03:04:05:06
+role: worker
+environment: production
+system: test
+lastupdated: 1973-11-29T21:33:09Z
+metadata:
+  foo: test
+  bar: 34.1
+`
+	node := &Node{}
+	testUnmarshalYAML(t, node, expected, yamlString)
+}
+
+func TestNodeMarshalYAMLRoundTrip(t *testing.T) {
+	expected, _ := getTestNode()
+	data, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Unable to marshal: %v", err)
+	}
+
+	node := &Node{}
+	testUnmarshalYAML(t, node, expected, string(data))
+}
+
+func TestNodeEqual(t *testing.T) {
+	a, _ := getTestNode()
+	b, _ := getTestNode()
+	b.LastUpdated = time.Now()
+	b.Networks["test_phys"].Metadata = nil
+	if !a.Equal(b) {
+		t.Errorf("nodes differing only in LastUpdated and empty metadata should be equal")
+	}
+
+	b.Tags = []string{"foo"}
+	if a.Equal(b) {
+		t.Errorf("nodes with different tags should not be equal")
+	}
+
+	if a.Equal(nil) {
+		t.Errorf("node should not equal nil")
+	}
+}
+
 func TestNodeID(t *testing.T) {
 	n := &Node{InventoryID: "foo2341"}
 	if n.ID() != "foo2341" {
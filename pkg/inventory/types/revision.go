@@ -0,0 +1,68 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is a copy of an object as it was stored from Timestamp, the
+// object's LastUpdated timestamp, until its next revision.  Deleted revisions
+// record the time the object was removed and have no copy.
+type Revision struct {
+	ObjectType string
+	ObjectID   string
+	Timestamp  time.Time
+	Deleted    bool
+	Object     json.RawMessage `json:",omitempty"`
+}
+
+// NewRevision creates a revision holding a copy of obj
+func NewRevision(objectType string, objectID string, timestamp time.Time, obj interface{}) (*Revision, error) {
+	doc, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &Revision{ObjectType: objectType, ObjectID: objectID, Timestamp: timestamp, Object: doc}, nil
+}
+
+// NewDeletedRevision creates a revision recording that the object was
+// deleted at timestamp
+func NewDeletedRevision(objectType string, objectID string, timestamp time.Time) *Revision {
+	return &Revision{ObjectType: objectType, ObjectID: objectID, Timestamp: timestamp, Deleted: true}
+}
+
+// Key returns the key the revisions of the object are stored under
+func (r *Revision) Key() string {
+	return HistoryKey(r.ObjectType, r.ObjectID)
+}
+
+// Sequence orders the revisions of an object.  Objects without a timestamp
+// are treated as if they were stored at the unix epoch.
+func (r *Revision) Sequence() int64 {
+	return RevisionSequence(r.Timestamp)
+}
+
+// RevisionSequence returns the sequence of a revision stored at t
+func RevisionSequence(t time.Time) int64 {
+	if t.Before(time.Unix(0, 0)) {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// Unmarshal copies the object stored in the revision into out
+func (r *Revision) Unmarshal(out interface{}) error {
+	return json.Unmarshal(r.Object, out)
+}
+
+// ArchivedIPReservationObject is the object type of the revisions that keep
+// reservations once they've ended and are replaced or purged.  They're stored
+// under the reservation's mac and timestamped when they're archived, which is
+// always after the reservation ended.
+const ArchivedIPReservationObject = "archivedipreservation"
+
+// NewArchivedIPReservationRevision creates a revision holding a copy of the
+// reservation, archived at timestamp
+func NewArchivedIPReservationRevision(r *IPReservation, timestamp time.Time) (*Revision, error) {
+	return NewRevision(ArchivedIPReservationObject, r.MAC.String(), timestamp, r)
+}
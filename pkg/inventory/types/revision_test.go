@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevision(t *testing.T) {
+	timestamp := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	r, err := NewRevision(HistoryObjectSystem, "test", timestamp, &System{Name: "test", Roles: []string{"worker"}})
+	if err != nil {
+		t.Fatalf("unable to create revision: %v", err)
+	}
+
+	if r.Key() != "system/test" || r.Sequence() != timestamp.UnixNano() {
+		t.Errorf("unexpected revision key: %s %d", r.Key(), r.Sequence())
+	}
+
+	system := &System{}
+	err = r.Unmarshal(system)
+	if err != nil || system.Name != "test" || len(system.Roles) != 1 {
+		t.Errorf("unable to unmarshal revision: %v %v", system, err)
+	}
+
+	if r := NewDeletedRevision(HistoryObjectSystem, "test", time.Time{}); !r.Deleted || r.Sequence() != 0 {
+		t.Errorf("expected a deleted revision at the epoch: %v", r)
+	}
+}
@@ -2,8 +2,11 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"net"
 
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
@@ -18,6 +21,9 @@ type Subnet struct {
 	DNS                     []net.IP
 	StaticAllocationMethod  string
 	DynamicAllocationMethod string
+	LocationScheme          *ipam.V4LocationScheme `json:",omitempty" dynamodbav:",omitempty"`
+	AllocationRanges        []ipam.IPRange         `json:",omitempty" dynamodbav:",omitempty"`
+	Exclusions              []ipam.IPRange         `json:",omitempty" dynamodbav:",omitempty"`
 }
 
 // ToNet creates an IPNet object from the supplied ip with the Cidr mask for this subnet
@@ -35,6 +41,155 @@ func (s Subnet) DynamicAllocationEnabled() bool {
 	return s.DynamicAllocationMethod != ""
 }
 
+// Pools returns the ranges of addresses that may be allocated in this subnet:
+// the allocation ranges if any are set, otherwise every host address.
+func (s Subnet) Pools() []ipam.IPRange {
+	if len(s.AllocationRanges) > 0 {
+		return s.AllocationRanges
+	}
+	return []ipam.IPRange{ipam.HostRange(s.Cidr)}
+}
+
+// ExcludedRanges returns the ranges of addresses that must never be
+// allocated: the exclusions, the gateway and the DNS servers.
+func (s Subnet) ExcludedRanges() []ipam.IPRange {
+	excluded := make([]ipam.IPRange, 0, len(s.Exclusions)+len(s.DNS)+1)
+	excluded = append(excluded, s.Exclusions...)
+	if s.Gateway != nil {
+		excluded = append(excluded, ipam.IPRange{Start: s.Gateway})
+	}
+	for _, dns := range s.DNS {
+		excluded = append(excluded, ipam.IPRange{Start: dns})
+	}
+	return excluded
+}
+
+// Exclusion returns the excluded range that contains ip, or nil if ip isn't
+// excluded
+func (s Subnet) Exclusion(ip net.IP) *ipam.IPRange {
+	for _, r := range s.ExcludedRanges() {
+		if r.Contains(ip) {
+			return &r
+		}
+	}
+	return nil
+}
+
+// Allocatable returns true if ip is a host address in one of the pools of
+// this subnet and isn't excluded
+func (s Subnet) Allocatable(ip net.IP) bool {
+	if !s.Cidr.Contains(ip) || !ipam.HostRange(s.Cidr).Contains(ip) || s.Exclusion(ip) != nil {
+		return false
+	}
+
+	for _, pool := range s.Pools() {
+		if pool.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostPools returns the pools of this subnet limited to its host addresses,
+// merged so that no address appears twice
+func (s Subnet) hostPools() []ipam.IPRange {
+	hosts := ipam.HostRange(s.Cidr)
+	pools := make([]ipam.IPRange, 0, len(s.Pools()))
+	for _, pool := range s.Pools() {
+		if r, ok := pool.Intersect(hosts); ok {
+			pools = append(pools, r)
+		}
+	}
+	return ipam.MergeRanges(pools)
+}
+
+// UsableSize returns the number of addresses that may be allocated in this
+// subnet, whether or not they're reserved
+func (s Subnet) UsableSize() *big.Int {
+	excluded := ipam.MergeRanges(s.ExcludedRanges())
+	usable := new(big.Int)
+	for _, pool := range s.hostPools() {
+		usable.Add(usable, pool.RangeSize())
+		for _, e := range excluded {
+			if r, ok := pool.Intersect(e); ok {
+				usable.Sub(usable, r.RangeSize())
+			}
+		}
+	}
+	return usable
+}
+
+// FreeIPs returns up to n of the lowest addresses that may be allocated in
+// this subnet and aren't in reserved
+func (s Subnet) FreeIPs(reserved IPReservationList, n int) []net.IP {
+	free := make([]net.IP, 0, n)
+	for _, pool := range s.hostPools() {
+		ip := pool.Start
+		for len(free) < n {
+			if excluded := s.Exclusion(ip); excluded != nil {
+				// skip to the end of the excluded range
+				ip = excluded.Last()
+				if ipam.CompareIP(ip, pool.Last()) > 0 {
+					ip = pool.Last()
+				}
+			} else if !reserved.Contains(ip) {
+				free = append(free, ip)
+			}
+
+			if ipam.CompareIP(ip, pool.Last()) >= 0 {
+				break
+			}
+			ip = ipam.NextIP(ip)
+		}
+	}
+	return free
+}
+
+// ValidateRanges checks that the allocation ranges and exclusions lie within
+// this subnet
+func (s Subnet) ValidateRanges() error {
+	for _, r := range append(append([]ipam.IPRange{}, s.AllocationRanges...), s.Exclusions...) {
+		if err := r.Validate(s.Cidr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// V4LocationScheme returns the scheme used to allocate ipv4 addresses by
+// location in this subnet
+func (s Subnet) V4LocationScheme() *ipam.V4LocationScheme {
+	if s.LocationScheme != nil {
+		return s.LocationScheme
+	}
+	return ipam.DefaultV4LocationScheme
+}
+
+// LocationIP returns the address of the node in this subnet based on its
+// chassis location.  An error is returned if the address is one of the
+// reserved addresses.
+func (s Subnet) LocationIP(node *Node, reservedAddresses ...net.IP) (net.IP, error) {
+	if node.ChassisLocation == nil || node.Rack == "" {
+		return nil, fmt.Errorf("node %s has no chassis location", node.ID())
+	}
+
+	if !ipam.IsV6(s.Cidr.IP) {
+		return s.V4LocationScheme().GetIP(s.Cidr, node.Rack, node.BottomU, node.ChassisSubIndex, reservedAddresses...)
+	}
+
+	ip, err := ipam.GetIPByLocation(s.Cidr, node.Rack, node.BottomU, node.ChassisSubIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reserved := range reservedAddresses {
+		if reserved.Equal(ip) {
+			return nil, fmt.Errorf("ip conflicts with reserved address: %s", reserved)
+		}
+	}
+	return ip, nil
+}
+
 // MarshalJSON implements the Marshaler Interface so that cidr is rendered as a
 // string.
 func (s *Subnet) MarshalJSON() ([]byte, error) {
@@ -68,6 +223,76 @@ func (s *Subnet) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// MarshalYAML implements the yaml Marshaler interface so that cidr is rendered
+// as a string.
+func (s *Subnet) MarshalYAML() (interface{}, error) {
+	v := &struct {
+		Name                    string
+		Cidr                    string
+		Gateway                 net.IP
+		DNS                     []net.IP
+		StaticAllocationMethod  string
+		DynamicAllocationMethod string
+		LocationScheme          *ipam.V4LocationScheme `yaml:",omitempty"`
+		AllocationRanges        []ipam.IPRange         `yaml:",omitempty"`
+		Exclusions              []ipam.IPRange         `yaml:",omitempty"`
+	}{
+		Name:                    s.Name,
+		Gateway:                 s.Gateway,
+		DNS:                     s.DNS,
+		StaticAllocationMethod:  s.StaticAllocationMethod,
+		DynamicAllocationMethod: s.DynamicAllocationMethod,
+		LocationScheme:          s.LocationScheme,
+		AllocationRanges:        s.AllocationRanges,
+		Exclusions:              s.Exclusions,
+	}
+	if s.Cidr != nil {
+		v.Cidr = s.Cidr.String()
+	}
+	return v, nil
+}
+
+// UnmarshalYAML implements the yaml Unmarshaler interface so that cidr can be
+// directly read from a string
+func (s *Subnet) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	v := &struct {
+		Name                    string
+		Cidr                    string
+		Gateway                 net.IP
+		DNS                     []net.IP
+		StaticAllocationMethod  string
+		DynamicAllocationMethod string
+		AllocationMethod        string
+		LocationScheme          *ipam.V4LocationScheme
+		AllocationRanges        []ipam.IPRange
+		Exclusions              []ipam.IPRange
+	}{}
+	err := unmarshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, cidr, err := net.ParseCIDR(v.Cidr)
+	if err != nil {
+		return err
+	}
+
+	s.Name = v.Name
+	s.Cidr = cidr
+	s.Gateway = v.Gateway
+	s.DNS = v.DNS
+	s.StaticAllocationMethod = v.StaticAllocationMethod
+	s.DynamicAllocationMethod = v.DynamicAllocationMethod
+	s.LocationScheme = v.LocationScheme
+	s.AllocationRanges = v.AllocationRanges
+	s.Exclusions = v.Exclusions
+	if v.AllocationMethod != "" {
+		s.StaticAllocationMethod = v.AllocationMethod
+		s.DynamicAllocationMethod = v.AllocationMethod
+	}
+	return nil
+}
+
 func (n *Subnet) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
 	type Alias Subnet
 	v := Alias{}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"net"
 	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/ipam"
+	"github.com/go-test/deep"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func getTestSubnetV4() (*Subnet, string) {
@@ -35,6 +39,32 @@ func TestSubnetUnmarshalJSONV4(t *testing.T) {
 	testUnmarshalJSON(t, subnet, expected, testText)
 }
 
+func TestSubnetUnmarshalYAMLV4(t *testing.T) {
+	expected, _ := getTestSubnetV4()
+	subnet := &Subnet{}
+	yamlString := `name: test
+cidr: 10.0.0.0/24
+gateway: 10.0.0.254
+dns:
+  - 10.0.1.1
+  - 10.0.2.2
+staticallocationmethod: random
+`
+	testUnmarshalYAML(t, subnet, expected, yamlString)
+}
+
+func TestSubnetUnmarshalYAMLLegacyAllocationMethod(t *testing.T) {
+	subnet := &Subnet{}
+	err := yaml.Unmarshal([]byte("cidr: 10.0.0.0/24\nallocationmethod: random\n"), subnet)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal: %v", err)
+	}
+
+	if subnet.StaticAllocationMethod != "random" || subnet.DynamicAllocationMethod != "random" {
+		t.Errorf("Legacy allocation method not applied: %v", subnet)
+	}
+}
+
 func getTestSubnetV6() (*Subnet, string) {
 	gateway := net.ParseIP("2001:db8:0:1::1")
 	_, cidr, _ := net.ParseCIDR("2001:db8:0:1::/64")
@@ -63,3 +93,91 @@ func TestSubnetUnmarshalJSONV6(t *testing.T) {
 	subnet := &Subnet{}
 	testUnmarshalJSON(t, subnet, expected, testText)
 }
+
+func TestSubnetUnmarshalYAMLLocationScheme(t *testing.T) {
+	subnet := &Subnet{}
+	err := yaml.Unmarshal([]byte("cidr: 10.0.0.0/20\nlocationscheme:\n  rackbits: 3\n  ubits: 6\n  subindexbits: 2\n  offset: 16\n"), subnet)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal: %v", err)
+	}
+
+	expected := &ipam.V4LocationScheme{RackBits: 3, UBits: 6, SubIndexBits: 2, Offset: 16}
+	if diff := deep.Equal(subnet.LocationScheme, expected); len(diff) > 0 {
+		t.Errorf("Location scheme not unmarshaled correctly: %v", diff)
+	}
+}
+
+func TestSubnetLocationIP(t *testing.T) {
+	_, v4cidr, _ := net.ParseCIDR("10.0.0.0/16")
+	_, v6cidr, _ := net.ParseCIDR("2001:db8::/64")
+	node := &Node{InventoryID: "sample0000", ChassisLocation: &ChassisLocation{Rack: "xr20", BottomU: 31}, ChassisSubIndex: "a"}
+
+	cases := []struct {
+		Subnet   *Subnet
+		Expected net.IP
+	}{
+		{&Subnet{Cidr: v4cidr}, net.ParseIP("10.0.81.250").To4()},
+		{&Subnet{Cidr: v4cidr, LocationScheme: &ipam.V4LocationScheme{RackBits: 5, UBits: 6, SubIndexBits: 4, Offset: 1}}, net.ParseIP("10.0.81.251").To4()},
+		{&Subnet{Cidr: v6cidr}, net.ParseIP("2001:db8::e01c:e1fa:0:1")},
+	}
+
+	for _, c := range cases {
+		ip, err := c.Subnet.LocationIP(node)
+		if err != nil {
+			t.Errorf("unable to get ip in %s: %v", c.Subnet.Cidr, err)
+			continue
+		}
+
+		if !ip.Equal(c.Expected) {
+			t.Errorf("got wrong ip in %s: expected %s, got %s", c.Subnet.Cidr, c.Expected, ip)
+		}
+
+		_, err = c.Subnet.LocationIP(node, c.Expected)
+		if err == nil {
+			t.Errorf("expected a collision with the reserved address in %s", c.Subnet.Cidr)
+		}
+	}
+
+	_, err := (&Subnet{Cidr: v4cidr}).LocationIP(&Node{InventoryID: "sample0001"})
+	if err == nil {
+		t.Errorf("expected an error for a node without a location")
+	}
+}
+
+func TestSubnetAllocatable(t *testing.T) {
+	subnet := &Subnet{}
+	err := yaml.Unmarshal([]byte(`cidr: 10.0.0.0/24
+gateway: 10.0.0.1
+dns:
+  - 10.0.0.2
+allocationranges:
+  - start: 10.0.0.1
+    end: 10.0.0.100
+exclusions:
+  - start: 10.0.0.50
+    end: 10.0.0.59
+`), subnet)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal: %v", err)
+	}
+
+	cases := map[string]bool{
+		"10.0.0.1":   false,
+		"10.0.0.2":   false,
+		"10.0.0.3":   true,
+		"10.0.0.55":  false,
+		"10.0.0.60":  true,
+		"10.0.0.101": false,
+		"10.0.1.3":   false,
+	}
+
+	for ip, expected := range cases {
+		if subnet.Allocatable(net.ParseIP(ip)) != expected {
+			t.Errorf("expected allocatable to be %t for %s", expected, ip)
+		}
+	}
+
+	if err := subnet.ValidateRanges(); err != nil {
+		t.Errorf("expected ranges to be valid: %v", err)
+	}
+}
@@ -30,3 +30,9 @@ func (s *System) Timestamp() int64 {
 func (s *System) SetTimestamp(timestamp time.Time) {
 	s.LastUpdated = timestamp
 }
+
+// Version returns the LastUpdated timestamp, which identifies the revision of
+// the system that was read
+func (s *System) Version() time.Time {
+	return s.LastUpdated
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"testing"
 	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 func getTestSystem() (*System, string, string) {
@@ -82,3 +84,30 @@ func TestSystemSetTimestamp(t *testing.T) {
 		t.Errorf("Timestamp returned doesn't match the time set.")
 	}
 }
+
+func TestSystemUnmarshalYAMLNestedMetadata(t *testing.T) {
+	sys := &System{}
+	err := yaml.Unmarshal([]byte("name: test\nmetadata:\n  nested:\n    key: value\n"), sys)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal: %v", err)
+	}
+
+	_, err = json.Marshal(sys)
+	if err != nil {
+		t.Errorf("Unable to marshal system with nested metadata: %v", err)
+	}
+}
+
+func TestSystemEqual(t *testing.T) {
+	a, _, _ := getTestSystem()
+	b, _, _ := getTestSystem()
+	b.LastUpdated = time.Now()
+	if !a.Equal(b) {
+		t.Errorf("systems differing only in LastUpdated should be equal")
+	}
+
+	b.Roles = append(b.Roles, "master")
+	if a.Equal(b) {
+		t.Errorf("systems with different roles should not be equal")
+	}
+}
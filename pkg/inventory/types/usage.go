@@ -0,0 +1,71 @@
+package types
+
+import (
+	"math/big"
+	"net"
+	"time"
+)
+
+// SubnetUsage reports how much of a subnet has been allocated.  Total counts
+// every address in the subnet and Usable the addresses that may be allocated.
+// Allocated, Static and Dynamic count the reservations that are active, and
+// Expired the reservations that have expired but haven't been reaped.
+// Reservations lists the active reservations.
+type SubnetUsage struct {
+	Name         string
+	Cidr         string
+	Total        *big.Int
+	Usable       *big.Int
+	Allocated    int
+	Static       int
+	Dynamic      int
+	Expired      int
+	Free         []net.IP
+	Reservations IPReservationList
+}
+
+// NewSubnetUsage calculates the usage of the subnet at the time specified from
+// its reservations, listing up to free of its lowest free addresses
+func NewSubnetUsage(subnet *Subnet, reservations IPReservationList, at time.Time, free int) *SubnetUsage {
+	ones, bits := subnet.Cidr.Mask.Size()
+	active := reservations.Active(at)
+
+	return &SubnetUsage{
+		Name:         subnet.Name,
+		Cidr:         subnet.Cidr.String(),
+		Total:        new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)),
+		Usable:       subnet.UsableSize(),
+		Allocated:    len(active),
+		Static:       len(active.Static()),
+		Dynamic:      len(active.Dynamic()),
+		Expired:      len(reservations.Expired(at)),
+		Free:         subnet.FreeIPs(active, free),
+		Reservations: active,
+	}
+}
+
+// NetworkUsage rolls up the usage of every subnet in a network
+type NetworkUsage struct {
+	Network   string
+	Total     *big.Int
+	Usable    *big.Int
+	Allocated int
+	Static    int
+	Dynamic   int
+	Expired   int
+	Subnets   []*SubnetUsage
+}
+
+// NewNetworkUsage totals the usage of the subnets of the network
+func NewNetworkUsage(network *Network, subnets []*SubnetUsage) *NetworkUsage {
+	u := &NetworkUsage{Network: network.ID(), Total: new(big.Int), Usable: new(big.Int), Subnets: subnets}
+	for _, s := range subnets {
+		u.Total.Add(u.Total, s.Total)
+		u.Usable.Add(u.Usable, s.Usable)
+		u.Allocated += s.Allocated
+		u.Static += s.Static
+		u.Dynamic += s.Dynamic
+		u.Expired += s.Expired
+	}
+	return u
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNewSubnetUsage(t *testing.T) {
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/29")
+	subnet := &Subnet{Cidr: cidr, Gateway: net.ParseIP("10.0.0.1")}
+
+	now := time.Now()
+	expired := NewDynamicIPReservation(-time.Minute)
+	expired.IP = &net.IPNet{IP: net.ParseIP("10.0.0.2"), Mask: cidr.Mask}
+	static := NewStaticIPReservation()
+	static.IP = &net.IPNet{IP: net.ParseIP("10.0.0.3"), Mask: cidr.Mask}
+
+	usage := NewSubnetUsage(subnet, IPReservationList{expired, static}, now, 10)
+	if usage.Total.Int64() != 8 || usage.Usable.Int64() != 5 || usage.Allocated != 1 || usage.Static != 1 || usage.Dynamic != 0 || usage.Expired != 1 {
+		t.Errorf("got wrong usage: %+v", usage)
+	}
+
+	// expired reservations don't prevent their addresses being allocated
+	if len(usage.Free) != 4 || !usage.Free[0].Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("got wrong free addresses: %v", usage.Free)
+	}
+}
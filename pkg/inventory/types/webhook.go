@@ -0,0 +1,94 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Webhook subscribes a url outside of AWS to inventory change events.  Events
+// are POSTed to URL, signed with Secret.  Kinds, Systems and Tags filter the
+// events delivered: an empty filter matches every event, otherwise the changed
+// object must match one of the values listed.
+type Webhook struct {
+	Name        string
+	URL         string
+	Secret      string
+	Kinds       []string
+	Systems     []string
+	Tags        Tags
+	Metadata    Metadata
+	LastUpdated time.Time
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{}
+}
+
+func (w *Webhook) ID() string {
+	return w.Name
+}
+
+func (w *Webhook) Timestamp() int64 {
+	return w.LastUpdated.Unix()
+}
+
+func (w *Webhook) SetTimestamp(timestamp time.Time) {
+	w.LastUpdated = timestamp
+}
+
+// Version returns the LastUpdated timestamp, which identifies the revision of
+// the webhook that was read
+func (w *Webhook) Version() time.Time {
+	return w.LastUpdated
+}
+
+// Validate returns an error if the webhook can't be delivered to
+func (w *Webhook) Validate() error {
+	if w.Name == "" {
+		return ErrKeyNotSet
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https url")
+	}
+
+	if w.Secret == "" {
+		return fmt.Errorf("webhook secret must be set")
+	}
+	return nil
+}
+
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the webhook subscribes to changes to objects of kind
+// belonging to system and tagged with tags.  Objects match the tag filter if
+// they have any of the tags listed.
+func (w *Webhook) Matches(kind string, system string, tags []string) bool {
+	if len(w.Kinds) > 0 && !matchesAny(w.Kinds, kind) {
+		return false
+	}
+
+	if len(w.Systems) > 0 && !matchesAny(w.Systems, system) {
+		return false
+	}
+
+	if len(w.Tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		if matchesAny(w.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package types
+
+import "testing"
+
+func TestWebhookMatches(t *testing.T) {
+	hook := &Webhook{Kinds: []string{HistoryObjectNode, HistoryObjectIPReservation}, Systems: []string{"tst"}, Tags: Tags{"dhcp", "dns"}}
+
+	cases := []struct {
+		kind     string
+		system   string
+		tags     []string
+		expected bool
+	}{
+		{HistoryObjectNode, "tst", []string{"dns"}, true},
+		{HistoryObjectIPReservation, "tst", []string{"other", "dhcp"}, true},
+		{HistoryObjectNetwork, "tst", []string{"dns"}, false},
+		{HistoryObjectNode, "prd", []string{"dns"}, false},
+		{HistoryObjectNode, "tst", []string{"other"}, false},
+		{HistoryObjectNode, "tst", nil, false},
+	}
+
+	for _, c := range cases {
+		if matched := hook.Matches(c.kind, c.system, c.tags); matched != c.expected {
+			t.Errorf("expected match of %s in %s tagged %v to be %t", c.kind, c.system, c.tags, c.expected)
+		}
+	}
+
+	if !(&Webhook{}).Matches(HistoryObjectNetwork, "", nil) {
+		t.Errorf("expected a webhook without filters to match every change")
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	cases := map[string]struct {
+		hook  *Webhook
+		valid bool
+	}{
+		"valid":       {&Webhook{Name: "dhcp", URL: "https://dhcp.example.com/hook", Secret: "s3cret"}, true},
+		"no name":     {&Webhook{URL: "https://dhcp.example.com/hook", Secret: "s3cret"}, false},
+		"relative":    {&Webhook{Name: "dhcp", URL: "/hook", Secret: "s3cret"}, false},
+		"wrong proto": {&Webhook{Name: "dhcp", URL: "ftp://dhcp.example.com/hook", Secret: "s3cret"}, false},
+		"no secret":   {&Webhook{Name: "dhcp", URL: "https://dhcp.example.com/hook"}, false},
+	}
+
+	for name, c := range cases {
+		if err := c.hook.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected validation result: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// GetSubnetUsage reports the usage of the subnet from its stored reservations,
+// including those that have expired but haven't been reaped, listing up to free
+// of its lowest free addresses
+func GetSubnetUsage(store IPReservationStore, subnet *types.Subnet, free int) (*types.SubnetUsage, error) {
+	reservations, err := store.GetStoredIPReservations(subnet.Cidr)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewSubnetUsage(subnet, reservations, time.Now(), free), nil
+}
+
+// GetNetworkUsage reports the usage of every subnet in the network, along with
+// the totals for the network
+func GetNetworkUsage(store IPReservationStore, network *types.Network, free int) (*types.NetworkUsage, error) {
+	subnets := make([]*types.SubnetUsage, 0, len(network.Subnets))
+	for _, subnet := range network.Subnets {
+		usage, err := GetSubnetUsage(store, subnet, free)
+		if err != nil {
+			return nil, err
+		}
+		subnets = append(subnets, usage)
+	}
+	return types.NewNetworkUsage(network, subnets), nil
+}
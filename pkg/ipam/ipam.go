@@ -37,6 +37,8 @@ func LocationBits(rack string, bottomU uint, sublocation string) (uint64, error)
 	return locationBits, nil
 }
 
+// GetIPByLocation returns the address of the node at the location in subnet.
+// ipv4 addresses are allocated using the DefaultV4LocationScheme.
 func GetIPByLocation(subnet *net.IPNet, rack string, bottomU uint, sublocation string) (net.IP, error) {
 
 	if IsV6(subnet.IP) {
@@ -61,10 +63,13 @@ func GetIPByLocation(subnet *net.IPNet, rack string, bottomU uint, sublocation s
 
 		return newIp, nil
 	} else {
-		return net.IP{}, ErrAllocationNotImplemented
+		return DefaultV4LocationScheme.GetIP(subnet, rack, bottomU, sublocation)
 	}
 }
 
+// GetRangeByLocation returns the range of addresses for the chassis at the
+// location in subnet.  ipv4 ranges are allocated using the
+// DefaultV4LocationScheme.
 func GetRangeByLocation(subnet *net.IPNet, rack string, bottomU uint, sublocation string) (net.IP, net.IP, error) {
 
 	if IsV6(subnet.IP) {
@@ -87,7 +92,7 @@ func GetRangeByLocation(subnet *net.IPNet, rack string, bottomU uint, sublocatio
 
 		return newIp, newIpEnd, nil
 	} else {
-		return net.IP{}, net.IP{}, ErrAllocationNotImplemented
+		return DefaultV4LocationScheme.GetRange(subnet, rack, bottomU)
 	}
 }
 
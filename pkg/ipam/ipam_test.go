@@ -51,6 +51,7 @@ func TestIPv6IPAllocation(t *testing.T) {
 	_, v6cidrA, _ := net.ParseCIDR("2001:db8::/64")
 	_, v6cidrB, _ := net.ParseCIDR("2001:db8::/56")
 	_, v4cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	_, v4cidrLarge, _ := net.ParseCIDR("10.0.0.0/16")
 
 	cases := []*testCase{
 		&testCase{
@@ -75,7 +76,15 @@ func TestIPv6IPAllocation(t *testing.T) {
 			BottomU:         31,
 			ChassisSubIndex: "a",
 			ExpectedIp:      net.IP{},
-			ExpectedErr:     ErrAllocationNotImplemented,
+			ExpectedErr:     ErrSchemeTooLarge,
+		},
+		&testCase{
+			Subnet:          v4cidrLarge,
+			Rack:            "xr20",
+			BottomU:         31,
+			ChassisSubIndex: "a",
+			ExpectedIp:      net.ParseIP("10.0.81.250").To4(),
+			ExpectedErr:     nil,
 		},
 	}
 
@@ -108,6 +117,7 @@ func TestIPv6RangeAllocation(t *testing.T) {
 	_, v6cidrA, _ := net.ParseCIDR("2001:db8::/64")
 	_, v6cidrB, _ := net.ParseCIDR("2001:db8::/56")
 	_, v4cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	_, v4cidrLarge, _ := net.ParseCIDR("10.0.0.0/16")
 
 	cases := []*testCase{
 		&testCase{
@@ -135,7 +145,16 @@ func TestIPv6RangeAllocation(t *testing.T) {
 			ChassisSubIndex: "a",
 			ExpectedStartIp: net.IP{},
 			ExpectedEndIp:   net.IP{},
-			ExpectedErr:     ErrAllocationNotImplemented,
+			ExpectedErr:     ErrSchemeTooLarge,
+		},
+		&testCase{
+			Subnet:          v4cidrLarge,
+			Rack:            "xr20",
+			BottomU:         31,
+			ChassisSubIndex: "a",
+			ExpectedStartIp: net.ParseIP("10.0.81.240").To4(),
+			ExpectedEndIp:   net.ParseIP("10.0.81.255").To4(),
+			ExpectedErr:     nil,
 		},
 	}
 
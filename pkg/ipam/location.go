@@ -0,0 +1,189 @@
+package ipam
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrLocationOutOfRange = errors.New("location doesn't fit in the bits allocated by the location scheme")
+	ErrSchemeTooLarge     = errors.New("location scheme needs more host bits than the subnet has")
+	ErrUnknownRack        = errors.New("rack not listed in the location scheme")
+)
+
+// V4LocationScheme maps the location of a node onto the host bits of an ipv4
+// subnet, so that its address is predictable from its location.  The host
+// part of the address is built from the rack index, bottom U and chassis sub
+// index, in that order from the most significant bit, each taking the number
+// of bits specified, plus Offset.  The rack index is the position of the rack
+// in Racks if it's set, otherwise the number at the end of the rack name, eg:
+// 20 for xr20.
+type V4LocationScheme struct {
+	RackBits     uint
+	UBits        uint
+	SubIndexBits uint
+	Offset       uint64   `json:",omitempty" yaml:",omitempty"`
+	Racks        []string `json:",omitempty" yaml:",omitempty"`
+}
+
+// DefaultV4LocationScheme is used for ipv4 subnets without their own scheme.
+// It fits 64 racks of 64U chassis with up to 16 nodes each, which needs a /16
+// or larger subnet.
+var DefaultV4LocationScheme = &V4LocationScheme{RackBits: 6, UBits: 6, SubIndexBits: 4}
+
+var rackNumber = regexp.MustCompile(`(\d+)$`)
+
+// hostBits returns the number of host bits used by the scheme
+func (s *V4LocationScheme) hostBits() uint {
+	return s.RackBits + s.UBits + s.SubIndexBits
+}
+
+func (s *V4LocationScheme) rackIndex(rack string) (uint64, error) {
+	if len(s.Racks) > 0 {
+		for i, r := range s.Racks {
+			if r == rack {
+				return uint64(i), nil
+			}
+		}
+		return 0, ErrUnknownRack
+	}
+
+	match := rackNumber.FindString(rack)
+	if match == "" {
+		return 0, fmt.Errorf("unable to find rack number in rack name '%s'", rack)
+	}
+	return strconv.ParseUint(match, 10, 32)
+}
+
+// fieldBits returns value, or ErrLocationOutOfRange if it doesn't fit in bits
+func fieldBits(value uint64, bits uint) (uint64, error) {
+	if value >= 1<<bits {
+		return 0, ErrLocationOutOfRange
+	}
+	return value, nil
+}
+
+// locationBits returns the host part of the address for the location.  Every
+// field is checked against its width, so distinct locations never map to the
+// same address.
+func (s *V4LocationScheme) locationBits(rack string, bottomU uint, sublocation string) (uint64, error) {
+	rackIndex, err := s.rackIndex(rack)
+	if err != nil {
+		return 0, err
+	}
+
+	subIndex := uint64(0)
+	if sublocation != "" {
+		subIndex, err = strconv.ParseUint(sublocation, 16, 32)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	fields := []struct {
+		value uint64
+		bits  uint
+	}{{rackIndex, s.RackBits}, {uint64(bottomU), s.UBits}, {subIndex, s.SubIndexBits}}
+
+	host := uint64(0)
+	for _, f := range fields {
+		v, err := fieldBits(f.value, f.bits)
+		if err != nil {
+			return 0, err
+		}
+		host = host<<f.bits | v
+	}
+	return host, nil
+}
+
+// v4Host returns the address in subnet with the host number specified
+func v4Host(subnet *net.IPNet, host uint64) (net.IP, error) {
+	ones, bits := subnet.Mask.Size()
+	if bits != 32 {
+		return net.IP{}, fmt.Errorf("not an ipv4 subnet: %s", subnet)
+	}
+
+	hostBits := uint(bits - ones)
+	if host >= 1<<hostBits {
+		return net.IP{}, ErrSchemeTooLarge
+	}
+
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(subnet.IP.To4())|uint32(host))
+	return ip, nil
+}
+
+// checkSize returns ErrSchemeTooLarge if the scheme doesn't fit in the subnet
+func (s *V4LocationScheme) checkSize(subnet *net.IPNet) error {
+	ones, bits := subnet.Mask.Size()
+	maxHost := uint64(1)<<s.hostBits() - 1 + s.Offset
+	if bits != 32 || uint(bits-ones) > 63 || maxHost >= 1<<uint(bits-ones) {
+		return ErrSchemeTooLarge
+	}
+	return nil
+}
+
+// GetIP returns the address of the node at the location in subnet.  The
+// network and broadcast addresses are never returned, and an error is
+// returned if the address is one of the reserved addresses, so that
+// collisions with existing reservations are detected.
+func (s *V4LocationScheme) GetIP(subnet *net.IPNet, rack string, bottomU uint, sublocation string, reservedAddresses ...net.IP) (net.IP, error) {
+	err := s.checkSize(subnet)
+	if err != nil {
+		return net.IP{}, err
+	}
+
+	host, err := s.locationBits(rack, bottomU, sublocation)
+	if err != nil {
+		return net.IP{}, err
+	}
+	host += s.Offset
+
+	ones, bits := subnet.Mask.Size()
+	if host == 0 || host == 1<<uint(bits-ones)-1 {
+		return net.IP{}, fmt.Errorf("location maps to the network or broadcast address, set an offset in the location scheme")
+	}
+
+	ip, err := v4Host(subnet, host)
+	if err != nil {
+		return net.IP{}, err
+	}
+
+	for _, reserved := range reservedAddresses {
+		if reserved.Equal(ip) {
+			return net.IP{}, fmt.Errorf("ip conflicts with reserved address: %s", reserved)
+		}
+	}
+	return ip, nil
+}
+
+// GetRange returns the first and last addresses of the chassis at the
+// location in subnet, covering every sub index in the chassis
+func (s *V4LocationScheme) GetRange(subnet *net.IPNet, rack string, bottomU uint) (net.IP, net.IP, error) {
+	err := s.checkSize(subnet)
+	if err != nil {
+		return net.IP{}, net.IP{}, err
+	}
+
+	first, err := s.locationBits(rack, bottomU, "")
+	if err != nil {
+		return net.IP{}, net.IP{}, err
+	}
+	first += s.Offset
+	last := first + (1<<s.SubIndexBits - 1)
+
+	start, err := v4Host(subnet, first)
+	if err != nil {
+		return net.IP{}, net.IP{}, err
+	}
+
+	end, err := v4Host(subnet, last)
+	if err != nil {
+		return net.IP{}, net.IP{}, err
+	}
+	return start, end, nil
+}
@@ -0,0 +1,87 @@
+package ipam
+
+import (
+	"net"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestV4LocationSchemeGetIP(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.1.0.0/20")
+	scheme := &V4LocationScheme{RackBits: 3, UBits: 6, SubIndexBits: 2, Offset: 16, Racks: []string{"aa01", "aa02"}}
+
+	ip, err := scheme.GetIP(subnet, "aa02", 10, "3")
+	if err != nil {
+		t.Fatalf("unable to get ip: %v", err)
+	}
+	// (1<<8 | 10<<2 | 3) + 16 = 315
+	if diff := deep.Equal(ip, net.ParseIP("10.1.1.59").To4()); len(diff) > 0 {
+		t.Errorf("got incorrect ip: %v", diff)
+	}
+
+	_, err = scheme.GetIP(subnet, "aa02", 10, "3", net.ParseIP("10.1.1.59"))
+	if err == nil {
+		t.Errorf("expected a collision with the reserved address")
+	}
+
+	_, err = scheme.GetIP(subnet, "aa03", 10, "3")
+	if err != ErrUnknownRack {
+		t.Errorf("expected unknown rack error, got %v", err)
+	}
+
+	_, err = scheme.GetIP(subnet, "aa01", 10, "4")
+	if err != ErrLocationOutOfRange {
+		t.Errorf("expected sub index to be out of range, got %v", err)
+	}
+
+	_, err = scheme.GetIP(subnet, "aa01", 64, "")
+	if err != ErrLocationOutOfRange {
+		t.Errorf("expected bottom u to be out of range, got %v", err)
+	}
+
+	_, small, _ := net.ParseCIDR("10.1.0.0/22")
+	_, err = scheme.GetIP(small, "aa01", 10, "")
+	if err != ErrSchemeTooLarge {
+		t.Errorf("expected scheme not to fit in subnet, got %v", err)
+	}
+}
+
+func TestV4LocationSchemeGetRange(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.1.0.0/20")
+	for offset, expected := range map[uint64][]string{
+		// (1<<8 | 10<<2) + 16 = 312, the last sub index adds 3
+		16: {"10.1.1.56", "10.1.1.59"},
+		// with an offset that isn't aligned to the sub index bits, 297 to 300
+		1: {"10.1.1.41", "10.1.1.44"},
+	} {
+		scheme := &V4LocationScheme{RackBits: 3, UBits: 6, SubIndexBits: 2, Offset: offset, Racks: []string{"aa01", "aa02"}}
+		first, last, err := scheme.GetRange(subnet, "aa02", 10)
+		if err != nil {
+			t.Fatalf("unable to get range with offset %d: %v", offset, err)
+		}
+
+		if diff := deep.Equal([]net.IP{first, last}, []net.IP{net.ParseIP(expected[0]).To4(), net.ParseIP(expected[1]).To4()}); len(diff) > 0 {
+			t.Errorf("got incorrect range with offset %d: %v", offset, diff)
+		}
+
+		ip, err := scheme.GetIP(subnet, "aa02", 10, "3")
+		if err != nil || !ip.Equal(last) {
+			t.Errorf("expected the last sub index to be at the end of the range with offset %d, got %s: %v", offset, ip, err)
+		}
+	}
+}
+
+func TestV4LocationSchemeNetworkAddress(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.1.0.0/16")
+
+	_, err := DefaultV4LocationScheme.GetIP(subnet, "xr00", 0, "")
+	if err == nil {
+		t.Errorf("expected an error for a location that maps to the network address")
+	}
+
+	_, err = DefaultV4LocationScheme.GetIP(subnet, "xr", 0, "")
+	if err == nil {
+		t.Errorf("expected an error for a rack without a number")
+	}
+}
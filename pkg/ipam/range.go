@@ -0,0 +1,159 @@
+package ipam
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net"
+	"sort"
+)
+
+// IPRange is an inclusive range of addresses.  A range without an End holds
+// only its Start address.
+type IPRange struct {
+	Start net.IP
+	End   net.IP `json:",omitempty" yaml:",omitempty" dynamodbav:",omitempty"`
+}
+
+// Last returns the last address in the range
+func (r IPRange) Last() net.IP {
+	if r.End == nil {
+		return r.Start
+	}
+	return r.End
+}
+
+// Contains returns true if ip is in the range
+func (r IPRange) Contains(ip net.IP) bool {
+	return CompareIP(r.Start, ip) <= 0 && CompareIP(ip, r.Last()) <= 0
+}
+
+// Validate checks that the range is ordered and lies within subnet
+func (r IPRange) Validate(subnet *net.IPNet) error {
+	if r.Start == nil {
+		return fmt.Errorf("range has no start address")
+	}
+
+	if !subnet.Contains(r.Start) || !subnet.Contains(r.Last()) {
+		return fmt.Errorf("range %s isn't within %s", r, subnet)
+	}
+
+	if CompareIP(r.Start, r.Last()) > 0 {
+		return fmt.Errorf("range %s ends before it starts", r)
+	}
+	return nil
+}
+
+func (r IPRange) String() string {
+	if r.End == nil {
+		return r.Start.String()
+	}
+	return fmt.Sprintf("%s-%s", r.Start, r.End)
+}
+
+// CompareIP compares two addresses numerically, returning -1, 0 or 1 if a is
+// less than, equal to or greater than b.  ipv4 addresses compare equal to
+// their ipv4 mapped ipv6 form.
+func CompareIP(a, b net.IP) int {
+	return bytes.Compare(a.To16(), b.To16())
+}
+
+func ipInt(ip net.IP) *big.Int {
+	if v4 := ip.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// intIP converts i back into an address the same length as template
+func intIP(i *big.Int, template net.IP) net.IP {
+	length := net.IPv6len
+	if template.To4() != nil {
+		length = net.IPv4len
+	}
+
+	b := i.Bytes()
+	if len(b) > length {
+		// wrap around past the last address
+		b = b[len(b)-length:]
+	}
+	ip := make(net.IP, length)
+	copy(ip[length-len(b):], b)
+	return ip
+}
+
+// NextIP returns the address following ip
+func NextIP(ip net.IP) net.IP {
+	return intIP(new(big.Int).Add(ipInt(ip), big.NewInt(1)), ip)
+}
+
+// RangeSize returns the number of addresses in the range
+func (r IPRange) RangeSize() *big.Int {
+	size := new(big.Int).Sub(ipInt(r.Last()), ipInt(r.Start))
+	return size.Add(size, big.NewInt(1))
+}
+
+// RandomIP returns a random address from the range using rnd
+func (r IPRange) RandomIP(rnd *rand.Rand) net.IP {
+	offset := new(big.Int).Rand(rnd, r.RangeSize())
+	return intIP(offset.Add(offset, ipInt(r.Start)), r.Start)
+}
+
+// HostRange returns the range of host addresses in subnet.  The network and
+// broadcast addresses of ipv4 subnets aren't included, nor is the all zeros
+// address of ipv6 subnets.
+func HostRange(subnet *net.IPNet) IPRange {
+	network := subnet.IP.Mask(subnet.Mask)
+	last := make(net.IP, len(network))
+	for i := range network {
+		last[i] = network[i] | ^subnet.Mask[i]
+	}
+
+	end := last
+	if !IsV6(network) {
+		end = intIP(new(big.Int).Sub(ipInt(last), big.NewInt(1)), network)
+	}
+	return IPRange{Start: NextIP(network), End: end}
+}
+
+// Intersect returns the addresses in both ranges, and false if the ranges
+// don't overlap
+func (r IPRange) Intersect(o IPRange) (IPRange, bool) {
+	start, end := r.Start, r.Last()
+	if CompareIP(o.Start, start) > 0 {
+		start = o.Start
+	}
+	if CompareIP(o.Last(), end) < 0 {
+		end = o.Last()
+	}
+
+	if CompareIP(start, end) > 0 {
+		return IPRange{}, false
+	}
+	return IPRange{Start: start, End: end}, true
+}
+
+// MergeRanges returns the ranges sorted by start address, with overlapping
+// and adjacent ranges merged
+func MergeRanges(ranges []IPRange) []IPRange {
+	sorted := make([]IPRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start != nil && CompareIP(r.Start, r.Last()) <= 0 {
+			sorted = append(sorted, IPRange{Start: r.Start, End: r.Last()})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return CompareIP(sorted[i].Start, sorted[j].Start) < 0 })
+
+	merged := make([]IPRange, 0, len(sorted))
+	for _, r := range sorted {
+		if n := len(merged); n > 0 && CompareIP(r.Start, NextIP(merged[n-1].End)) <= 0 {
+			if CompareIP(r.End, merged[n-1].End) > 0 {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
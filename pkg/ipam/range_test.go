@@ -0,0 +1,97 @@
+package ipam
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func TestHostRange(t *testing.T) {
+	cases := map[string]IPRange{
+		"10.0.0.0/24":   {Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.254")},
+		"10.0.0.0/31":   {Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.0")},
+		"2001:db8::/64": {Start: net.ParseIP("2001:db8::1"), End: net.ParseIP("2001:db8::ffff:ffff:ffff:ffff")},
+	}
+
+	for cidr, expected := range cases {
+		_, subnet, _ := net.ParseCIDR(cidr)
+		r := HostRange(subnet)
+		if CompareIP(r.Start, expected.Start) != 0 || CompareIP(r.End, expected.End) != 0 {
+			t.Errorf("wrong host range for %s: expected %s, got %s", cidr, expected, r)
+		}
+	}
+}
+
+func TestIPRange(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	r := IPRange{Start: net.ParseIP("10.0.0.10"), End: net.ParseIP("10.0.0.20")}
+
+	if !r.Contains(net.ParseIP("10.0.0.20")) || r.Contains(net.ParseIP("10.0.0.21")) || r.Contains(net.ParseIP("10.0.0.9")) {
+		t.Errorf("range %s contains the wrong addresses", r)
+	}
+
+	if size := r.RangeSize().Int64(); size != 11 {
+		t.Errorf("expected range of 11 addresses, got %d", size)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if ip := r.RandomIP(rnd); !r.Contains(ip) {
+			t.Fatalf("random address %s isn't in range %s", ip, r)
+		}
+	}
+
+	if ip := NextIP(net.ParseIP("10.0.0.255").To4()); !ip.Equal(net.ParseIP("10.0.1.0")) {
+		t.Errorf("got wrong next address: %s", ip)
+	}
+
+	single := IPRange{Start: net.ParseIP("10.0.0.5")}
+	if !single.Contains(net.ParseIP("10.0.0.5")) || single.Contains(net.ParseIP("10.0.0.6")) {
+		t.Errorf("range %s contains the wrong addresses", single)
+	}
+
+	if err := r.Validate(subnet); err != nil {
+		t.Errorf("expected range to be valid: %v", err)
+	}
+
+	invalid := []IPRange{
+		{Start: net.ParseIP("10.0.0.20"), End: net.ParseIP("10.0.0.10")},
+		{Start: net.ParseIP("10.0.0.20"), End: net.ParseIP("10.0.1.10")},
+		{End: net.ParseIP("10.0.0.10")},
+	}
+	for _, i := range invalid {
+		if err := i.Validate(subnet); err == nil {
+			t.Errorf("expected range %s to be invalid", i)
+		}
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	ranges := []IPRange{
+		{Start: net.ParseIP("10.0.0.20"), End: net.ParseIP("10.0.0.30")},
+		{Start: net.ParseIP("10.0.0.1")},
+		{Start: net.ParseIP("10.0.0.25"), End: net.ParseIP("10.0.0.40")},
+		{Start: net.ParseIP("10.0.0.2"), End: net.ParseIP("10.0.0.5")},
+		{Start: net.ParseIP("255.255.255.255")},
+	}
+
+	merged := MergeRanges(ranges)
+	expected := []string{"10.0.0.1-10.0.0.5", "10.0.0.20-10.0.0.40", "255.255.255.255-255.255.255.255"}
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, merged)
+	}
+
+	for i, e := range expected {
+		if merged[i].String() != e {
+			t.Errorf("expected %v, got %v", expected, merged)
+		}
+	}
+
+	if r, ok := merged[0].Intersect(IPRange{Start: net.ParseIP("10.0.0.4"), End: net.ParseIP("10.0.0.30")}); !ok || r.String() != "10.0.0.4-10.0.0.5" {
+		t.Errorf("got wrong intersection: %s", r)
+	}
+
+	if _, ok := merged[0].Intersect(merged[1]); ok {
+		t.Errorf("expected ranges not to intersect")
+	}
+}
@@ -0,0 +1,424 @@
+// Package jsonpatch applies RFC 7396 JSON merge patches and RFC 6902 JSON
+// patches to json documents.  Numbers are decoded as json.Number so that large
+// integers in patched documents aren't rounded.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrTestFailed is returned when a test operation doesn't match the document
+	ErrTestFailed = errors.New("test operation failed")
+
+	// ErrPathNotFound is returned when an operation refers to a location that
+	// doesn't exist in the document
+	ErrPathNotFound = errors.New("path not found")
+)
+
+// OperationError is returned by Apply when an operation can't be applied
+type OperationError struct {
+	Index int
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// MergePatch applies an RFC 7396 merge patch to doc.  Members of patch
+// objects replace the members of the document, except that null removes the
+// member and objects are merged recursively.
+func MergePatch(doc []byte, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %v", err)
+	}
+
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+func merge(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = merge(t[key], value)
+	}
+	return t
+}
+
+// operation is a single step of an RFC 6902 patch
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (o operation) value() (interface{}, error) {
+	if len(o.Value) == 0 {
+		return nil, fmt.Errorf("%s operation requires a value", o.Op)
+	}
+	return decode(o.Value)
+}
+
+// Apply applies an RFC 6902 patch, a list of add, remove, replace, move, copy
+// and test operations, to doc.  The patch is applied in full or not at all.
+func Apply(doc []byte, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %v", err)
+	}
+
+	operations := []operation{}
+	err = json.Unmarshal(patch, &operations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json patch: %v", err)
+	}
+
+	for i, op := range operations {
+		target, err = apply(target, op)
+		if err != nil {
+			return nil, &OperationError{Index: i, Err: err}
+		}
+	}
+	return json.Marshal(target)
+}
+
+func apply(doc interface{}, op operation) (interface{}, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%s operation requires a path", op.Op)
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		doc, _, err = remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%s operation requires from", op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("unable to move %s into one of its children", *op.From)
+			}
+			doc, value, err = remove(doc, from)
+		} else {
+			value, err = get(doc, from)
+			value = deepCopy(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	}
+	return nil, fmt.Errorf("unsupported operation: %s", op.Op)
+}
+
+// parsePointer splits an RFC 6901 json pointer into its reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer: %s", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// index parses an array index token, which must be less than max
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index: %s", token)
+	}
+	if i >= max {
+		return 0, ErrPathNotFound
+	}
+	return i, nil
+}
+
+func child(doc interface{}, token string) (interface{}, error) {
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		value, ok := c[token]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		return value, nil
+	case []interface{}:
+		i, err := index(token, len(c))
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+	}
+	return nil, ErrPathNotFound
+}
+
+// setChild replaces an existing member of doc
+func setChild(doc interface{}, token string, value interface{}) (interface{}, error) {
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		c[token] = value
+		return c, nil
+	case []interface{}:
+		i, err := index(token, len(c))
+		if err != nil {
+			return nil, err
+		}
+		c[i] = value
+		return c, nil
+	}
+	return nil, ErrPathNotFound
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		var err error
+		doc, err = child(doc, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// add inserts value at path, returning the updated document.  Arrays may be
+// reallocated, so the updated value is stored back into each parent.
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	if len(path) > 1 {
+		c, err := child(doc, token)
+		if err != nil {
+			return nil, err
+		}
+		c, err = add(c, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return setChild(doc, token, c)
+	}
+
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		c[token] = value
+		return c, nil
+	case []interface{}:
+		if token == "-" {
+			return append(c, value), nil
+		}
+		i, err := index(token, len(c)+1)
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, nil)
+		copy(c[i+1:], c[i:])
+		c[i] = value
+		return c, nil
+	}
+	return nil, ErrPathNotFound
+}
+
+// remove deletes the value at path, returning the updated document and the
+// removed value
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("unable to remove the whole document")
+	}
+
+	token := path[0]
+	if len(path) > 1 {
+		c, err := child(doc, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		c, removed, err := remove(c, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		doc, err = setChild(doc, token, c)
+		return doc, removed, err
+	}
+
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		removed, ok := c[token]
+		if !ok {
+			return nil, nil, ErrPathNotFound
+		}
+		delete(c, token)
+		return c, removed, nil
+	case []interface{}:
+		i, err := index(token, len(c))
+		if err != nil {
+			return nil, nil, err
+		}
+		removed := c[i]
+		return append(c[:i], c[i+1:]...), removed, nil
+	}
+	return nil, nil, ErrPathNotFound
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			c[key] = deepCopy(member)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, element := range v {
+			c[i] = deepCopy(element)
+		}
+		return c
+	}
+	return value
+}
+
+// equal compares decoded json values, treating numbers as equal if they have
+// the same value
+func equal(a interface{}, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, member := range av {
+			other, ok := bv[key]
+			if !ok || !equal(member, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if av == bv {
+			return true
+		}
+		af, aerr := av.Float64()
+		bf, berr := bv.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+	return a == b
+}
@@ -0,0 +1,118 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func assertJSONEqual(t *testing.T, name string, actual []byte, expected string) {
+	var a, e interface{}
+	err := json.Unmarshal(actual, &a)
+	if err != nil {
+		t.Errorf("%s: unable to unmarshal result: %v", name, err)
+		return
+	}
+	err = json.Unmarshal([]byte(expected), &e)
+	if err != nil {
+		t.Fatalf("%s: unable to unmarshal expected result: %v", name, err)
+	}
+	for _, d := range deep.Equal(a, e) {
+		t.Errorf("%s: %s", name, d)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Doc      string
+		Patch    string
+		Expected string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{"merge nested objects", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"f","d":null}}`, `{"a":{"b":"f"}}`},
+		{"nulls removed from new objects", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"non-object patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"large integers preserved", `{"a":1}`, `{"b":9007199254740993}`, `{"a":1,"b":9007199254740993}`},
+	}
+
+	for _, c := range cases {
+		result, err := MergePatch([]byte(c.Doc), []byte(c.Patch))
+		if err != nil {
+			t.Errorf("%s: unable to apply patch: %v", c.Name, err)
+			continue
+		}
+		assertJSONEqual(t, c.Name, result, c.Expected)
+	}
+
+	if result, _ := MergePatch([]byte(`{"a":1}`), []byte(`{"b":9007199254740993}`)); string(result) != `{"a":1,"b":9007199254740993}` {
+		t.Errorf("large integer was rounded: %s", result)
+	}
+
+	_, err := MergePatch([]byte(`{}`), []byte(`{`))
+	if err == nil {
+		t.Errorf("expected error for invalid patch")
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Doc      string
+		Patch    string
+		Expected string
+		Err      error
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, nil},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		{"append array element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`, nil},
+		{"add null value", `{}`, `[{"op":"add","path":"/foo","value":null}]`, `{"foo":null}`, nil},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+		{"replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+		{"replace document", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`, nil},
+		{"move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, nil},
+		{"copy value", `{"foo":{"bar":"baz"}}`, `[{"op":"copy","from":"/foo","path":"/qux"},{"op":"add","path":"/qux/bar","value":"changed"}]`, `{"foo":{"bar":"baz"},"qux":{"bar":"changed"}}`, nil},
+		{"escaped pointer", `{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`, nil},
+		{"test value", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		{"failed test", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+		{"remove missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, "", ErrPathNotFound},
+		{"add to missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", ErrPathNotFound},
+		{"array index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`, "", ErrPathNotFound},
+	}
+
+	for _, c := range cases {
+		result, err := Apply([]byte(c.Doc), []byte(c.Patch))
+		if c.Err != nil {
+			if opErr, ok := err.(*OperationError); !ok || opErr.Err != c.Err {
+				t.Errorf("%s: expected error %v, got: %v", c.Name, c.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unable to apply patch: %v", c.Name, err)
+			continue
+		}
+		assertJSONEqual(t, c.Name, result, c.Expected)
+	}
+
+	invalid := []string{
+		`{"op":"add"}`,
+		`[{"op":"add","path":"/foo"}]`,
+		`[{"op":"bad","path":"/foo","value":1}]`,
+		`[{"op":"add","path":"foo","value":1}]`,
+		`[{"op":"move","path":"/foo"}]`,
+		`[{"op":"move","from":"/foo","path":"/foo/bar"}]`,
+	}
+	for _, patch := range invalid {
+		_, err := Apply([]byte(`{"foo":{}}`), []byte(patch))
+		if err == nil {
+			t.Errorf("expected error for invalid patch %s", patch)
+		}
+	}
+}
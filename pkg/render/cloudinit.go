@@ -0,0 +1,168 @@
+package render
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// UserDataMetadataKey is the environment metadata key holding user-data
+// templates.  Its value is either a single template used for every role, or
+// a map of role to template, where the template for DefaultUserDataRole is
+// used for roles without one.
+const UserDataMetadataKey = "cloud-init-user-data"
+
+// DefaultUserDataRole selects the user-data template used for roles without
+// their own
+const DefaultUserDataRole = "default"
+
+type nameservers struct {
+	Addresses []string `yaml:"addresses,omitempty"`
+	Search    []string `yaml:"search,omitempty"`
+}
+
+type ethernet struct {
+	Match       map[string]string `yaml:"match"`
+	MTU         uint              `yaml:"mtu,omitempty"`
+	DHCP4       bool              `yaml:"dhcp4"`
+	Addresses   []string          `yaml:"addresses,omitempty"`
+	Gateway4    string            `yaml:"gateway4,omitempty"`
+	Gateway6    string            `yaml:"gateway6,omitempty"`
+	Nameservers *nameservers      `yaml:"nameservers,omitempty"`
+}
+
+type networkConfig struct {
+	Version   int                  `yaml:"version"`
+	Ethernets map[string]*ethernet `yaml:"ethernets"`
+}
+
+// NetworkConfig renders a cloud-init network-config (version 2) document for
+// the node.  Each logical network is configured on the node's first interface
+// on it, matched by mac and named after the network.  Any other interfaces
+// on the network are matched as <network>-<n>, without addresses.
+func NetworkConfig(node *types.InventoryNode) ([]byte, error) {
+	config := &networkConfig{Version: 2, Ethernets: make(map[string]*ethernet)}
+	params := NewParams(node, nil)
+
+	for _, name := range networkNames(node) {
+		n := params.Networks[name]
+		for i, mac := range n.MAC {
+			e := &ethernet{Match: map[string]string{"macaddress": mac}, MTU: n.MTU}
+			if i > 0 {
+				config.Ethernets[fmt.Sprintf("%s-%d", name, i)] = e
+				continue
+			}
+
+			e.Addresses = n.IP
+			for _, gateway := range n.Gateway {
+				ip := net.ParseIP(gateway)
+				switch {
+				case ip == nil:
+				case ip.To4() != nil && e.Gateway4 == "":
+					e.Gateway4 = gateway
+				case ip.To4() == nil && e.Gateway6 == "":
+					e.Gateway6 = gateway
+				}
+			}
+
+			if len(n.DNS) > 0 || n.Domain != "" {
+				e.Nameservers = &nameservers{Addresses: unique(n.DNS)}
+				if n.Domain != "" {
+					e.Nameservers.Search = []string{n.Domain}
+				}
+			}
+			config.Ethernets[name] = e
+		}
+	}
+	return yaml.Marshal(config)
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	u := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+type metaData struct {
+	InstanceID    string   `yaml:"instance-id"`
+	LocalHostname string   `yaml:"local-hostname"`
+	InventoryID   string   `yaml:"inventory-id,omitempty"`
+	Location      string   `yaml:"location,omitempty"`
+	Role          string   `yaml:"role,omitempty"`
+	System        string   `yaml:"system,omitempty"`
+	Tags          []string `yaml:"tags,omitempty"`
+}
+
+// MetaData renders a cloud-init NoCloud meta-data document for the node.  The
+// instance id is the node's id, so cloud-init only runs once per node.
+func MetaData(node *types.InventoryNode) ([]byte, error) {
+	params := NewParams(node, nil)
+	return yaml.Marshal(&metaData{
+		InstanceID:    node.ID(),
+		LocalHostname: params.Hostname,
+		InventoryID:   params.InventoryID,
+		Location:      params.Location,
+		Role:          params.Role,
+		System:        params.System,
+		Tags:          params.Tags,
+	})
+}
+
+// defaultUserData is rendered for nodes without a user-data template
+const defaultUserData = `#cloud-config
+hostname: {{.Hostname}}
+fqdn: {{.FQDN}}
+`
+
+// userDataTemplate selects the user-data template for the node from its
+// environment's metadata
+func userDataTemplate(node *types.InventoryNode) (string, error) {
+	if node.Environment == nil {
+		return defaultUserData, nil
+	}
+
+	switch templates := node.Environment.Metadata[UserDataMetadataKey].(type) {
+	case nil:
+		return defaultUserData, nil
+	case string:
+		return templates, nil
+	case map[string]interface{}:
+		for _, role := range []string{node.Role, DefaultUserDataRole} {
+			if tmpl, ok := templates[role]; ok {
+				s, ok := tmpl.(string)
+				if !ok {
+					return "", fmt.Errorf("user-data template for %s isn't a string", role)
+				}
+				return s, nil
+			}
+		}
+		return defaultUserData, nil
+	default:
+		return "", fmt.Errorf("%s must be a template or a map of role to template", UserDataMetadataKey)
+	}
+}
+
+// UserData renders the cloud-init user-data for the node from the template
+// selected by its role from the environment's UserDataMetadataKey metadata.
+// The template is executed with the node's Params.  Nodes without a template
+// get a cloud-config that only sets the hostname.
+func UserData(node *types.InventoryNode) ([]byte, error) {
+	tmpl, err := userDataTemplate(node)
+	if err != nil {
+		return nil, err
+	}
+
+	userData, err := NewParams(node, nil).Expand(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render user-data: %v", err)
+	}
+	return []byte(userData), nil
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"net"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestNetworkConfig(t *testing.T) {
+	node := testInventoryNode()
+	provisioning := node.Networks["provisioning"]
+	provisioning.Network = types.Network{Name: "provisioning", MTU: 9000, Domain: "tst.example.com"}
+	second, _ := net.ParseMAC("00:01:02:03:04:06")
+	provisioning.Interface.NICs = append(provisioning.Interface.NICs, second)
+
+	config, err := NetworkConfig(node)
+	if err != nil {
+		t.Fatalf("unable to render network config: %v", err)
+	}
+
+	expected := `version: 2
+ethernets:
+  provisioning:
+    match:
+      macaddress: "00:01:02:03:04:05"
+    mtu: 9000
+    dhcp4: false
+    addresses:
+    - 10.0.0.10/24
+    gateway4: 10.0.0.1
+    nameservers:
+      addresses:
+      - 10.0.0.2
+      - 10.0.0.3
+      search:
+      - tst.example.com
+  provisioning-1:
+    match:
+      macaddress: "00:01:02:03:04:06"
+    mtu: 9000
+    dhcp4: false
+`
+	if string(config) != expected {
+		t.Errorf("unexpected network config:\n%s", config)
+	}
+}
+
+func TestMetaData(t *testing.T) {
+	metaData, err := MetaData(testInventoryNode())
+	if err != nil {
+		t.Fatalf("unable to render meta-data: %v", err)
+	}
+
+	expected := `instance-id: node0001
+local-hostname: tst-worker-001
+inventory-id: node0001
+location: xr20-31
+role: worker
+system: tst
+tags:
+- dhcp
+`
+	if string(metaData) != expected {
+		t.Errorf("unexpected meta-data:\n%s", metaData)
+	}
+}
+
+func TestUserData(t *testing.T) {
+	node := testInventoryNode()
+	node.Networks["provisioning"].Network.Domain = "tst.example.com"
+
+	cases := map[string]struct {
+		templates interface{}
+		expected  string
+	}{
+		"no template":   {nil, "#cloud-config\nhostname: tst-worker-001\nfqdn: tst-worker-001.tst.example.com\n"},
+		"single":        {"#cloud-config\nfqdn: {{.FQDN}}\n", "#cloud-config\nfqdn: tst-worker-001.tst.example.com\n"},
+		"by role":       {map[string]interface{}{"worker": "role: {{.Role}}", "default": "default"}, "role: worker"},
+		"default role":  {map[string]interface{}{"master": "master", "default": "{{.Metadata.console}}"}, "ttyS1\nshell"},
+		"no role match": {map[string]interface{}{"master": "master"}, "#cloud-config\nhostname: tst-worker-001\nfqdn: tst-worker-001.tst.example.com\n"},
+	}
+
+	for name, c := range cases {
+		node.Environment.Metadata = types.Metadata{UserDataMetadataKey: c.templates}
+		userData, err := UserData(node)
+		if err != nil {
+			t.Errorf("%s: unable to render user-data: %v", name, err)
+			continue
+		}
+
+		if string(userData) != c.expected {
+			t.Errorf("%s: unexpected user-data:\n%s", name, userData)
+		}
+	}
+
+	node.Environment.Metadata = types.Metadata{UserDataMetadataKey: 42}
+	_, err := UserData(node)
+	if err == nil {
+		t.Errorf("expected invalid templates to be rejected")
+	}
+}
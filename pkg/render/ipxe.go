@@ -0,0 +1,174 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// ErrNoIPXEUrl is returned when rendering a boot script for a node whose
+// environment doesn't have an IPXEUrl
+var ErrNoIPXEUrl = errors.New("node environment has no ipxe url")
+
+var invalidSettingChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// settingName converts name into a valid ipxe setting name
+func settingName(parts ...string) string {
+	return invalidSettingChars.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+// unsafeChars matches the characters that ipxe acts on when it parses a
+// command: whitespace separates arguments, so that a value could add || or &&
+// to chain another command, ${ expands settings, and quotes and backslashes
+// change how arguments are split.  Control characters would end the command.
+var unsafeChars = regexp.MustCompile(`[\s\x00-\x1f\x7f$|&"'\\]`)
+
+// safeValue returns true if value can be used as a literal ipxe argument
+func safeValue(value string) bool {
+	return !unsafeChars.MatchString(value)
+}
+
+// urlValue percent-encodes the characters of url that ipxe would act on, other
+// than &, which separates query parameters and can't chain a command without
+// whitespace around it
+func urlValue(url string) string {
+	return unsafeChars.ReplaceAllStringFunc(url, func(c string) string {
+		if c == "&" {
+			return c
+		}
+
+		encoded := ""
+		for _, b := range []byte(c) {
+			encoded += fmt.Sprintf("%%%02X", b)
+		}
+		return encoded
+	})
+}
+
+// messageValue strips the characters that would end the command or expand
+// settings from a message for echo, keeping its spaces
+func messageValue(message string) string {
+	return strings.Map(func(r rune) rune {
+		if r != ' ' && unsafeChars.MatchString(string(r)) {
+			return -1
+		}
+		return r
+	}, message)
+}
+
+type ipxeScript struct {
+	strings.Builder
+}
+
+func newIPXEScript() *ipxeScript {
+	s := &ipxeScript{}
+	s.WriteString("#!ipxe\n")
+	return s
+}
+
+func (s *ipxeScript) command(format string, args ...interface{}) {
+	fmt.Fprintf(s, format+"\n", args...)
+}
+
+// set sets an ipxe setting, skipping empty values and values that ipxe would
+// act on rather than set
+func (s *ipxeScript) set(name string, value string) {
+	if value != "" && safeValue(value) {
+		s.command("set %s %s", name, value)
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// IPXEScript renders an ipxe script that chains to the IPXEUrl of the node's
+// environment.  The url is expanded as a template with the node's Params
+// first, so it may include values such as {{.Hostname}} or {{.Role}}.  The
+// parameters are also set as ipxe settings before chaining, for use by the
+// chained script: hostname, inventory_id, location, role, system, net_<name>_ip,
+// net_<name>_gateway, net_<name>_dns for the first address on each logical
+// network, and meta_<key> for each of metadataKeys whose metadata value is a
+// string, number or boolean.  The script is served without authentication, so
+// only the metadata keys listed are included.  Settings whose values contain
+// whitespace or characters that ipxe expands or chains commands on are left
+// out, and those characters are percent-encoded in the url.
+func IPXEScript(node *types.InventoryNode, mac net.HardwareAddr, metadataKeys []string) (string, error) {
+	if node.Environment == nil || node.Environment.IPXEUrl == "" {
+		return "", ErrNoIPXEUrl
+	}
+
+	params := NewParams(node, mac)
+	url, err := params.Expand(node.Environment.IPXEUrl)
+	if err != nil {
+		return "", fmt.Errorf("unable to expand ipxe url: %v", err)
+	}
+
+	s := newIPXEScript()
+	s.set("hostname", params.Hostname)
+	s.set("inventory_id", params.InventoryID)
+	s.set("location", params.Location)
+	s.set("role", params.Role)
+	s.set("system", params.System)
+
+	for _, name := range networkNames(node) {
+		n := params.Networks[name]
+		s.set(settingName("net", name, "ip"), first(n.IP))
+		s.set(settingName("net", name, "gateway"), first(n.Gateway))
+		s.set(settingName("net", name, "dns"), first(n.DNS))
+	}
+
+	keys := append([]string{}, metadataKeys...)
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch v := params.Metadata[key].(type) {
+		case string, bool, int, int64, float64:
+			s.set(settingName("meta", key), fmt.Sprint(v))
+		}
+	}
+
+	s.command("chain %s", urlValue(url))
+	return s.String(), nil
+}
+
+// DiscoveryIPXEScript renders the ipxe script served to macs that aren't in
+// the inventory.  If discoveryURL is set, the script chains to it so that the
+// node can be registered, the url may include {{.MAC}}.  Otherwise the script
+// reports that the node is unknown and exits, so that the firmware moves on to
+// the next boot device.
+func DiscoveryIPXEScript(mac net.HardwareAddr, discoveryURL string) (string, error) {
+	message := fmt.Sprintf("No inventory record found for %s", mac)
+	if discoveryURL == "" {
+		return ExitIPXEScript(message), nil
+	}
+
+	s := newIPXEScript()
+	s.command("echo %s", messageValue(message))
+
+	url, err := (&Params{MAC: mac.String()}).Expand(discoveryURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to expand discovery url: %v", err)
+	}
+
+	s.set("mac_address", mac.String())
+	s.command("chain %s", urlValue(url))
+	return s.String(), nil
+}
+
+// ExitIPXEScript renders an ipxe script that prints message and exits, so that
+// the firmware moves on to the next boot device
+func ExitIPXEScript(message string) string {
+	s := newIPXEScript()
+	s.command("echo %s", messageValue(message))
+	s.command("exit")
+	return s.String()
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+	"github.com/go-test/deep"
+)
+
+func testInventoryNode() *types.InventoryNode {
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	return &types.InventoryNode{
+		Hostname:       "tst-worker-001",
+		InventoryID:    "node0001",
+		LocationString: "xr20-31",
+		Role:           "worker",
+		Tags:           []string{"dhcp"},
+		System:         &types.System{Name: "test", ShortName: "tst"},
+		Environment: &types.Environment{
+			IPXEUrl:  "http://boot.example.com/{{.System}}/{{.Role}}.ipxe?host={{.Hostname | urlquery}}",
+			Metadata: types.Metadata{"console": "ttyS0", "debug": false},
+		},
+		Networks: map[string]*types.NICInstance{
+			"provisioning": {
+				Interface: types.NetworkInterface{NICs: []net.HardwareAddr{mac}},
+				Config:    types.NicConfig{IP: []string{"10.0.0.10/24"}, Gateway: []string{"10.0.0.1"}, DNS: []string{"10.0.0.2", "10.0.0.3"}},
+			},
+		},
+		Metadata: types.Metadata{"console": "ttyS1\nshell", "rack": map[string]interface{}{"row": 2}},
+	}
+}
+
+func TestIPXEScript(t *testing.T) {
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	script, err := IPXEScript(testInventoryNode(), mac, []string{"debug", "console", "rack", "missing"})
+	if err != nil {
+		t.Fatalf("unable to render script: %v", err)
+	}
+
+	expected := `#!ipxe
+set hostname tst-worker-001
+set inventory_id node0001
+set location xr20-31
+set role worker
+set system tst
+set net_provisioning_ip 10.0.0.10/24
+set net_provisioning_gateway 10.0.0.1
+set net_provisioning_dns 10.0.0.2
+set meta_debug false
+chain http://boot.example.com/tst/worker.ipxe?host=tst-worker-001
+`
+	if diff := deep.Equal(script, expected); len(diff) > 0 {
+		t.Errorf("rendered script doesn't match: %v\n%s", diff, script)
+	}
+
+	script, err = IPXEScript(testInventoryNode(), mac, nil)
+	if err != nil || strings.Contains(script, "set meta_") {
+		t.Errorf("expected no metadata without an allow list, got %v:\n%s", err, script)
+	}
+
+	node := testInventoryNode()
+	node.Environment.IPXEUrl = ""
+	_, err = IPXEScript(node, mac, nil)
+	if err != ErrNoIPXEUrl {
+		t.Errorf("expected an error for environments without an ipxe url, got: %v", err)
+	}
+}
+
+func TestIPXEScriptHostileValues(t *testing.T) {
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := testInventoryNode()
+	node.Hostname = "x && shell"
+	node.Role = "${root-path}"
+	node.System = &types.System{Name: "test", ShortName: "a||b"}
+	node.LocationString = "xr20-31"
+	node.Environment.IPXEUrl = "http://boot.example.com/{{.Role}}.ipxe?host={{.Hostname}}&system={{.System}}"
+	node.Metadata = types.Metadata{"console": "ttyS0 || shell", "quoted": `a"b`, "safe": "ttyS1,115200"}
+
+	script, err := IPXEScript(node, mac, []string{"console", "quoted", "safe"})
+	if err != nil {
+		t.Fatalf("unable to render script: %v", err)
+	}
+
+	expected := `#!ipxe
+set inventory_id node0001
+set location xr20-31
+set net_provisioning_ip 10.0.0.10/24
+set net_provisioning_gateway 10.0.0.1
+set net_provisioning_dns 10.0.0.2
+set meta_safe ttyS1,115200
+chain http://boot.example.com/%24{root-path}.ipxe?host=x%20&&%20shell&system=a%7C%7Cb
+`
+	if diff := deep.Equal(script, expected); len(diff) > 0 {
+		t.Errorf("rendered script doesn't match: %v\n%s", diff, script)
+	}
+}
+
+func TestDiscoveryIPXEScript(t *testing.T) {
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+
+	script, err := DiscoveryIPXEScript(mac, "http://discovery.example.com/register?mac={{.MAC}}")
+	if err != nil {
+		t.Fatalf("unable to render script: %v", err)
+	}
+
+	expected := `#!ipxe
+echo No inventory record found for 00:01:02:03:04:05
+set mac_address 00:01:02:03:04:05
+chain http://discovery.example.com/register?mac=00:01:02:03:04:05
+`
+	if script != expected {
+		t.Errorf("unexpected discovery script:\n%s", script)
+	}
+
+	script, _ = DiscoveryIPXEScript(mac, "")
+	if script != "#!ipxe\necho No inventory record found for 00:01:02:03:04:05\nexit\n" {
+		t.Errorf("unexpected discovery script without a discovery url:\n%s", script)
+	}
+}
+
+func TestExitIPXEScript(t *testing.T) {
+	script := ExitIPXEScript("No ipxe url is configured for node0001\nchain http://evil.example.com")
+	if script != "#!ipxe\necho No ipxe url is configured for node0001chain http://evil.example.com\nexit\n" {
+		t.Errorf("unexpected exit script:\n%s", script)
+	}
+
+	script = ExitIPXEScript("No ipxe url is configured for x && shell ${root-path} || exit")
+	if script != "#!ipxe\necho No ipxe url is configured for x  shell {root-path}  exit\nexit\n" {
+		t.Errorf("unexpected exit script:\n%s", script)
+	}
+}
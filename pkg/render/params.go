@@ -0,0 +1,117 @@
+// Package render renders boot and provisioning configuration for inventory
+// nodes from their compiled inventory records.
+package render
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"text/template"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// NetworkParams describes the configuration of a node's interfaces on one of
+// its networks
+type NetworkParams struct {
+	MAC     []string
+	IP      []string
+	Gateway []string
+	DNS     []string
+	MTU     uint
+	Domain  string
+}
+
+// Params are the values about a node available to templates.  Networks are
+// keyed by the logical network name used in the node's environment.  Domain
+// is the domain of the first network, by logical name, that has one.
+// Metadata holds the environment's metadata, overridden by the node's.
+type Params struct {
+	Hostname    string
+	Domain      string
+	FQDN        string
+	InventoryID string
+	Location    string
+	Role        string
+	System      string
+	MAC         string
+	Tags        []string
+	Networks    map[string]NetworkParams
+	Metadata    map[string]interface{}
+}
+
+// NewParams collects the template parameters for node.  mac is the address of
+// the interface making the request, if known.
+func NewParams(node *types.InventoryNode, mac net.HardwareAddr) *Params {
+	p := &Params{
+		Hostname:    node.Hostname,
+		InventoryID: node.InventoryID,
+		Location:    node.LocationString,
+		Role:        node.Role,
+		Tags:        node.Tags,
+		Networks:    make(map[string]NetworkParams),
+		Metadata:    make(map[string]interface{}),
+	}
+
+	if mac != nil {
+		p.MAC = mac.String()
+	}
+
+	if node.System != nil {
+		p.System = node.System.ID()
+	}
+
+	if node.Environment != nil {
+		for k, v := range node.Environment.Metadata {
+			p.Metadata[k] = v
+		}
+	}
+
+	for k, v := range node.Metadata {
+		p.Metadata[k] = v
+	}
+
+	for _, name := range networkNames(node) {
+		nic := node.Networks[name]
+		n := NetworkParams{IP: nic.Config.IP, Gateway: nic.Config.Gateway, DNS: nic.Config.DNS, MTU: nic.Network.MTU, Domain: nic.Network.Domain}
+		for _, m := range nic.Interface.NICs {
+			n.MAC = append(n.MAC, m.String())
+		}
+		p.Networks[name] = n
+
+		if p.Domain == "" {
+			p.Domain = n.Domain
+		}
+	}
+
+	p.FQDN = p.Hostname
+	if p.Domain != "" {
+		p.FQDN = p.Hostname + "." + p.Domain
+	}
+	return p
+}
+
+// networkNames returns the logical names of the node's networks in order
+func networkNames(node *types.InventoryNode) []string {
+	names := make([]string, 0, len(node.Networks))
+	for name := range node.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand executes text as a template with the parameters
+func (p *Params) Expand(text string) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, p)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
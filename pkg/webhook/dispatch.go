@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// Dispatcher sends change events to every webhook subscribed to them
+type Dispatcher struct {
+	hooks  inventory.WebhookStore
+	nodes  inventory.NodeStore
+	sender *Sender
+}
+
+// NewDispatcher creates a Dispatcher for the webhooks in inv
+func NewDispatcher(inv inventory.Store, sender *Sender) *Dispatcher {
+	return &Dispatcher{hooks: inv.Webhook(), nodes: inv.Node(), sender: sender}
+}
+
+// eventNode returns the node the event is about, or the node holding the
+// reservation for reservation events.  It returns nil for other events, or if
+// the node can't be found.
+func (d *Dispatcher) eventNode(e *types.ChangeEvent) *types.Node {
+	doc := e.After
+	if len(doc) == 0 {
+		doc = e.Before
+	}
+
+	switch e.Kind {
+	case types.HistoryObjectNode:
+		node := &types.Node{}
+		if json.Unmarshal(doc, node) != nil {
+			return nil
+		}
+		return node
+	case types.HistoryObjectIPReservation:
+		r := &types.IPReservation{}
+		if json.Unmarshal(doc, r) != nil || r.MAC == nil {
+			return nil
+		}
+		node, err := d.nodes.GetNodeByMAC(r.MAC)
+		if err != nil {
+			return nil
+		}
+		return node
+	}
+	return nil
+}
+
+// subject returns the system and tags of the changed object that webhooks
+// filter on
+func (d *Dispatcher) subject(e *types.ChangeEvent) (string, []string) {
+	if e.Kind == types.HistoryObjectSystem {
+		return e.ObjectID, nil
+	}
+
+	if node := d.eventNode(e); node != nil {
+		return node.System, node.Tags
+	}
+	return "", nil
+}
+
+// Dispatch sends the event to each webhook whose filters match it.  Every
+// matching webhook is tried, even if delivery to another fails.
+func (d *Dispatcher) Dispatch(e *types.ChangeEvent) error {
+	hooks, err := d.hooks.GetWebhooks()
+	if err != nil {
+		return fmt.Errorf("unable to get webhooks: %v", err)
+	}
+
+	system, tags := d.subject(e)
+	failed := []string{}
+	for _, hook := range hooks {
+		if !hook.Matches(e.Kind, system, tags) {
+			continue
+		}
+
+		err := d.sender.Send(hook, e)
+		if err != nil {
+			log.Printf("unable to deliver %s event for %s %s: %v", e.Type, e.Kind, e.ObjectID, err)
+			failed = append(failed, hook.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("delivery failed to webhooks: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/memorystore"
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+func TestDispatch(t *testing.T) {
+	inv := memorystore.NewMemoryStore()
+
+	receivers := map[string]*testReceiver{}
+	hooks := map[string]*types.Webhook{
+		"all":  {},
+		"dhcp": {Kinds: []string{types.HistoryObjectIPReservation}, Tags: types.Tags{"dhcp"}},
+		"prd":  {Systems: []string{"prd"}},
+	}
+
+	for name, hook := range hooks {
+		receivers[name] = &testReceiver{}
+		srv := httptest.NewServer(receivers[name])
+		defer srv.Close()
+
+		hook.Name, hook.URL, hook.Secret = name, srv.URL, "s3cret"
+		err := inv.Webhook().Create(hook)
+		if err != nil {
+			t.Fatalf("unable to create webhook: %v", err)
+		}
+	}
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	err := inv.Network().Create(&types.Network{Name: "provisioning", Subnets: []*types.Subnet{{Cidr: subnet}}})
+	if err != nil {
+		t.Fatalf("unable to create network: %v", err)
+	}
+
+	mac, _ := net.ParseMAC("00:01:02:03:04:05")
+	node := &types.Node{InventoryID: "node0001", System: "tst", Tags: types.Tags{"dhcp"}, Networks: types.NICInfoMap{"provisioning": &types.NetworkInterface{NICs: []net.HardwareAddr{mac}}}}
+	err = inv.Node().Create(node)
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+
+	dispatcher := NewDispatcher(inv, testSender())
+	reservation := &types.IPReservation{IP: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: subnet.Mask}, MAC: mac}
+	e, _ := types.NewChangeEvent(types.ChangeEventCreated, types.HistoryObjectIPReservation, "10.0.0.10", nil, reservation)
+	err = dispatcher.Dispatch(e)
+	if err != nil {
+		t.Fatalf("unable to dispatch event: %v", err)
+	}
+
+	e, _ = types.NewChangeEvent(types.ChangeEventUpdated, types.HistoryObjectSystem, "prd", &types.System{Name: "prd"}, &types.System{Name: "prd"})
+	err = dispatcher.Dispatch(e)
+	if err != nil {
+		t.Fatalf("unable to dispatch event: %v", err)
+	}
+
+	expected := map[string]int{"all": 2, "dhcp": 1, "prd": 1}
+	for name, count := range expected {
+		if delivered := len(receivers[name].deliveries); delivered != count {
+			t.Errorf("expected %d deliveries to %s, got %d", count, name, delivered)
+		}
+	}
+
+	receivers["all"].statuses = []int{404}
+	err = dispatcher.Dispatch(e)
+	if err == nil || len(receivers["prd"].deliveries) != 2 {
+		t.Errorf("expected failed delivery to be reported after delivering to the other webhooks: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Package webhook delivers inventory change events to webhook subscribers
+// outside of AWS.  Each event is POSTed as json, signed with an HMAC of the
+// body keyed with the webhook's secret.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// Headers set on each delivery
+const (
+	SignatureHeader = "X-Inventory-Signature"
+	EventHeader     = "X-Inventory-Event"
+	KindHeader      = "X-Inventory-Kind"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of payload sent in the SignatureHeader: the hex
+// encoded HMAC-SHA256 of the payload keyed with secret, prefixed with
+// "sha256=".
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is the signature of payload with secret.
+// Receivers should verify each delivery before acting on it.
+func Verify(secret string, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// DefaultTimeout limits how long the default client waits for a webhook to
+// respond, so that an unresponsive receiver can't hold up delivery to the
+// others
+const DefaultTimeout = 5 * time.Second
+
+// Sender POSTs change events to webhooks.  Deliveries that fail because the
+// receiver couldn't be reached, or responded with a server error or 429, are
+// retried up to Attempts times in total.  The delay before each retry starts
+// at Backoff and doubles each time, up to MaxBackoff.
+type Sender struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	client     *http.Client
+}
+
+// NewSender creates a Sender with the default retry policy, that sends
+// requests with client, or a client with DefaultTimeout if it's nil
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Sender{
+		Attempts:   5,
+		Backoff:    time.Second,
+		MaxBackoff: 8 * time.Second,
+		client:     client,
+	}
+}
+
+// Send delivers the event to the webhook, retrying failed deliveries
+func (s *Sender) Send(hook *types.Webhook, e *types.ChangeEvent) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(hook, e, payload)
+		if err == nil || !retry || attempt >= s.Attempts {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if s.MaxBackoff > 0 && backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+// post makes a single delivery, returning whether it should be retried if it
+// failed
+func (s *Sender) post(hook *types.Webhook, e *types.ChangeEvent, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, payload))
+	req.Header.Set(EventHeader, e.Type)
+	req.Header.Set(KindHeader, e.Kind)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("unable to deliver to webhook %s: %v", hook.Name, err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook %s responded with %s", hook.Name, resp.Status)
+	default:
+		return false, fmt.Errorf("webhook %s rejected delivery with %s", hook.Name, resp.Status)
+	}
+}
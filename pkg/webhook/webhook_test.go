@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PolarGeospatialCenter/inventory/pkg/inventory/types"
+)
+
+// testReceiver stands in for a webhook receiver, responding to each delivery
+// with the next status in statuses, or 200 once they run out
+type testReceiver struct {
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []*http.Request
+	bodies     [][]byte
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func testSender() *Sender {
+	sender := NewSender(nil)
+	sender.Backoff = time.Millisecond
+	sender.MaxBackoff = 2 * time.Millisecond
+	return sender
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"Type":"created"}`)
+	signature := Sign("s3cret", payload)
+
+	if !Verify("s3cret", payload, signature) {
+		t.Errorf("expected signature to verify")
+	}
+
+	if Verify("other", payload, signature) || Verify("s3cret", []byte(`{}`), signature) || Verify("s3cret", payload, signature[len(signaturePrefix):]) {
+		t.Errorf("expected signature to be rejected")
+	}
+}
+
+func TestSend(t *testing.T) {
+	receiver := &testReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hook := &types.Webhook{Name: "dhcp", URL: srv.URL, Secret: "s3cret"}
+	e, _ := types.NewChangeEvent(types.ChangeEventCreated, types.HistoryObjectNode, "node0001", nil, &types.Node{InventoryID: "node0001"})
+
+	err := testSender().Send(hook, e)
+	if err != nil {
+		t.Fatalf("expected delivery to succeed after retries: %v", err)
+	}
+
+	if len(receiver.deliveries) != 3 {
+		t.Fatalf("expected 3 delivery attempts, got %d", len(receiver.deliveries))
+	}
+
+	req := receiver.deliveries[2]
+	if req.Method != http.MethodPost || req.Header.Get(EventHeader) != types.ChangeEventCreated || req.Header.Get(KindHeader) != types.HistoryObjectNode {
+		t.Errorf("unexpected delivery: %s %v", req.Method, req.Header)
+	}
+
+	if !Verify("s3cret", receiver.bodies[2], req.Header.Get(SignatureHeader)) {
+		t.Errorf("delivery signature doesn't verify")
+	}
+}
+
+func TestSendGivesUp(t *testing.T) {
+	receiver := &testReceiver{statuses: []int{http.StatusBadRequest}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hook := &types.Webhook{Name: "dhcp", URL: srv.URL, Secret: "s3cret"}
+	e, _ := types.NewChangeEvent(types.ChangeEventDeleted, types.HistoryObjectNode, "node0001", nil, nil)
+
+	err := testSender().Send(hook, e)
+	if err == nil || len(receiver.deliveries) != 1 {
+		t.Errorf("expected rejected delivery not to be retried, got %d attempts: %v", len(receiver.deliveries), err)
+	}
+
+	receiver = &testReceiver{statuses: []int{500, 500, 500, 500, 500, 500}}
+	srv2 := httptest.NewServer(receiver)
+	defer srv2.Close()
+
+	hook.URL = srv2.URL
+	err = testSender().Send(hook, e)
+	if err == nil || len(receiver.deliveries) != 5 {
+		t.Errorf("expected delivery to be attempted 5 times, got %d: %v", len(receiver.deliveries), err)
+	}
+}